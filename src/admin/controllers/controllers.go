@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/svadikari/golang_fiber_orders/src/admin/repository"
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"gorm.io/gorm"
+)
+
+type DeadLetterController interface {
+	GetDeadLetters(c *fiber.Ctx) error
+	ReplayDeadLetter(c *fiber.Ctx) error
+}
+
+type deadLetterController struct {
+	deadLetterRepository repository.DeadLetterRepository
+	publisher            events.EventPublisher
+}
+
+func NewDeadLetterController(deadLetterRepository repository.DeadLetterRepository, publisher events.EventPublisher) DeadLetterController {
+	return &deadLetterController{deadLetterRepository: deadLetterRepository, publisher: publisher}
+}
+
+// List dead letters
+//
+//	@Summary		List dead letters
+//	@Description	List messages moved to the dead-letter topic, newest first
+//	@Tags			Admin
+//	@Produce		json
+//
+//	@Param			limit		query		int		false	"Page size (default 50, max 500)"
+//	@Param			offset		query		int		false	"Number of messages to skip"
+//	@Param			replayed	query		bool	false	"Only replayed (true) or not yet replayed (false) messages"
+//
+//	@Success		200			{array}		models.DeadLetterMessage
+//	@Failure		500			{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/admin/dlq [get]
+func (dc *deadLetterController) GetDeadLetters(c *fiber.Ctx) error {
+	log := c.Locals("logger").(*slog.Logger)
+	limit := min(max(c.QueryInt("limit", 50), 1), 500)
+	offset := max(c.QueryInt("offset", 0), 0)
+	var replayed *bool
+	if c.Query("replayed") != "" {
+		value := c.QueryBool("replayed")
+		replayed = &value
+	}
+
+	letters, err := dc.deadLetterRepository.Find(limit, offset, replayed)
+	if err != nil {
+		log.Error("Failed to fetch dead letters", "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch dead letters")
+	}
+	return c.Status(fiber.StatusOK).JSON(letters)
+}
+
+// Replay dead letter
+//
+//	@Summary		Replay dead letter
+//	@Description	Publish a dead-lettered message back to its original topic
+//	@Tags			Admin
+//	@Produce		json
+//
+//	@Param			id	path		int	true	"Dead letter ID"
+//
+//	@Success		200	{object}	models.DeadLetterMessage
+//	@Failure		400	{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		404	{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500	{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/admin/dlq/{id}/replay [post]
+func (dc *deadLetterController) ReplayDeadLetter(c *fiber.Ctx) error {
+	log := c.Locals("logger").(*slog.Logger)
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid dead letter ID")
+	}
+
+	letter, err := dc.deadLetterRepository.FindByID(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "Dead letter not found")
+	}
+	if err != nil {
+		log.Error("Failed to fetch dead letter", "id", id, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch dead letter")
+	}
+
+	headers := map[string]string{}
+	if err := json.Unmarshal(letter.Headers, &headers); err != nil {
+		log.Error("Failed to decode dead letter headers", "id", id, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to decode dead letter headers")
+	}
+	// The replayed message starts a fresh retry chain.
+	for key := range headers {
+		if strings.HasPrefix(key, "x-") {
+			delete(headers, key)
+		}
+	}
+
+	err = dc.publisher.Publish(c.UserContext(), events.Message{
+		Topic:   letter.OriginalTopic,
+		Key:     []byte(letter.Key),
+		Value:   letter.Payload,
+		Headers: headers,
+	})
+	if err != nil {
+		log.Error("Failed to replay dead letter", "id", id, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to replay dead letter")
+	}
+	if err := dc.deadLetterRepository.MarkReplayed(&letter); err != nil {
+		log.Error("Replayed dead letter but failed to record it", "id", id, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to record replay")
+	}
+	log.Info("Replayed dead letter", "id", id, "topic", letter.OriginalTopic)
+	return c.Status(fiber.StatusOK).JSON(letter)
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/svadikari/golang_fiber_orders/src/admin/schemas"
+	"github.com/svadikari/golang_fiber_orders/src/middleware"
+	"github.com/svadikari/golang_fiber_orders/src/shipping"
+)
+
+type ShippingMethodController interface {
+	GetShippingMethods(c *fiber.Ctx) error
+	PutShippingMethod(c *fiber.Ctx) error
+	DeleteShippingMethod(c *fiber.Ctx) error
+}
+
+type shippingMethodController struct {
+	methods *shipping.Methods
+}
+
+func NewShippingMethodController(methods *shipping.Methods) ShippingMethodController {
+	return &shippingMethodController{methods: methods}
+}
+
+// List shipping methods
+//
+//	@Summary		List shipping methods
+//	@Description	List every shipping method, including inactive ones
+//	@Tags			Admin
+//	@Produce		json
+//
+//	@Success		200	{array}		shipping.Method
+//	@Failure		500	{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/admin/shipping-methods [get]
+func (sc *shippingMethodController) GetShippingMethods(c *fiber.Ctx) error {
+	methods, err := sc.methods.List(c.UserContext())
+	if err != nil {
+		c.Locals("logger").(*slog.Logger).Error("Failed to fetch shipping methods", "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch shipping methods")
+	}
+	return c.Status(fiber.StatusOK).JSON(methods)
+}
+
+// Set shipping method
+//
+//	@Summary		Set shipping method
+//	@Description	Create or replace a shipping method. Its cost is converted into the currency of orders placed in other currencies. Placed orders keep their shipping cost.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			code	path		string							true	"Shipping method code"
+//	@Param			method	body		schemas.ShippingMethodSchema	true	"Shipping method"
+//
+//	@Success		200		{object}	shipping.Method
+//	@Failure		400		{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500		{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/admin/shipping-methods/{code} [put]
+func (sc *shippingMethodController) PutShippingMethod(c *fiber.Ctx) error {
+	log := c.Locals("logger").(*slog.Logger)
+	var payload schemas.ShippingMethodSchema
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Cannot parse JSON")
+	}
+	if err := c.ParamsParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid shipping method path")
+	}
+	payload.Code = strings.ToLower(payload.Code)
+	if validationErrs := middleware.NewStructValidator().Validate(payload); len(validationErrs) > 0 {
+		return fiber.NewError(fiber.StatusBadRequest, strings.Join(validationErrs, ","))
+	}
+
+	method := shipping.Method{Code: payload.Code, Name: payload.Name, Cost: payload.Cost, Currency: payload.Currency,
+		Active: payload.Active == nil || *payload.Active}
+	if err := sc.methods.Save(c.UserContext(), &method); err != nil {
+		log.Error("Failed to save shipping method", "code", method.Code, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to save shipping method")
+	}
+	log.Info("Saved shipping method", "code", method.Code, "cost", method.Cost, "currency", method.Currency)
+	return c.Status(fiber.StatusOK).JSON(method)
+}
+
+// Delete shipping method
+//
+//	@Summary		Delete shipping method
+//	@Description	Delete a shipping method. Orders shipped with it keep its code and cost
+//	@Tags			Admin
+//	@Produce		json
+//
+//	@Param			code	path	string	true	"Shipping method code"
+//
+//	@Success		204
+//	@Failure		404	{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500	{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/admin/shipping-methods/{code} [delete]
+func (sc *shippingMethodController) DeleteShippingMethod(c *fiber.Ctx) error {
+	code := strings.ToLower(c.Params("code"))
+	err := sc.methods.Delete(c.UserContext(), code)
+	if errors.Is(err, shipping.ErrMethodNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "Shipping method not found")
+	}
+	if err != nil {
+		c.Locals("logger").(*slog.Logger).Error("Failed to delete shipping method", "code", code, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete shipping method")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/svadikari/golang_fiber_orders/src/admin/schemas"
+	"github.com/svadikari/golang_fiber_orders/src/middleware"
+	"github.com/svadikari/golang_fiber_orders/src/tax"
+)
+
+type TaxRateController interface {
+	GetTaxRates(c *fiber.Ctx) error
+	PutTaxRate(c *fiber.Ctx) error
+	DeleteTaxRate(c *fiber.Ctx) error
+}
+
+type taxRateController struct {
+	rates *tax.Rates
+}
+
+func NewTaxRateController(rates *tax.Rates) TaxRateController {
+	return &taxRateController{rates: rates}
+}
+
+// List tax rates
+//
+//	@Summary		List tax rates
+//	@Description	List the tax rates of every region and tax category
+//	@Tags			Admin
+//	@Produce		json
+//
+//	@Success		200	{array}		tax.TaxRate
+//	@Failure		500	{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/admin/tax-rates [get]
+func (tc *taxRateController) GetTaxRates(c *fiber.Ctx) error {
+	rates, err := tc.rates.List(c.UserContext())
+	if err != nil {
+		c.Locals("logger").(*slog.Logger).Error("Failed to fetch tax rates", "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch tax rates")
+	}
+	return c.Status(fiber.StatusOK).JSON(rates)
+}
+
+// Set tax rate
+//
+//	@Summary		Set tax rate
+//	@Description	Create or replace the tax rate of a tax category in a region. The standard category applies to categories without a rate of their own.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param			region		path		string					true	"ISO 3166-1 country or ISO 3166-2 subdivision code"
+//	@Param			category	path		string					true	"Tax category"
+//	@Param			rate		body		schemas.TaxRateSchema	true	"Tax rate"
+//
+//	@Success		200			{object}	tax.TaxRate
+//	@Failure		400			{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500			{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/admin/tax-rates/{region}/{category} [put]
+func (tc *taxRateController) PutTaxRate(c *fiber.Ctx) error {
+	log := c.Locals("logger").(*slog.Logger)
+	var payload schemas.TaxRateSchema
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Cannot parse JSON")
+	}
+	if err := c.ParamsParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid tax rate path")
+	}
+	payload.Region = strings.ToUpper(payload.Region)
+	payload.Category = strings.ToLower(payload.Category)
+	if validationErrs := middleware.NewStructValidator().Validate(payload); len(validationErrs) > 0 {
+		return fiber.NewError(fiber.StatusBadRequest, strings.Join(validationErrs, ","))
+	}
+
+	rate := tax.TaxRate{Region: payload.Region, Category: payload.Category, Name: payload.Name, Rate: payload.Rate}
+	if err := tc.rates.Save(c.UserContext(), &rate); err != nil {
+		log.Error("Failed to save tax rate", "region", rate.Region, "category", rate.Category, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to save tax rate")
+	}
+	log.Info("Saved tax rate", "region", rate.Region, "category", rate.Category, "rate", rate.Rate)
+	return c.Status(fiber.StatusOK).JSON(rate)
+}
+
+// Delete tax rate
+//
+//	@Summary		Delete tax rate
+//	@Description	Delete the tax rate of a tax category in a region
+//	@Tags			Admin
+//	@Produce		json
+//
+//	@Param			region		path	string	true	"ISO 3166-1 country or ISO 3166-2 subdivision code"
+//	@Param			category	path	string	true	"Tax category"
+//
+//	@Success		204
+//	@Failure		404	{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500	{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/admin/tax-rates/{region}/{category} [delete]
+func (tc *taxRateController) DeleteTaxRate(c *fiber.Ctx) error {
+	region, category := strings.ToUpper(c.Params("region")), strings.ToLower(c.Params("category"))
+	err := tc.rates.Delete(c.UserContext(), region, category)
+	if errors.Is(err, tax.ErrRateNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "Tax rate not found")
+	}
+	if err != nil {
+		c.Locals("logger").(*slog.Logger).Error("Failed to delete tax rate", "region", region, "category", category, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to delete tax rate")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
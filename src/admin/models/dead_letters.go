@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DeadLetterMessage is a consumed message that exhausted its retries or could not be processed at all.
+type DeadLetterMessage struct {
+	ID                uint            `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	Topic             string          `json:"topic" gorm:"column:topic;not null;size:255"`
+	OriginalTopic     string          `json:"original_topic" gorm:"column:original_topic;not null;size:255;uniqueIndex:idx_dead_letters_origin,priority:1"`
+	OriginalPartition int32           `json:"original_partition" gorm:"column:original_partition;not null;uniqueIndex:idx_dead_letters_origin,priority:2"`
+	OriginalOffset    int64           `json:"original_offset" gorm:"column:original_offset;not null;uniqueIndex:idx_dead_letters_origin,priority:3"`
+	Key               string          `json:"key" gorm:"column:message_key;size:255"`
+	Payload           []byte          `json:"payload" gorm:"column:payload;type:bytea"`
+	Headers           json.RawMessage `json:"headers" gorm:"column:headers;type:jsonb"`
+	Error             string          `json:"error" gorm:"column:error;size:2000"`
+	ErrorType         string          `json:"error_type" gorm:"column:error_type;size:50"`
+	RetryCount        int             `json:"retry_count" gorm:"column:retry_count;not null;default:0"`
+	FailedAt          time.Time       `json:"failed_at" gorm:"column:failed_at;not null"`
+	PublishedAt       *time.Time      `json:"published_at" gorm:"column:published_at"`
+	ReplayCount       int             `json:"replay_count" gorm:"column:replay_count;not null;default:0"`
+	ReplayedAt        *time.Time      `json:"replayed_at" gorm:"column:replayed_at"`
+	CreatedAt         time.Time       `json:"created_at" gorm:"column:created_at;not null"`
+}
+
+func (DeadLetterMessage) TableName() string {
+	return "dead_letters"
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/admin/models"
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type deadLetterRepository struct {
+	Db *gorm.DB
+}
+
+func NewDeadLetterRepository(db *gorm.DB) DeadLetterRepository {
+	return &deadLetterRepository{Db: db}
+}
+
+func (r *deadLetterRepository) Save(ctx context.Context, letter events.DeadLetter) (bool, error) {
+	headers, err := json.Marshal(letter.Headers)
+	if err != nil {
+		return false, err
+	}
+	result := r.Db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "original_topic"}, {Name: "original_partition"}, {Name: "original_offset"}},
+		DoNothing: true,
+	}).Create(&models.DeadLetterMessage{
+		Topic:             letter.Topic,
+		OriginalTopic:     letter.OriginalTopic,
+		OriginalPartition: letter.OriginalPartition,
+		OriginalOffset:    letter.OriginalOffset,
+		Key:               string(letter.Key),
+		Payload:           letter.Value,
+		Headers:           headers,
+		Error:             letter.Error,
+		ErrorType:         letter.ErrorType,
+		RetryCount:        letter.RetryCount,
+		FailedAt:          letter.FailedAt,
+	})
+	if result.Error != nil || result.RowsAffected == 1 {
+		return false, result.Error
+	}
+	var existing models.DeadLetterMessage
+	err = r.origin(ctx, letter).First(&existing).Error
+	return existing.PublishedAt != nil, err
+}
+
+func (r *deadLetterRepository) MarkPublished(ctx context.Context, letter events.DeadLetter) error {
+	return r.origin(ctx, letter).Model(&models.DeadLetterMessage{}).Update("published_at", time.Now()).Error
+}
+
+// origin selects the dead letter of the message letter was made from.
+func (r *deadLetterRepository) origin(ctx context.Context, letter events.DeadLetter) *gorm.DB {
+	return r.Db.WithContext(ctx).Where("original_topic = ? AND original_partition = ? AND original_offset = ?",
+		letter.OriginalTopic, letter.OriginalPartition, letter.OriginalOffset)
+}
+
+func (r *deadLetterRepository) Find(limit, offset int, replayed *bool) ([]models.DeadLetterMessage, error) {
+	letters := []models.DeadLetterMessage{}
+	query := r.Db.Order("id DESC").Limit(limit).Offset(offset)
+	if replayed != nil {
+		if *replayed {
+			query = query.Where("replayed_at IS NOT NULL")
+		} else {
+			query = query.Where("replayed_at IS NULL")
+		}
+	}
+	err := query.Find(&letters).Error
+	return letters, err
+}
+
+func (r *deadLetterRepository) FindByID(id uint) (models.DeadLetterMessage, error) {
+	var letter models.DeadLetterMessage
+	err := r.Db.First(&letter, id).Error
+	return letter, err
+}
+
+func (r *deadLetterRepository) MarkReplayed(letter *models.DeadLetterMessage) error {
+	now := time.Now()
+	letter.ReplayedAt = &now
+	letter.ReplayCount++
+	return r.Db.Model(letter).Updates(map[string]any{
+		"replayed_at":  letter.ReplayedAt,
+		"replay_count": letter.ReplayCount,
+	}).Error
+}
+
+type DeadLetterRepository interface {
+	events.DeadLetterStore
+	Find(limit, offset int, replayed *bool) ([]models.DeadLetterMessage, error)
+	FindByID(uint) (models.DeadLetterMessage, error)
+	MarkReplayed(*models.DeadLetterMessage) error
+}
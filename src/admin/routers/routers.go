@@ -0,0 +1,27 @@
+package routers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/svadikari/golang_fiber_orders/src/admin/controllers"
+	"github.com/svadikari/golang_fiber_orders/src/admin/repository"
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"github.com/svadikari/golang_fiber_orders/src/shipping"
+	"github.com/svadikari/golang_fiber_orders/src/tax"
+	"gorm.io/gorm"
+)
+
+func Init(app *fiber.App, db *gorm.DB, publisher events.EventPublisher) {
+	deadLetterController := controllers.NewDeadLetterController(repository.NewDeadLetterRepository(db), publisher)
+	taxRateController := controllers.NewTaxRateController(tax.NewRates(db))
+	shippingMethodController := controllers.NewShippingMethodController(shipping.NewMethods(db))
+	app.Route("/admin", func(router fiber.Router) {
+		router.Get("/dlq", deadLetterController.GetDeadLetters)
+		router.Post("/dlq/:id<min(1)>/replay", deadLetterController.ReplayDeadLetter)
+		router.Get("/tax-rates", taxRateController.GetTaxRates)
+		router.Put("/tax-rates/:region/:category", taxRateController.PutTaxRate)
+		router.Delete("/tax-rates/:region/:category", taxRateController.DeleteTaxRate)
+		router.Get("/shipping-methods", shippingMethodController.GetShippingMethods)
+		router.Put("/shipping-methods/:code", shippingMethodController.PutShippingMethod)
+		router.Delete("/shipping-methods/:code", shippingMethodController.DeleteShippingMethod)
+	})
+}
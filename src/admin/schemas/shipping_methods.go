@@ -0,0 +1,12 @@
+package schemas
+
+import "github.com/svadikari/golang_fiber_orders/src/money"
+
+// ShippingMethodSchema sets a shipping method. Code comes from the path; Active defaults to true.
+type ShippingMethodSchema struct {
+	Code     string      `json:"-" params:"code" validate:"required,max=50" message:"code is required and must be at most 50 characters"`
+	Name     string      `json:"name" validate:"required,max=100" message:"name is required and must be at most 100 characters"`
+	Cost     money.Money `json:"cost" validate:"min=0" message:"cost must be min 0"`
+	Currency string      `json:"currency" validate:"required,iso4217" message:"currency is required and must be an ISO 4217 code"`
+	Active   *bool       `json:"active"`
+}
@@ -0,0 +1,11 @@
+package schemas
+
+import "github.com/svadikari/golang_fiber_orders/src/money"
+
+// TaxRateSchema sets the rate of a tax category in a region. Region and Category come from the path.
+type TaxRateSchema struct {
+	Region   string     `json:"-" params:"region" validate:"required,iso3166_1_alpha2|iso3166_2" message:"region must be an ISO 3166-1 country or ISO 3166-2 subdivision code"`
+	Category string     `json:"-" params:"category" validate:"required,max=50" message:"category is required and must be at most 50 characters"`
+	Name     string     `json:"name" validate:"required,max=100" message:"name is required and must be at most 100 characters"`
+	Rate     money.Rate `json:"rate" validate:"min=0,max=1000000" message:"rate must be between 0 and 1"`
+}
@@ -0,0 +1,262 @@
+package controllers
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/svadikari/golang_fiber_orders/src/carts/schemas"
+	"github.com/svadikari/golang_fiber_orders/src/carts/services"
+	"github.com/svadikari/golang_fiber_orders/src/middleware"
+	orderControllers "github.com/svadikari/golang_fiber_orders/src/orders/controllers"
+)
+
+type CartController interface {
+	GetCart(c *fiber.Ctx) error
+	CreateCart(c *fiber.Ctx) error
+	DeleteCart(c *fiber.Ctx) error
+	AddItem(c *fiber.Ctx) error
+	UpdateItem(c *fiber.Ctx) error
+	RemoveItem(c *fiber.Ctx) error
+	Checkout(c *fiber.Ctx) error
+}
+
+type cartController struct {
+	cartService services.CartService
+}
+
+func NewCartController(cartService services.CartService) CartController {
+	return &cartController{cartService: cartService}
+}
+
+// Get cart
+//
+//	@Summary		Get cart
+//	@Description	Get a cart by ID, priced from the current catalog. Lines that cannot be ordered as they are carry a warning
+//	@Tags			Carts
+//	@Produce		json
+//	@Param			id	path		int	true	"Cart ID"
+//	@Success		200	{object}	schemas.CartResponse
+//	@Failure		400	{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		404	{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500	{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/carts/{id} [get]
+func (cc *cartController) GetCart(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid cart ID")
+	}
+	cart, err := cc.cartService.GetCart(c.UserContext(), uint(id))
+	if err != nil {
+		return cartError(c, err, "Failed to fetch cart")
+	}
+	return c.Status(fiber.StatusOK).JSON(cart)
+}
+
+// Create cart
+//
+//	@Summary		Create cart
+//	@Description	Create an empty cart. Carts expire once left untouched for CART_TTL
+//	@Tags			Carts
+//	@Accept			json
+//	@Produce		json
+//	@Param			cart	body		schemas.CartSchema	true	"Cart payload"
+//	@Success		201		{object}	schemas.CartResponse
+//	@Failure		400		{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500		{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/carts [post]
+func (cc *cartController) CreateCart(c *fiber.Ctx) error {
+	var payload schemas.CartSchema
+	if err := parseBody(c, &payload); err != nil {
+		return err
+	}
+	cart, err := cc.cartService.CreateCart(c.UserContext(), payload)
+	if err != nil {
+		return cartError(c, err, "Failed to create cart")
+	}
+	return c.Status(fiber.StatusCreated).JSON(cart)
+}
+
+// Delete cart
+//
+//	@Summary		Delete cart
+//	@Description	Delete a cart by ID
+//	@Tags			Carts
+//	@Produce		json
+//	@Param			id	path	int	true	"Cart ID"
+//	@Success		204
+//	@Failure		400	{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		404	{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500	{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/carts/{id} [delete]
+func (cc *cartController) DeleteCart(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid cart ID")
+	}
+	if err := cc.cartService.DeleteCart(c.UserContext(), uint(id)); err != nil {
+		return cartError(c, err, "Failed to delete cart")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Add cart item
+//
+//	@Summary		Add cart item
+//	@Description	Add a quantity of a product to a cart. Stock is not reserved until checkout
+//	@Tags			Carts
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int						true	"Cart ID"
+//	@Param			item	body		schemas.CartItemSchema	true	"Cart item payload"
+//	@Success		200		{object}	schemas.CartResponse
+//	@Failure		400		{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		404		{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500		{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/carts/{id}/items [post]
+func (cc *cartController) AddItem(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid cart ID")
+	}
+	var payload schemas.CartItemSchema
+	if err := parseBody(c, &payload); err != nil {
+		return err
+	}
+	cart, err := cc.cartService.AddItem(c.UserContext(), uint(id), payload)
+	if err != nil {
+		return cartError(c, err, "Failed to add cart item")
+	}
+	return c.Status(fiber.StatusOK).JSON(cart)
+}
+
+// Update cart item
+//
+//	@Summary		Update cart item
+//	@Description	Set the quantity of a product in a cart
+//	@Tags			Carts
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int								true	"Cart ID"
+//	@Param			productId	path		int								true	"Product ID"
+//	@Param			item		body		schemas.CartItemUpdateSchema	true	"Cart item payload"
+//	@Success		200			{object}	schemas.CartResponse
+//	@Failure		400			{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		404			{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500			{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/carts/{id}/items/{productId} [put]
+func (cc *cartController) UpdateItem(c *fiber.Ctx) error {
+	id, productID, err := itemParams(c)
+	if err != nil {
+		return err
+	}
+	var payload schemas.CartItemUpdateSchema
+	if err := parseBody(c, &payload); err != nil {
+		return err
+	}
+	cart, err := cc.cartService.UpdateItem(c.UserContext(), id, productID, payload)
+	if err != nil {
+		return cartError(c, err, "Failed to update cart item")
+	}
+	return c.Status(fiber.StatusOK).JSON(cart)
+}
+
+// Remove cart item
+//
+//	@Summary		Remove cart item
+//	@Description	Remove a product from a cart
+//	@Tags			Carts
+//	@Produce		json
+//	@Param			id			path		int	true	"Cart ID"
+//	@Param			productId	path		int	true	"Product ID"
+//	@Success		200			{object}	schemas.CartResponse
+//	@Failure		400			{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		404			{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500			{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/carts/{id}/items/{productId} [delete]
+func (cc *cartController) RemoveItem(c *fiber.Ctx) error {
+	id, productID, err := itemParams(c)
+	if err != nil {
+		return err
+	}
+	cart, err := cc.cartService.RemoveItem(c.UserContext(), id, productID)
+	if err != nil {
+		return cartError(c, err, "Failed to remove cart item")
+	}
+	return c.Status(fiber.StatusOK).JSON(cart)
+}
+
+// Checkout cart
+//
+//	@Summary		Checkout cart
+//	@Description	Place the order of a cart like POST /orders and empty the cart. The cart keeps its items when the order is rejected.
+//	@Description	Send an Idempotency-Key header to retry safely
+//	@Tags			Carts
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		int						true	"Cart ID"
+//	@Param			Idempotency-Key	header		string					false	"Unique key making retries of this request safe"
+//	@Param			checkout		body		schemas.CheckoutSchema	false	"Checkout payload"
+//	@Success		200				{object}	models.Order
+//	@Failure		400				{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		404				{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		409				{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		422				{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500				{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/carts/{id}/checkout [post]
+func (cc *cartController) Checkout(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid cart ID")
+	}
+	var payload schemas.CheckoutSchema
+	if len(c.Body()) > 0 {
+		if err := parseBody(c, &payload); err != nil {
+			return err
+		}
+	}
+	order, err := cc.cartService.Checkout(c.UserContext(), uint(id), payload)
+	if err != nil {
+		return cartError(c, err, "Failed to check out cart")
+	}
+	return c.Status(fiber.StatusOK).JSON(order)
+}
+
+func parseBody(c *fiber.Ctx, payload any) error {
+	if err := c.BodyParser(payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Cannot parse JSON")
+	}
+	if validationErrs := middleware.NewStructValidator().Validate(payload); len(validationErrs) > 0 {
+		return fiber.NewError(fiber.StatusBadRequest, strings.Join(validationErrs, ","))
+	}
+	return nil
+}
+
+func itemParams(c *fiber.Ctx) (uint, uint, error) {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return 0, 0, fiber.NewError(fiber.StatusBadRequest, "Invalid cart ID")
+	}
+	productID, err := c.ParamsInt("productId")
+	if err != nil {
+		return 0, 0, fiber.NewError(fiber.StatusBadRequest, "Invalid product ID")
+	}
+	return uint(id), uint(productID), nil
+}
+
+// cartError maps service errors to HTTP errors. Rejected checkouts are reported like rejected orders.
+func cartError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, services.ErrCartNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Cart not found")
+	case errors.Is(err, services.ErrCartItemNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Cart item not found")
+	case errors.Is(err, services.ErrProductNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Product not found")
+	case errors.Is(err, services.ErrEmptyCart):
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "Cart is empty")
+	default:
+		return orderControllers.OrderError(c.Locals("logger").(*slog.Logger), err, fallback)
+	}
+}
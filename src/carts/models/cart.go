@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Cart collects the products a user intends to order. Items hold no prices: carts are priced from the
+// catalog whenever they are read, and expire once untouched until ExpiresAt.
+type Cart struct {
+	ID     uint `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	UserId uint `json:"user_id" gorm:"column:user_id;not null;index:idx_carts_user_id"`
+	// Currency is the ISO 4217 currency the cart is priced and ordered in.
+	Currency  string     `json:"currency" gorm:"column:currency;type:char(3);not null"`
+	TaxRegion string     `json:"tax_region" gorm:"column:tax_region;size:6;not null;default:''"`
+	Items     []CartItem `json:"items" gorm:"foreignKey:CartID;constraint:OnDelete:CASCADE"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"column:expires_at;not null;index:idx_carts_expires_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"column:updated_at"`
+}
+
+type CartItem struct {
+	ID        uint      `json:"-" gorm:"primaryKey;autoIncrement;column:id"`
+	CartID    uint      `json:"-" gorm:"column:cart_id;not null;uniqueIndex:uq_cart_items_product,priority:1"`
+	ProductID uint      `json:"product_id" gorm:"column:product_id;not null;uniqueIndex:uq_cart_items_product,priority:2"`
+	Quantity  int       `json:"quantity" gorm:"column:quantity;not null;check:quantity > 0"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
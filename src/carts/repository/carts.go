@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/carts/models"
+	orderRepository "github.com/svadikari/golang_fiber_orders/src/orders/repository"
+	productModels "github.com/svadikari/golang_fiber_orders/src/products/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCartNotFound     = errors.New("cart not found")
+	ErrCartItemNotFound = errors.New("cart item not found")
+)
+
+type CartRepository interface {
+	// Transaction runs fn with a repository bound to a single database transaction.
+	Transaction(ctx context.Context, fn func(tx CartRepository) error) error
+	// FindByID returns an unexpired cart with its items, ErrCartNotFound when there is none.
+	FindByID(ctx context.Context, id uint) (models.Cart, error)
+	// LockByID is FindByID that also locks the cart until the end of the transaction.
+	LockByID(ctx context.Context, id uint) (models.Cart, error)
+	Create(ctx context.Context, cart *models.Cart) error
+	// Touch marks the cart as updated and pushes back its expiry.
+	Touch(ctx context.Context, id uint, expiresAt time.Time) error
+	Delete(ctx context.Context, id uint) error
+	// AddItem adds quantity to the item of the product, creating it when the cart has none.
+	AddItem(ctx context.Context, cartID, productID uint, quantity int) error
+	// SetItem sets the quantity of the item of the product, ErrCartItemNotFound when the cart has none.
+	SetItem(ctx context.Context, cartID, productID uint, quantity int) error
+	// RemoveItem removes the item of the product, ErrCartItemNotFound when the cart has none.
+	RemoveItem(ctx context.Context, cartID, productID uint) error
+	ClearItems(ctx context.Context, cartID uint) error
+	// DeleteExpired deletes the carts that expired before now and returns how many were removed.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+	// FindProducts returns the products with their prices by ID. Unknown IDs are left out.
+	FindProducts(ctx context.Context, ids []uint) (map[uint]productModels.Product, error)
+	// Orders returns an order repository on the same connection, so within Transaction it shares the cart's
+	// transaction.
+	Orders() orderRepository.OrderRepository
+}
+
+type cartRepository struct {
+	Db *gorm.DB
+}
+
+func NewCartRepository(db *gorm.DB) CartRepository {
+	return &cartRepository{Db: db}
+}
+
+func (r *cartRepository) Transaction(ctx context.Context, fn func(tx CartRepository) error) error {
+	return r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&cartRepository{Db: tx})
+	})
+}
+
+func (r *cartRepository) FindByID(ctx context.Context, id uint) (models.Cart, error) {
+	return r.findByID(r.Db.WithContext(ctx), id)
+}
+
+func (r *cartRepository) LockByID(ctx context.Context, id uint) (models.Cart, error) {
+	return r.findByID(r.Db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+func (r *cartRepository) findByID(tx *gorm.DB, id uint) (models.Cart, error) {
+	var cart models.Cart
+	err := tx.Where("expires_at > ?", time.Now()).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&cart, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return cart, ErrCartNotFound
+	}
+	return cart, err
+}
+
+func (r *cartRepository) Create(ctx context.Context, cart *models.Cart) error {
+	return r.Db.WithContext(ctx).Create(cart).Error
+}
+
+func (r *cartRepository) Touch(ctx context.Context, id uint, expiresAt time.Time) error {
+	return r.Db.WithContext(ctx).Model(&models.Cart{ID: id}).
+		Updates(map[string]any{"expires_at": expiresAt, "updated_at": time.Now()}).Error
+}
+
+func (r *cartRepository) Delete(ctx context.Context, id uint) error {
+	result := r.Db.WithContext(ctx).Delete(&models.Cart{}, id)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return ErrCartNotFound
+	}
+	return result.Error
+}
+
+func (r *cartRepository) AddItem(ctx context.Context, cartID, productID uint, quantity int) error {
+	item := models.CartItem{CartID: cartID, ProductID: productID, Quantity: quantity}
+	return r.Db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "cart_id"}, {Name: "product_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"quantity":   gorm.Expr("cart_items.quantity + excluded.quantity"),
+			"updated_at": gorm.Expr("excluded.updated_at"),
+		}),
+	}).Create(&item).Error
+}
+
+func (r *cartRepository) SetItem(ctx context.Context, cartID, productID uint, quantity int) error {
+	result := r.Db.WithContext(ctx).Model(&models.CartItem{}).
+		Where("cart_id = ? AND product_id = ?", cartID, productID).
+		Update("quantity", quantity)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return ErrCartItemNotFound
+	}
+	return result.Error
+}
+
+func (r *cartRepository) RemoveItem(ctx context.Context, cartID, productID uint) error {
+	result := r.Db.WithContext(ctx).Where("cart_id = ? AND product_id = ?", cartID, productID).Delete(&models.CartItem{})
+	if result.Error == nil && result.RowsAffected == 0 {
+		return ErrCartItemNotFound
+	}
+	return result.Error
+}
+
+func (r *cartRepository) ClearItems(ctx context.Context, cartID uint) error {
+	return r.Db.WithContext(ctx).Where("cart_id = ?", cartID).Delete(&models.CartItem{}).Error
+}
+
+func (r *cartRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.Db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&models.Cart{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *cartRepository) FindProducts(ctx context.Context, ids []uint) (map[uint]productModels.Product, error) {
+	products := make(map[uint]productModels.Product, len(ids))
+	if len(ids) == 0 {
+		return products, nil
+	}
+	var found []productModels.Product
+	if err := r.Db.WithContext(ctx).Preload("Prices").Find(&found, ids).Error; err != nil {
+		return nil, err
+	}
+	for _, product := range found {
+		products[product.ID] = product
+	}
+	return products, nil
+}
+
+func (r *cartRepository) Orders() orderRepository.OrderRepository {
+	return orderRepository.NewOrderRepository(r.Db)
+}
@@ -0,0 +1,26 @@
+package routers
+
+import (
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/svadikari/golang_fiber_orders/src/carts/controllers"
+	"github.com/svadikari/golang_fiber_orders/src/carts/repository"
+	"github.com/svadikari/golang_fiber_orders/src/carts/services"
+	"github.com/svadikari/golang_fiber_orders/src/middleware"
+	"gorm.io/gorm"
+)
+
+func Init(app *fiber.App, db *gorm.DB, orders services.OrderCreator) {
+	cartService := services.NewCartService(repository.NewCartRepository(db), orders, slog.Default())
+	cartController := controllers.NewCartController(cartService)
+	app.Route("/carts", func(router fiber.Router) {
+		router.Post("/", cartController.CreateCart)
+		router.Get("/:id<min(1)>", cartController.GetCart)
+		router.Delete("/:id<min(1)>", cartController.DeleteCart)
+		router.Post("/:id<min(1)>/items", cartController.AddItem)
+		router.Put("/:id<min(1)>/items/:productId<min(1)>", cartController.UpdateItem)
+		router.Delete("/:id<min(1)>/items/:productId<min(1)>", cartController.RemoveItem)
+		router.Post("/:id<min(1)>/checkout", middleware.Idempotency, cartController.Checkout)
+	})
+}
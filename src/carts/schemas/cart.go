@@ -0,0 +1,60 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/money"
+	orderSchemas "github.com/svadikari/golang_fiber_orders/src/orders/schemas"
+)
+
+// CartSchema creates a cart. Currency defaults to DEFAULT_CURRENCY; orders of carts without a TaxRegion
+// are taxed in TAX_DEFAULT_REGION.
+type CartSchema struct {
+	UserId    uint   `json:"user_id" validate:"required,min=1" message:"user_id is required and must be min 1"`
+	Currency  string `json:"currency" validate:"omitempty,iso4217" message:"currency must be an ISO 4217 code"`
+	TaxRegion string `json:"tax_region" validate:"omitempty,iso3166_1_alpha2|iso3166_2" message:"tax_region must be an ISO 3166-1 country or ISO 3166-2 subdivision code"`
+}
+
+// CartItemSchema adds a quantity of a product to a cart.
+type CartItemSchema struct {
+	ProductID uint `json:"product_id" validate:"required,min=1" message:"product_id is required and must be min 1"`
+	Quantity  int  `json:"quantity" validate:"required,min=1,max=1000" message:"quantity is required and must be between 1 and 1000"`
+}
+
+// CartItemUpdateSchema sets the quantity of a product in a cart.
+type CartItemUpdateSchema struct {
+	Quantity int `json:"quantity" validate:"required,min=1,max=1000" message:"quantity is required and must be between 1 and 1000"`
+}
+
+// CheckoutSchema places the order of a cart. CouponCode redeems a promotion; the shipping fields are
+// those of POST /orders.
+type CheckoutSchema struct {
+	CouponCode string `json:"coupon_code" validate:"omitempty,max=50,alphanum" message:"coupon_code must be at most 50 letters or digits"`
+	orderSchemas.ShippingSchema
+}
+
+// CartResponse is a cart priced from the current catalog. Subtotal sums the lines that have a price,
+// before discounts and tax.
+type CartResponse struct {
+	ID        uint        `json:"id"`
+	UserId    uint        `json:"user_id"`
+	Currency  string      `json:"currency"`
+	TaxRegion string      `json:"tax_region"`
+	Items     []CartLine  `json:"items"`
+	Subtotal  money.Money `json:"subtotal"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// CartLine is a cart item priced in the cart currency. Available is the stock of the product;
+// Warning explains why the line cannot be ordered as it is.
+type CartLine struct {
+	ProductID uint        `json:"product_id"`
+	Name      string      `json:"name"`
+	Quantity  int         `json:"quantity"`
+	UnitPrice money.Money `json:"unit_price"`
+	LineTotal money.Money `json:"line_total"`
+	Available int         `json:"available"`
+	Warning   string      `json:"warning,omitempty"`
+}
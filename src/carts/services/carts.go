@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/carts/models"
+	"github.com/svadikari/golang_fiber_orders/src/carts/repository"
+	"github.com/svadikari/golang_fiber_orders/src/carts/schemas"
+	"github.com/svadikari/golang_fiber_orders/src/currency"
+	orderModels "github.com/svadikari/golang_fiber_orders/src/orders/models"
+	orderRepository "github.com/svadikari/golang_fiber_orders/src/orders/repository"
+	orderSchemas "github.com/svadikari/golang_fiber_orders/src/orders/schemas"
+	productModels "github.com/svadikari/golang_fiber_orders/src/products/models"
+)
+
+var (
+	ErrCartNotFound     = repository.ErrCartNotFound
+	ErrCartItemNotFound = repository.ErrCartItemNotFound
+	// ErrProductNotFound rejects adding a product that does not exist.
+	ErrProductNotFound = errors.New("product not found")
+	// ErrEmptyCart rejects checking out a cart without items.
+	ErrEmptyCart = errors.New("cart is empty")
+)
+
+// OrderCreator places orders within a transaction of the caller. It is satisfied by the order service, so
+// checkouts go through the same stock, pricing, coupon and tax rules as POST /orders.
+type OrderCreator interface {
+	CreateOrderTx(ctx context.Context, tx orderRepository.OrderRepository, orderPayload orderSchemas.OrderSchema) (orderModels.Order, error)
+}
+
+type CartService interface {
+	GetCart(ctx context.Context, id uint) (schemas.CartResponse, error)
+	CreateCart(ctx context.Context, payload schemas.CartSchema) (schemas.CartResponse, error)
+	DeleteCart(ctx context.Context, id uint) error
+	AddItem(ctx context.Context, id uint, payload schemas.CartItemSchema) (schemas.CartResponse, error)
+	UpdateItem(ctx context.Context, id, productID uint, payload schemas.CartItemUpdateSchema) (schemas.CartResponse, error)
+	RemoveItem(ctx context.Context, id, productID uint) (schemas.CartResponse, error)
+	Checkout(ctx context.Context, id uint, payload schemas.CheckoutSchema) (orderModels.Order, error)
+}
+
+type cartService struct {
+	Logger         *slog.Logger
+	cartRepository repository.CartRepository
+	orders         OrderCreator
+	// ttl is how long a cart lives after its last change, configured by CART_TTL.
+	ttl time.Duration
+}
+
+func NewCartService(cartRepository repository.CartRepository, orders OrderCreator, logger *slog.Logger) CartService {
+	logger = logger.With("service", "CartService")
+	return &cartService{
+		Logger:         logger,
+		cartRepository: cartRepository,
+		orders:         orders,
+		ttl:            envDuration("CART_TTL", 7*24*time.Hour),
+	}
+}
+
+func (s *cartService) GetCart(ctx context.Context, id uint) (schemas.CartResponse, error) {
+	cart, err := s.cartRepository.FindByID(ctx, id)
+	if err != nil {
+		return schemas.CartResponse{}, err
+	}
+	return s.price(ctx, cart)
+}
+
+func (s *cartService) CreateCart(ctx context.Context, payload schemas.CartSchema) (schemas.CartResponse, error) {
+	cart := models.Cart{
+		UserId:    payload.UserId,
+		Currency:  payload.Currency,
+		TaxRegion: payload.TaxRegion,
+		Items:     []models.CartItem{},
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+	if cart.Currency == "" {
+		cart.Currency = currency.Default()
+	}
+	if err := s.cartRepository.Create(ctx, &cart); err != nil {
+		return schemas.CartResponse{}, err
+	}
+	s.Logger.Info("Created cart", "cartId", cart.ID, "userId", cart.UserId)
+	return s.price(ctx, cart)
+}
+
+func (s *cartService) DeleteCart(ctx context.Context, id uint) error {
+	return s.cartRepository.Delete(ctx, id)
+}
+
+// AddItem adds a quantity of a product to the cart. Stock is not reserved: lines exceeding it are
+// flagged when the cart is read and rejected at checkout.
+func (s *cartService) AddItem(ctx context.Context, id uint, payload schemas.CartItemSchema) (schemas.CartResponse, error) {
+	return s.change(ctx, id, func(tx repository.CartRepository) error {
+		products, err := tx.FindProducts(ctx, []uint{payload.ProductID})
+		if err != nil {
+			return err
+		}
+		if _, ok := products[payload.ProductID]; !ok {
+			return ErrProductNotFound
+		}
+		return tx.AddItem(ctx, id, payload.ProductID, payload.Quantity)
+	})
+}
+
+func (s *cartService) UpdateItem(ctx context.Context, id, productID uint, payload schemas.CartItemUpdateSchema) (schemas.CartResponse, error) {
+	return s.change(ctx, id, func(tx repository.CartRepository) error {
+		return tx.SetItem(ctx, id, productID, payload.Quantity)
+	})
+}
+
+func (s *cartService) RemoveItem(ctx context.Context, id, productID uint) (schemas.CartResponse, error) {
+	return s.change(ctx, id, func(tx repository.CartRepository) error {
+		return tx.RemoveItem(ctx, id, productID)
+	})
+}
+
+// Checkout places the order of the cart through the order service and empties the cart in the same
+// transaction, so the order is only committed together with the emptied cart. The cart stays locked until
+// then, so concurrent checkouts cannot order it twice, and keeps its items when the order is rejected.
+func (s *cartService) Checkout(ctx context.Context, id uint, payload schemas.CheckoutSchema) (orderModels.Order, error) {
+	var order orderModels.Order
+	err := s.cartRepository.Transaction(ctx, func(tx repository.CartRepository) error {
+		cart, err := tx.LockByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(cart.Items) == 0 {
+			return ErrEmptyCart
+		}
+		order, err = s.orders.CreateOrderTx(ctx, tx.Orders(), toOrderSchema(cart, payload))
+		if err != nil {
+			return err
+		}
+		if err := tx.ClearItems(ctx, id); err != nil {
+			return err
+		}
+		return tx.Touch(ctx, id, time.Now().Add(s.ttl))
+	})
+	if err != nil {
+		return orderModels.Order{}, err
+	}
+	s.Logger.Info("Checked out cart", "cartId", id, "orderId", order.ID)
+	return order, nil
+}
+
+// change locks the cart, applies fn and pushes back the expiry of the cart, then returns the priced cart.
+func (s *cartService) change(ctx context.Context, id uint, fn func(tx repository.CartRepository) error) (schemas.CartResponse, error) {
+	err := s.cartRepository.Transaction(ctx, func(tx repository.CartRepository) error {
+		if _, err := tx.LockByID(ctx, id); err != nil {
+			return err
+		}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return tx.Touch(ctx, id, time.Now().Add(s.ttl))
+	})
+	if err != nil {
+		return schemas.CartResponse{}, err
+	}
+	return s.GetCart(ctx, id)
+}
+
+func (s *cartService) price(ctx context.Context, cart models.Cart) (schemas.CartResponse, error) {
+	ids := make([]uint, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		ids = append(ids, item.ProductID)
+	}
+	products, err := s.cartRepository.FindProducts(ctx, ids)
+	if err != nil {
+		return schemas.CartResponse{}, err
+	}
+	return priceCart(cart, products), nil
+}
+
+// priceCart prices the cart items from the catalog in the cart currency and warns about the lines that
+// cannot be ordered: products that were deleted, have no price in the cart currency or lack stock.
+func priceCart(cart models.Cart, products map[uint]productModels.Product) schemas.CartResponse {
+	response := schemas.CartResponse{
+		ID:        cart.ID,
+		UserId:    cart.UserId,
+		Currency:  cart.Currency,
+		TaxRegion: cart.TaxRegion,
+		Items:     make([]schemas.CartLine, 0, len(cart.Items)),
+		ExpiresAt: cart.ExpiresAt,
+		CreatedAt: cart.CreatedAt,
+		UpdatedAt: cart.UpdatedAt,
+	}
+	for _, item := range cart.Items {
+		line := schemas.CartLine{ProductID: item.ProductID, Quantity: item.Quantity}
+		product, ok := products[item.ProductID]
+		if !ok {
+			line.Warning = "product is no longer available"
+			response.Items = append(response.Items, line)
+			continue
+		}
+		line.Name = product.Name
+		line.Available = product.Stock
+		if price, ok := product.PriceIn(cart.Currency); ok {
+			line.UnitPrice = price
+			line.LineTotal = price.Mul(item.Quantity)
+			response.Subtotal += line.LineTotal
+		} else {
+			line.Warning = "product has no price in " + cart.Currency
+		}
+		switch {
+		case line.Warning != "":
+		case product.Stock <= 0:
+			line.Warning = "product is out of stock"
+		case item.Quantity > product.Stock:
+			line.Warning = fmt.Sprintf("only %d in stock", product.Stock)
+		}
+		response.Items = append(response.Items, line)
+	}
+	return response
+}
+
+func toOrderSchema(cart models.Cart, payload schemas.CheckoutSchema) orderSchemas.OrderSchema {
+	orderPayload := orderSchemas.OrderSchema{
+		UserId:         cart.UserId,
+		Currency:       cart.Currency,
+		TaxRegion:      cart.TaxRegion,
+		CouponCode:     payload.CouponCode,
+		OrderItems:     make([]orderSchemas.OrderItemSchema, 0, len(cart.Items)),
+		ShippingSchema: payload.ShippingSchema,
+	}
+	for _, item := range cart.Items {
+		orderPayload.OrderItems = append(orderPayload.OrderItems, orderSchemas.OrderItemSchema{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+	return orderPayload
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
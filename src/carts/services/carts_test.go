@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/svadikari/golang_fiber_orders/src/carts/models"
+	"github.com/svadikari/golang_fiber_orders/src/carts/repository"
+	"github.com/svadikari/golang_fiber_orders/src/carts/schemas"
+	"github.com/svadikari/golang_fiber_orders/src/money"
+	orderModels "github.com/svadikari/golang_fiber_orders/src/orders/models"
+	orderRepository "github.com/svadikari/golang_fiber_orders/src/orders/repository"
+	orderSchemas "github.com/svadikari/golang_fiber_orders/src/orders/schemas"
+	productModels "github.com/svadikari/golang_fiber_orders/src/products/models"
+	"gorm.io/gorm"
+)
+
+type mockCartRepository struct {
+	mock.Mock
+	orders     orderRepository.OrderRepository
+	rolledBack bool
+}
+
+func (m *mockCartRepository) Transaction(ctx context.Context, fn func(tx repository.CartRepository) error) error {
+	err := fn(m)
+	m.rolledBack = err != nil
+	return err
+}
+
+func (m *mockCartRepository) Orders() orderRepository.OrderRepository {
+	return m.orders
+}
+
+func (m *mockCartRepository) FindByID(ctx context.Context, id uint) (models.Cart, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.Cart), args.Error(1)
+}
+
+func (m *mockCartRepository) LockByID(ctx context.Context, id uint) (models.Cart, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.Cart), args.Error(1)
+}
+
+func (m *mockCartRepository) Create(ctx context.Context, cart *models.Cart) error {
+	return m.Called(cart).Error(0)
+}
+
+func (m *mockCartRepository) Touch(ctx context.Context, id uint, expiresAt time.Time) error {
+	return m.Called(id, expiresAt).Error(0)
+}
+
+func (m *mockCartRepository) Delete(ctx context.Context, id uint) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *mockCartRepository) AddItem(ctx context.Context, cartID, productID uint, quantity int) error {
+	return m.Called(cartID, productID, quantity).Error(0)
+}
+
+func (m *mockCartRepository) SetItem(ctx context.Context, cartID, productID uint, quantity int) error {
+	return m.Called(cartID, productID, quantity).Error(0)
+}
+
+func (m *mockCartRepository) RemoveItem(ctx context.Context, cartID, productID uint) error {
+	return m.Called(cartID, productID).Error(0)
+}
+
+func (m *mockCartRepository) ClearItems(ctx context.Context, cartID uint) error {
+	return m.Called(cartID).Error(0)
+}
+
+func (m *mockCartRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockCartRepository) FindProducts(ctx context.Context, ids []uint) (map[uint]productModels.Product, error) {
+	args := m.Called(ids)
+	return args.Get(0).(map[uint]productModels.Product), args.Error(1)
+}
+
+type mockOrderCreator struct {
+	mock.Mock
+}
+
+func (m *mockOrderCreator) CreateOrderTx(ctx context.Context, tx orderRepository.OrderRepository, orderPayload orderSchemas.OrderSchema) (orderModels.Order, error) {
+	args := m.Called(tx, orderPayload)
+	return args.Get(0).(orderModels.Order), args.Error(1)
+}
+
+func TestPriceCart(t *testing.T) {
+
+	t.Run("Carts are priced in their currency with stock warnings", func(t *testing.T) {
+		cart := models.Cart{ID: 1, UserId: 7, Currency: "EUR", Items: []models.CartItem{
+			{ProductID: 1, Quantity: 2},
+			{ProductID: 2, Quantity: 5},
+			{ProductID: 3, Quantity: 1},
+			{ProductID: 4, Quantity: 1},
+			{ProductID: 5, Quantity: 1},
+		}}
+		products := map[uint]productModels.Product{
+			1: {Model: gorm.Model{ID: 1}, Name: "Mug", Price: money.MustParse("12.50"), Currency: "USD", Stock: 10,
+				Prices: []productModels.ProductPrice{{Currency: "EUR", Amount: money.MustParse("11.50")}}},
+			2: {Model: gorm.Model{ID: 2}, Name: "Pen", Price: money.MustParse("2.00"), Currency: "EUR", Stock: 3},
+			3: {Model: gorm.Model{ID: 3}, Name: "Ink", Price: money.MustParse("4.00"), Currency: "EUR", Stock: 0},
+			4: {Model: gorm.Model{ID: 4}, Name: "Lamp", Price: money.MustParse("30.00"), Currency: "USD", Stock: 5},
+		}
+
+		response := priceCart(cart, products)
+		assert.Equal(t, money.MustParse("37.00"), response.Subtotal)
+		assert.Equal(t, money.MustParse("23.00"), response.Items[0].LineTotal)
+		assert.Empty(t, response.Items[0].Warning)
+		assert.Equal(t, "only 3 in stock", response.Items[1].Warning)
+		assert.Equal(t, money.MustParse("10.00"), response.Items[1].LineTotal, "lines short of stock keep their price")
+		assert.Equal(t, "product is out of stock", response.Items[2].Warning)
+		assert.Equal(t, "product has no price in EUR", response.Items[3].Warning)
+		assert.Zero(t, response.Items[3].LineTotal)
+		assert.Equal(t, "product is no longer available", response.Items[4].Warning)
+	})
+}
+
+func TestAddItem(t *testing.T) {
+
+	t.Run("Unknown products are not added", func(t *testing.T) {
+		mockRepo := new(mockCartRepository)
+		service := NewCartService(mockRepo, new(mockOrderCreator), slog.Default())
+		mockRepo.On("LockByID", uint(1)).Return(models.Cart{ID: 1}, nil).Once()
+		mockRepo.On("FindProducts", []uint{9}).Return(map[uint]productModels.Product{}, nil).Once()
+
+		_, err := service.AddItem(context.Background(), 1, schemas.CartItemSchema{ProductID: 9, Quantity: 1})
+		assert.ErrorIs(t, err, ErrProductNotFound)
+		mockRepo.AssertNotCalled(t, "AddItem", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Expired carts are not found", func(t *testing.T) {
+		mockRepo := new(mockCartRepository)
+		service := NewCartService(mockRepo, new(mockOrderCreator), slog.Default())
+		mockRepo.On("LockByID", uint(1)).Return(models.Cart{}, repository.ErrCartNotFound).Once()
+
+		_, err := service.AddItem(context.Background(), 1, schemas.CartItemSchema{ProductID: 9, Quantity: 1})
+		assert.ErrorIs(t, err, ErrCartNotFound)
+	})
+}
+
+func TestCheckout(t *testing.T) {
+
+	cart := models.Cart{ID: 1, UserId: 7, Currency: "USD", TaxRegion: "US-CA", Items: []models.CartItem{
+		{ProductID: 3, Quantity: 2},
+		{ProductID: 5, Quantity: 1},
+	}}
+
+	t.Run("Carts are ordered through the order service and emptied", func(t *testing.T) {
+		mockRepo := &mockCartRepository{orders: orderRepository.NewOrderRepository(nil)}
+		mockOrders := new(mockOrderCreator)
+		service := NewCartService(mockRepo, mockOrders, slog.Default())
+		mockRepo.On("LockByID", uint(1)).Return(cart, nil).Once()
+		mockRepo.On("ClearItems", uint(1)).Return(nil).Once()
+		mockRepo.On("Touch", uint(1), mock.Anything).Return(nil).Once()
+		shipping := orderSchemas.ShippingSchema{ShippingMethod: "standard", ShippingAddress: &orderSchemas.AddressSchema{
+			Name: "Ada Lovelace", Line1: "1 Market St", City: "San Francisco", PostalCode: "94105", Country: "US",
+		}}
+		mockOrders.On("CreateOrderTx", mockRepo.orders, orderSchemas.OrderSchema{
+			UserId:         7,
+			Currency:       "USD",
+			TaxRegion:      "US-CA",
+			CouponCode:     "SUMMER10",
+			OrderItems:     []orderSchemas.OrderItemSchema{{ProductID: 3, Quantity: 2}, {ProductID: 5, Quantity: 1}},
+			ShippingSchema: shipping,
+		}).Return(orderModels.Order{Model: gorm.Model{ID: 42}}, nil).Once()
+
+		order, err := service.Checkout(context.Background(), 1, schemas.CheckoutSchema{CouponCode: "SUMMER10", ShippingSchema: shipping})
+		assert.NoError(t, err)
+		assert.Equal(t, uint(42), order.ID)
+		assert.False(t, mockRepo.rolledBack)
+		mockRepo.AssertExpectations(t)
+		mockOrders.AssertExpectations(t)
+	})
+
+	t.Run("Rejected orders are reported", func(t *testing.T) {
+		mockRepo := new(mockCartRepository)
+		mockOrders := new(mockOrderCreator)
+		service := NewCartService(mockRepo, mockOrders, slog.Default())
+		rejected := errors.New("insufficient stock")
+		mockRepo.On("LockByID", uint(1)).Return(cart, nil).Once()
+		mockOrders.On("CreateOrderTx", mock.Anything, mock.Anything).Return(orderModels.Order{}, rejected).Once()
+
+		_, err := service.Checkout(context.Background(), 1, schemas.CheckoutSchema{})
+		assert.ErrorIs(t, err, rejected)
+		assert.True(t, mockRepo.rolledBack)
+		mockRepo.AssertNotCalled(t, "ClearItems", mock.Anything)
+	})
+
+	t.Run("Orders are rolled back with the cart when it cannot be emptied", func(t *testing.T) {
+		mockRepo := &mockCartRepository{orders: orderRepository.NewOrderRepository(nil)}
+		mockOrders := new(mockOrderCreator)
+		service := NewCartService(mockRepo, mockOrders, slog.Default())
+		failed := errors.New("connection reset")
+		mockRepo.On("LockByID", uint(1)).Return(cart, nil).Once()
+		created := mockOrders.On("CreateOrderTx", mockRepo.orders, mock.Anything).Return(orderModels.Order{Model: gorm.Model{ID: 42}}, nil).Once()
+		mockRepo.On("ClearItems", uint(1)).Return(failed).Once().NotBefore(created)
+
+		_, err := service.Checkout(context.Background(), 1, schemas.CheckoutSchema{})
+		assert.ErrorIs(t, err, failed)
+		assert.True(t, mockRepo.rolledBack, "the order is created in the cart's transaction and rolled back with it")
+		mockRepo.AssertExpectations(t)
+		mockOrders.AssertExpectations(t)
+	})
+
+	t.Run("Empty carts cannot be checked out", func(t *testing.T) {
+		mockRepo := new(mockCartRepository)
+		mockOrders := new(mockOrderCreator)
+		service := NewCartService(mockRepo, mockOrders, slog.Default())
+		mockRepo.On("LockByID", uint(1)).Return(models.Cart{ID: 1, UserId: 7}, nil).Once()
+
+		_, err := service.Checkout(context.Background(), 1, schemas.CheckoutSchema{})
+		assert.ErrorIs(t, err, ErrEmptyCart)
+		mockOrders.AssertNotCalled(t, "CreateOrderTx", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/carts/repository"
+)
+
+// Pruner periodically deletes abandoned carts: carts left untouched for longer than CART_TTL.
+type Pruner struct {
+	cartRepository repository.CartRepository
+	logger         *slog.Logger
+	interval       time.Duration
+}
+
+func NewPruner(cartRepository repository.CartRepository, logger *slog.Logger) *Pruner {
+	return &Pruner{
+		cartRepository: cartRepository,
+		logger:         logger.With("component", "CartsPruner"),
+		interval:       envDuration("CARTS_PRUNE_INTERVAL", time.Hour),
+	}
+}
+
+// Start prunes on every interval until ctx is cancelled.
+func (p *Pruner) Start(ctx context.Context) {
+	p.logger.Info("Carts pruner started", "interval", p.interval)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("Carts pruner stopped")
+			return
+		case <-ticker.C:
+			if _, err := p.Prune(ctx); err != nil {
+				p.logger.Error("Failed to prune expired carts", "error", err)
+			}
+		}
+	}
+}
+
+// Prune deletes the expired carts and returns how many were removed.
+func (p *Pruner) Prune(ctx context.Context) (int64, error) {
+	count, err := p.cartRepository.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		p.logger.Info("Pruned expired carts", "count", count)
+	}
+	return count, nil
+}
@@ -0,0 +1,113 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/money"
+	"gorm.io/gorm"
+)
+
+// Default returns the currency of prices and orders that do not name one, from DEFAULT_CURRENCY.
+func Default() string {
+	if code := strings.ToUpper(os.Getenv("DEFAULT_CURRENCY")); code != "" {
+		return code
+	}
+	return "USD"
+}
+
+var ErrNoRate = errors.New("no exchange rate")
+
+// ExchangeRate is how much of Quote one unit of Base buys.
+type ExchangeRate struct {
+	Base      string     `json:"base" gorm:"column:base;primaryKey;type:char(3)"`
+	Quote     string     `json:"quote" gorm:"column:quote;primaryKey;type:char(3)"`
+	Rate      money.Rate `json:"rate" gorm:"column:rate;type:numeric(12,6);not null"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"column:updated_at;not null"`
+}
+
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// Converter converts amounts between currencies.
+type Converter interface {
+	Convert(ctx context.Context, amount money.Money, from, to string) (money.Money, error)
+}
+
+// Rates converts amounts with the exchange rates stored in the exchange_rates table.
+type Rates struct {
+	db *gorm.DB
+}
+
+func NewRates(db *gorm.DB) *Rates {
+	return &Rates{db: db}
+}
+
+// Convert converts amount from one currency to another using a direct rate, the inverse of the
+// opposite rate, or a cross rate through a base both currencies are quoted against.
+func (r *Rates) Convert(ctx context.Context, amount money.Money, from, to string) (money.Money, error) {
+	if from == to {
+		return amount, nil
+	}
+	var rates []ExchangeRate
+	err := r.db.WithContext(ctx).Where("quote IN ? OR base IN ?", []string{from, to}, []string{from, to}).Find(&rates).Error
+	if err != nil {
+		return 0, err
+	}
+	return convert(rates, amount, from, to)
+}
+
+// List returns every stored exchange rate.
+func (r *Rates) List(ctx context.Context) ([]ExchangeRate, error) {
+	var rates []ExchangeRate
+	err := r.db.WithContext(ctx).Order("base, quote").Find(&rates).Error
+	return rates, err
+}
+
+// Save upserts the rates of table.
+func (r *Rates) Save(ctx context.Context, table Table) error {
+	now := time.Now().UTC()
+	rates := make([]ExchangeRate, 0, len(table.Rates))
+	for quote, rate := range table.Rates {
+		rates = append(rates, ExchangeRate{Base: table.Base, Quote: quote, Rate: rate, UpdatedAt: now})
+	}
+	if len(rates) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Save(&rates).Error
+}
+
+// convert picks the most direct of rates to convert amount. Rates are keyed by base and quote,
+// so one base currency quoted against every other one is enough to convert between any pair.
+func convert(rates []ExchangeRate, amount money.Money, from, to string) (money.Money, error) {
+	one := money.MustParseRate("1")
+	quotes := map[string]map[string]money.Rate{}
+	for _, rate := range rates {
+		if rate.Rate <= 0 {
+			continue
+		}
+		if quotes[rate.Base] == nil {
+			quotes[rate.Base] = map[string]money.Rate{rate.Base: one}
+		}
+		quotes[rate.Base][rate.Quote] = rate.Rate
+	}
+	if rate, ok := quotes[from][to]; ok {
+		return amount.Convert(one, rate), nil
+	}
+	if rate, ok := quotes[to][from]; ok {
+		return amount.Convert(rate, one), nil
+	}
+	for _, base := range sortedKeys(quotes) {
+		fromRate, okFrom := quotes[base][from]
+		toRate, okTo := quotes[base][to]
+		if okFrom && okTo {
+			return amount.Convert(fromRate, toRate), nil
+		}
+	}
+	return 0, fmt.Errorf("%w from %s to %s", ErrNoRate, from, to)
+}
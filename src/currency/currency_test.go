@@ -0,0 +1,70 @@
+package currency
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svadikari/golang_fiber_orders/src/money"
+)
+
+func TestConvert(t *testing.T) {
+	rates := []ExchangeRate{
+		{Base: "USD", Quote: "EUR", Rate: money.MustParseRate("0.8")},
+		{Base: "USD", Quote: "JPY", Rate: money.MustParseRate("150")},
+	}
+
+	t.Run("uses a direct rate", func(t *testing.T) {
+		converted, err := convert(rates, money.MustParse("10"), "USD", "EUR")
+		require.NoError(t, err)
+		assert.Equal(t, money.MustParse("8"), converted)
+	})
+
+	t.Run("inverts the opposite rate", func(t *testing.T) {
+		converted, err := convert(rates, money.MustParse("8"), "EUR", "USD")
+		require.NoError(t, err)
+		assert.Equal(t, money.MustParse("10"), converted)
+	})
+
+	t.Run("crosses through a common base", func(t *testing.T) {
+		converted, err := convert(rates, money.MustParse("8"), "EUR", "JPY")
+		require.NoError(t, err)
+		assert.Equal(t, money.MustParse("1500"), converted)
+	})
+
+	t.Run("fails without a rate", func(t *testing.T) {
+		_, err := convert(rates, money.MustParse("8"), "EUR", "GBP")
+		assert.ErrorIs(t, err, ErrNoRate)
+	})
+}
+
+func TestFileSource(t *testing.T) {
+	t.Run("reads a rates table", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rates.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"base": "USD", "rates": {"EUR": 0.92, "JPY": "149.5"}}`), 0o644))
+
+		table, err := FileSource{Path: path}.Fetch(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "USD", table.Base)
+		assert.Equal(t, money.MustParseRate("0.92"), table.Rates["EUR"])
+		assert.Equal(t, money.MustParseRate("149.5"), table.Rates["JPY"])
+	})
+
+	t.Run("rejects non-positive rates", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rates.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"base": "USD", "rates": {"EUR": 0}}`), 0o644))
+
+		_, err := FileSource{Path: path}.Fetch(context.Background())
+		assert.ErrorContains(t, err, "invalid exchange rate")
+	})
+}
+
+func TestStubSource(t *testing.T) {
+	table, err := StubSource{}.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.NoError(t, table.validate())
+	assert.Equal(t, "USD", table.Base)
+}
@@ -0,0 +1,63 @@
+package currency
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Refresher loads exchange rates from a source into the exchange_rates table on start and then on
+// every interval. Rates that fail to load keep their previous values.
+type Refresher struct {
+	rates    *Rates
+	source   Source
+	logger   *slog.Logger
+	interval time.Duration
+}
+
+func NewRefresher(db *gorm.DB, source Source, logger *slog.Logger) *Refresher {
+	interval := time.Hour
+	if value, err := time.ParseDuration(os.Getenv("EXCHANGE_RATES_REFRESH_INTERVAL")); err == nil && value > 0 {
+		interval = value
+	}
+	return &Refresher{
+		rates:    NewRates(db),
+		source:   source,
+		logger:   logger.With("component", "ExchangeRatesRefresher"),
+		interval: interval,
+	}
+}
+
+// Start refreshes the rates until ctx is cancelled.
+func (r *Refresher) Start(ctx context.Context) {
+	r.logger.Info("Exchange rates refresher started", "interval", r.interval)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		if err := r.Refresh(ctx); err != nil {
+			r.logger.Error("Failed to refresh exchange rates", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Exchange rates refresher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh loads the latest rates from the source.
+func (r *Refresher) Refresh(ctx context.Context) error {
+	table, err := r.source.Fetch(ctx)
+	if err != nil {
+		return err
+	}
+	if err := r.rates.Save(ctx, table); err != nil {
+		return err
+	}
+	r.logger.Info("Refreshed exchange rates", "base", table.Base, "count", len(table.Rates))
+	return nil
+}
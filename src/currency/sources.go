@@ -0,0 +1,121 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/svadikari/golang_fiber_orders/src/money"
+)
+
+// Table is a set of exchange rates against one base currency, in the format of the rates file and API:
+//
+//	{"base": "USD", "rates": {"EUR": 0.92, "GBP": 0.79}}
+type Table struct {
+	Base  string                `json:"base"`
+	Rates map[string]money.Rate `json:"rates"`
+}
+
+// Source supplies the latest exchange rates.
+type Source interface {
+	Fetch(ctx context.Context) (Table, error)
+}
+
+// SourceFromEnv reads rates from the file at EXCHANGE_RATES_FILE or the API at EXCHANGE_RATES_API_URL.
+// Without either it falls back to fixed stub rates, which keeps local setups working without a provider.
+func SourceFromEnv() Source {
+	if path := os.Getenv("EXCHANGE_RATES_FILE"); path != "" {
+		slog.Info("Loading exchange rates from file", "path", path)
+		return FileSource{Path: path}
+	}
+	if url := os.Getenv("EXCHANGE_RATES_API_URL"); url != "" {
+		return NewAPISource(url)
+	}
+	slog.Warn("Neither EXCHANGE_RATES_FILE nor EXCHANGE_RATES_API_URL is set, using stub exchange rates")
+	return StubSource{}
+}
+
+// FileSource reads a rates table from a JSON file.
+type FileSource struct {
+	Path string
+}
+
+func (s FileSource) Fetch(ctx context.Context) (Table, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return Table{}, err
+	}
+	var table Table
+	if err := json.Unmarshal(data, &table); err != nil {
+		return Table{}, fmt.Errorf("%s: %w", s.Path, err)
+	}
+	return table, table.validate()
+}
+
+// APISource fetches a rates table from an HTTP endpoint.
+type APISource struct {
+	restyClient *resty.Client
+}
+
+func NewAPISource(url string) *APISource {
+	restyClient := resty.New()
+	restyClient.SetBaseURL(url).
+		SetTimeout(5 * time.Second).
+		SetRetryCount(3).
+		SetRetryWaitTime(1 * time.Second)
+
+	slog.Info("Initialized Exchange Rates Client", "baseURL", restyClient.BaseURL)
+	return &APISource{restyClient}
+}
+
+func (s *APISource) Fetch(ctx context.Context) (Table, error) {
+	var table Table
+	resp, err := s.restyClient.R().SetContext(ctx).SetResult(&table).Get("")
+	if err != nil {
+		return Table{}, err
+	}
+	if resp.IsError() {
+		return Table{}, fmt.Errorf("exchange rates API returned %d", resp.StatusCode())
+	}
+	return table, table.validate()
+}
+
+// StubSource returns fixed, approximate rates against USD. It is meant for local runs and tests only.
+type StubSource struct{}
+
+func (StubSource) Fetch(ctx context.Context) (Table, error) {
+	return Table{Base: "USD", Rates: map[string]money.Rate{
+		"EUR": money.MustParseRate("0.92"),
+		"GBP": money.MustParseRate("0.79"),
+		"JPY": money.MustParseRate("149.5"),
+		"CAD": money.MustParseRate("1.36"),
+		"AUD": money.MustParseRate("1.52"),
+		"INR": money.MustParseRate("83.2"),
+	}}, nil
+}
+
+func (t Table) validate() error {
+	if len(t.Base) != 3 {
+		return fmt.Errorf("exchange rates need a three letter base currency, got %q", t.Base)
+	}
+	for quote, rate := range t.Rates {
+		if len(quote) != 3 || rate <= 0 {
+			return fmt.Errorf("invalid exchange rate %s/%s: %s", t.Base, quote, rate)
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,10 +1,10 @@
 package database
 
 import (
+	"fmt"
 	"os"
 
-	orderModels "github.com/svadikari/golang_fiber_orders/src/orders/models"
-	productModels "github.com/svadikari/golang_fiber_orders/src/products/models"
+	"github.com/svadikari/golang_fiber_orders/src/migrations"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -15,18 +15,35 @@ type DbInstance struct {
 
 var Database DbInstance
 
-func ConnectDB() error {
+// Open connects to the database at DB_DSN without checking its schema.
+func Open() (*gorm.DB, error) {
 	dsn := os.Getenv("DB_DSN")
 	if dsn == "" {
 		dsn = "host=localhost user=svadikari password=yourpassword dbname=orders-db port=5432 sslmode=disable TimeZone=Asia/Shanghai"
 	}
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	return gorm.Open(postgres.Open(dsn), &gorm.Config{})
+}
+
+// ConnectDB connects to the database and refuses to continue when migrations are pending,
+// so the API never serves against a schema older than the code expects.
+func ConnectDB() error {
+	db, err := Open()
 	if err != nil {
 		return err
 	}
 
-	// Migrate the schema
-	db.AutoMigrate(&productModels.Product{}, &orderModels.Order{}, &orderModels.OrderItem{})
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+	pending, err := migrator.Pending()
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("database schema is behind: %d pending migrations starting at %04d_%s; run `migrate up`",
+			len(pending), pending[0].Version, pending[0].Name)
+	}
 
 	Database = DbInstance{
 		Db: db,
@@ -24,56 +24,79 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/orders": {
+        "/admin/dlq": {
             "get": {
-                "description": "Retrieve a list of all orders",
+                "description": "List messages moved to the dead-letter topic, newest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Orders"
+                    "Admin"
+                ],
+                "summary": "List dead letters",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of messages to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only replayed (true) or not yet replayed (false) messages",
+                        "name": "replayed",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get all orders",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.Order"
+                                "$ref": "#/definitions/models.DeadLetterMessage"
                             }
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
                     }
                 }
-            },
+            }
+        },
+        "/admin/dlq/{id}/replay": {
             "post": {
-                "description": "Creates a new order",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Publish a dead-lettered message back to its original topic",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Orders"
+                    "Admin"
                 ],
-                "summary": "Create Order",
+                "summary": "Replay dead letter",
                 "parameters": [
                     {
-                        "description": "Order payload",
-                        "name": "order",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schemas.OrderSchema"
-                        }
+                        "type": "integer",
+                        "description": "Dead letter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Order"
+                            "$ref": "#/definitions/models.DeadLetterMessage"
                         }
                     },
                     "400": {
@@ -97,43 +120,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/consumer/start": {
+        "/admin/shipping-methods": {
             "get": {
-                "description": "Start the Kafka consumer to process orders",
+                "description": "List every shipping method, including inactive ones",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Orders"
+                    "Admin"
                 ],
-                "summary": "Start Kafka Consumer",
+                "summary": "List shipping methods",
                 "responses": {
                     "200": {
-                        "description": "Kafka consumer started successfully",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/fiber.Map"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/shipping.Method"
+                            }
                         }
-                    }
-                }
-            }
-        },
-        "/orders/consumer/stop": {
-            "get": {
-                "description": "Stop the Kafka consumer to process orders",
-                "tags": [
-                    "Orders"
-                ],
-                "summary": "Stop Kafka Consumer",
-                "responses": {
-                    "200": {
-                        "description": "Kafka consumer stopped successfully",
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/fiber.Map"
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
                         }
                     }
                 }
             }
         },
-        "/orders/{id}": {
-            "get": {
-                "description": "Fetch an order by ID",
+        "/admin/shipping-methods/{code}": {
+            "put": {
+                "description": "Create or replace a shipping method. Its cost is converted into the currency of orders placed in other currencies. Placed orders keep their shipping cost.",
                 "consumes": [
                     "application/json"
                 ],
@@ -141,23 +159,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Orders"
+                    "Admin"
                 ],
-                "summary": "Fetch Order",
+                "summary": "Set shipping method",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Order ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Shipping method code",
+                        "name": "code",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Shipping method",
+                        "name": "method",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ShippingMethodSchema"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Order"
+                            "$ref": "#/definitions/shipping.Method"
                         }
                     },
                     "400": {
@@ -166,6 +193,36 @@ const docTemplate = `{
                             "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
                         }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a shipping method. Orders shipped with it keep its code and cost",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Delete shipping method",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Shipping method code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -179,9 +236,40 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/tax-rates": {
+            "get": {
+                "description": "List the tax rates of every region and tax category",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List tax rates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/tax.TaxRate"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/tax-rates/{region}/{category}": {
             "put": {
-                "description": "Update an existing order by ID",
+                "description": "Create or replace the tax rate of a tax category in a region. The standard category applies to categories without a rate of their own.",
                 "consumes": [
                     "application/json"
                 ],
@@ -189,24 +277,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Orders"
+                    "Admin"
                 ],
-                "summary": "Updaate Order",
+                "summary": "Set tax rate",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Order ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "ISO 3166-1 country or ISO 3166-2 subdivision code",
+                        "name": "region",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Order payload",
-                        "name": "order",
+                        "type": "string",
+                        "description": "Tax category",
+                        "name": "category",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tax rate",
+                        "name": "rate",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schemas.OrderUpdateSchema"
+                            "$ref": "#/definitions/schemas.TaxRateSchema"
                         }
                     }
                 ],
@@ -214,7 +309,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Order"
+                            "$ref": "#/definitions/tax.TaxRate"
                         }
                     },
                     "400": {
@@ -223,12 +318,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -238,35 +327,33 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete an order by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Delete the tax rate of a tax category in a region",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Orders"
+                    "Admin"
                 ],
-                "summary": "Delete Order",
+                "summary": "Delete tax rate",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Order ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "ISO 3166-1 country or ISO 3166-2 subdivision code",
+                        "name": "region",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Tax category",
+                        "name": "category",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "204": {
-                        "description": "Order deleted successfully"
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
-                        }
+                        "description": "No Content"
                     },
                     "404": {
                         "description": "Not Found",
@@ -283,30 +370,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/products": {
-            "get": {
-                "description": "Retrieve a list of all products",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Products"
-                ],
-                "summary": "Get all products",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/schemas.Product"
-                            }
-                        }
-                    }
-                }
-            },
+        "/carts": {
             "post": {
-                "description": "Creates a new product",
+                "description": "Create an empty cart. Carts expire once left untouched for CART_TTL",
                 "consumes": [
                     "application/json"
                 ],
@@ -314,17 +380,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Carts"
                 ],
-                "summary": "Create product",
+                "summary": "Create cart",
                 "parameters": [
                     {
-                        "description": "Product payload",
-                        "name": "product",
+                        "description": "Cart payload",
+                        "name": "cart",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/schemas.Product"
+                            "$ref": "#/definitions/schemas.CartSchema"
                         }
                     }
                 ],
@@ -332,29 +398,38 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/schemas.Product"
+                            "$ref": "#/definitions/schemas.CartResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
                         }
                     }
                 }
             }
         },
-        "/products/{id}": {
+        "/carts/{id}": {
             "get": {
-                "description": "Get a product by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Get a cart by ID, priced from the current catalog. Lines that cannot be ordered as they are carry a warning",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Carts"
                 ],
-                "summary": "Get product",
+                "summary": "Get cart",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
+                        "description": "Cart ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -364,52 +439,151 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/schemas.Product"
+                            "$ref": "#/definitions/schemas.CartResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
                         }
                     }
                 }
             },
-            "put": {
-                "description": "Update a new product",
-                "consumes": [
-                    "application/json"
-                ],
+            "delete": {
+                "description": "Delete a cart by ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Carts"
                 ],
-                "summary": "Update product",
+                "summary": "Delete cart",
                 "parameters": [
                     {
-                        "description": "Product payload",
-                        "name": "product",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/schemas.Product"
-                        }
+                        "type": "integer",
+                        "description": "Cart ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/carts/{id}/checkout": {
+            "post": {
+                "description": "Place the order of a cart like POST /orders and empty the cart. The cart keeps its items when the order is rejected.\nSend an Idempotency-Key header to retry safely",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Carts"
+                ],
+                "summary": "Checkout cart",
+                "parameters": [
                     {
                         "type": "integer",
-                        "description": "Product ID",
+                        "description": "Cart ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique key making retries of this request safe",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Checkout payload",
+                        "name": "checkout",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.CheckoutSchema"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/schemas.Product"
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete product by ID",
+            }
+        },
+        "/carts/{id}/items": {
+            "post": {
+                "description": "Add a quantity of a product to a cart. Stock is not reserved until checkout",
                 "consumes": [
                     "application/json"
                 ],
@@ -417,102 +591,2306 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Products"
+                    "Carts"
                 ],
-                "summary": "Delete product",
+                "summary": "Add cart item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Cart ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cart item payload",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schemas.CartItemSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.CartResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/carts/{id}/items/{productId}": {
+            "put": {
+                "description": "Set the quantity of a product in a cart",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Carts"
+                ],
+                "summary": "Update cart item",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Cart ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
                         "description": "Product ID",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cart item payload",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schemas.CartItemUpdateSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.CartResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a product from a cart",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Carts"
+                ],
+                "summary": "Remove cart item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Cart ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "productId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.CartResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders": {
+            "get": {
+                "description": "Retrieve a page of orders matching the filters. Pass next_cursor back as cursor to fetch the next page",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Get orders",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Only orders of this user",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "NEW",
+                            "CONFIRMED",
+                            "PARTIALLY_SHIPPED",
+                            "SHIPPED",
+                            "PARTIALLY_DELIVERED",
+                            "DELIVERED",
+                            "CANCELLED",
+                            "RETURNED"
+                        ],
+                        "type": "string",
+                        "description": "Only orders in this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only orders placed in this currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only orders containing this product",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC 3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or before (RFC 3339)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Updated at or after (RFC 3339)",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Updated at or before (RFC 3339)",
+                        "name": "updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum total amount",
+                        "name": "min_total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum total amount",
+                        "name": "max_total",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "-id",
+                            "created_at",
+                            "-created_at",
+                            "updated_at",
+                            "-updated_at",
+                            "total_amount",
+                            "-total_amount"
+                        ],
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "Sort key, prefixed with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of orders to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a new order priced from the product catalog in the order currency, discounted by the coupon, taxed in the tax region, plus the cost of the shipping method, and reserves stock for every order line",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Create Order",
+                "parameters": [
+                    {
+                        "description": "Order payload",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schemas.OrderSchema"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries return the original response instead of creating another order",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/consumer/start": {
+            "post": {
+                "description": "Start the Kafka consumer to process orders",
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Start Kafka Consumer",
+                "responses": {
+                    "200": {
+                        "description": "Kafka consumer started successfully",
+                        "schema": {
+                            "$ref": "#/definitions/fiber.Map"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/consumer/status": {
+            "get": {
+                "description": "State, assigned partitions, lag and last error of the Kafka consumer",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Kafka Consumer Status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.ConsumerStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/consumer/stop": {
+            "post": {
+                "description": "Stop the Kafka consumer and wait until it has committed and left the consumer group",
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Stop Kafka Consumer",
+                "responses": {
+                    "200": {
+                        "description": "Kafka consumer stopped successfully",
+                        "schema": {
+                            "$ref": "#/definitions/fiber.Map"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/producer/stats": {
+            "get": {
+                "description": "Delivery report counters of the shared event publisher",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Event Producer Stats",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/events.PublisherStats"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/summary": {
+            "get": {
+                "description": "Count and total the orders matching the filters per currency and converted into the report currency with the stored exchange rates",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Order summary",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 currency of the converted totals. Defaults to DEFAULT_CURRENCY",
+                        "name": "report_currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only orders of this user",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "NEW",
+                            "CONFIRMED",
+                            "PARTIALLY_SHIPPED",
+                            "SHIPPED",
+                            "PARTIALLY_DELIVERED",
+                            "DELIVERED",
+                            "CANCELLED",
+                            "RETURNED"
+                        ],
+                        "type": "string",
+                        "description": "Only orders in this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only orders placed in this currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only orders containing this product",
+                        "name": "product_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC 3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or before (RFC 3339)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Updated at or after (RFC 3339)",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Updated at or before (RFC 3339)",
+                        "name": "updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum total amount in the order currency",
+                        "name": "min_total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum total amount in the order currency",
+                        "name": "max_total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.OrderSummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}": {
+            "get": {
+                "description": "Fetch an order by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Fetch Order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/services.OrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the status of an order by ID. Moving it to SHIPPED ships every line not shipped yet and requires the tracking_number and carrier; moving it to DELIVERED delivers every shipment",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Updaate Order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Order payload",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schemas.OrderUpdateSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete an order by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Delete Order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Order deleted successfully"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/history": {
+            "get": {
+                "description": "Fetch the status change timeline of an order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Order status history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OrderStatusHistory"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/shipments": {
+            "get": {
+                "description": "List the shipments of an order with the quantity of every order line they carry",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Order shipments",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Shipment"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Ship quantities of the order lines. The order moves to PARTIALLY_SHIPPED until every line has shipped and then to SHIPPED",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Create Shipment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Shipment payload",
+                        "name": "shipment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schemas.ShipmentSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Shipment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/shipments/{shipmentId}/deliver": {
+            "post": {
+                "description": "Mark a shipment delivered. The order moves to PARTIALLY_DELIVERED until every line has been delivered and then to DELIVERED",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orders"
+                ],
+                "summary": "Deliver Shipment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shipment ID",
+                        "name": "shipmentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Shipment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "Retrieve a page of products matching the search and filters",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Get products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Text searched in name and description",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum price",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum price",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only products in (true) or out of (false) stock",
+                        "name": "in_stock",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only products in this category",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "name",
+                            "-name",
+                            "price",
+                            "-price",
+                            "stock",
+                            "-stock",
+                            "created_at",
+                            "-created_at"
+                        ],
+                        "type": "string",
+                        "default": "name",
+                        "description": "Sort key, prefixed with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of products to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_Product"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a new product",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Create product",
+                "parameters": [
+                    {
+                        "description": "Product payload",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schemas.Product"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.Product"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/search": {
+            "get": {
+                "description": "Full-text search over product names and descriptions, ranked by relevance with the matched words highlighted.\nWhen nothing matches, products with a similar name or description are returned instead (match=similar)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Search products",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search text; supports quoted phrases, OR and -excluded words",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of results to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-schemas_ProductSearchResult"
+                        }
+                    }
+                }
+            }
+        },
+        "/products/{id}": {
+            "get": {
+                "description": "Get a product by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Get product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.Product"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a new product",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Update product",
+                "parameters": [
+                    {
+                        "description": "Product payload",
+                        "name": "product",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schemas.Product"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/schemas.Product"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete product by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Products"
+                ],
+                "summary": "Delete product",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Product ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/promotions": {
+            "get": {
+                "description": "Retrieve a page of promotions, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Promotions"
+                ],
+                "summary": "Get promotions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only the promotion of this coupon code",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only active (true) or inactive (false) promotions",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of promotions to skip",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/pagination.Page-models_Promotion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a promotion redeemed with a coupon code: a percentage or fixed amount off the targeted order lines, or free shipping",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Promotions"
+                ],
+                "summary": "Create promotion",
+                "parameters": [
+                    {
+                        "description": "Promotion payload",
+                        "name": "promotion",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schemas.PromotionSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Promotion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/promotions/{id}": {
+            "get": {
+                "description": "Get a promotion by ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Promotions"
+                ],
+                "summary": "Get promotion",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Promotion ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Promotion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace a promotion by ID. Orders that already redeemed it keep their discounts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Promotions"
+                ],
+                "summary": "Update promotion",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Promotion ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Promotion payload",
+                        "name": "promotion",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/schemas.PromotionSchema"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Promotion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a promotion by ID. Its coupon code can no longer be redeemed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Promotions"
+                ],
+                "summary": "Delete promotion",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Promotion ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/middleware.GlobalErrorHandlerResp"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "events.PartitionStatus": {
+            "type": "object",
+            "properties": {
+                "committed_offset": {
+                    "type": "integer"
+                },
+                "high_watermark": {
+                    "type": "integer"
+                },
+                "lag": {
+                    "type": "integer"
+                },
+                "partition": {
+                    "type": "integer"
+                },
+                "topic": {
+                    "type": "string"
+                }
+            }
+        },
+        "events.PublisherStats": {
+            "type": "object",
+            "properties": {
+                "delivered": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "in_flight": {
+                    "type": "integer"
+                },
+                "produced": {
+                    "type": "integer"
+                }
+            }
+        },
+        "fiber.Map": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "gorm.DeletedAt": {
+            "type": "object",
+            "properties": {
+                "time": {
+                    "type": "string"
+                },
+                "valid": {
+                    "description": "Valid is true if Time is not NULL",
+                    "type": "boolean"
+                }
+            }
+        },
+        "middleware.ConsumerState": {
+            "type": "string",
+            "enum": [
+                "STOPPED",
+                "RUNNING",
+                "STOPPING",
+                "FAILED"
+            ],
+            "x-enum-varnames": [
+                "ConsumerStopped",
+                "ConsumerRunning",
+                "ConsumerStopping",
+                "ConsumerFailed"
+            ]
+        },
+        "middleware.ConsumerStatus": {
+            "type": "object",
+            "properties": {
+                "last_error": {
+                    "type": "string"
+                },
+                "partitions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/events.PartitionStatus"
+                    }
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/middleware.ConsumerState"
+                },
+                "topics": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "total_lag": {
+                    "type": "integer"
+                }
+            }
+        },
+        "middleware.GlobalErrorHandlerResp": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.Address": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string"
+                },
+                "country": {
+                    "description": "Country is an ISO 3166-1 alpha-2 country code.",
+                    "type": "string"
+                },
+                "line1": {
+                    "type": "string"
+                },
+                "line2": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "postal_code": {
+                    "type": "string"
+                },
+                "region": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DeadLetterMessage": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "error_type": {
+                    "type": "string"
+                },
+                "failed_at": {
+                    "type": "string"
+                },
+                "headers": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "original_offset": {
+                    "type": "integer"
+                },
+                "original_partition": {
+                    "type": "integer"
+                },
+                "original_topic": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "published_at": {
+                    "type": "string"
+                },
+                "replay_count": {
+                    "type": "integer"
+                },
+                "replayed_at": {
+                    "type": "string"
+                },
+                "retry_count": {
+                    "type": "integer"
+                },
+                "topic": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Order": {
+            "type": "object",
+            "properties": {
+                "billing_address": {
+                    "$ref": "#/definitions/models.Address"
+                },
+                "carrier": {
+                    "type": "string"
+                },
+                "coupon_code": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Currency is the ISO 4217 currency the order was placed and priced in.",
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "discount_amount": {
+                    "description": "DiscountAmount is the sum of the line discounts of the redeemed coupon.",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "order_items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderItem"
+                    }
+                },
+                "promotion_id": {
+                    "type": "integer"
+                },
+                "shipped_at": {
+                    "type": "string"
+                },
+                "shipping_address": {
+                    "description": "ShippingAddress and BillingAddress are empty for orders placed without a shipping method.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Address"
+                        }
+                    ]
+                },
+                "shipping_cost": {
+                    "type": "number"
+                },
+                "shipping_method": {
+                    "description": "ShippingMethod is the code of the shipping method and ShippingCost its cost in the order currency,\nwaived by free shipping promotions. Shipping is not taxed.",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "subtotal": {
+                    "description": "Subtotal is the amount after discount and before tax; TotalAmount is Subtotal plus TaxAmount plus ShippingCost.",
+                    "type": "number"
+                },
+                "tax_amount": {
+                    "type": "number"
+                },
+                "tax_lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.OrderTaxLine"
+                    }
+                },
+                "tax_mode": {
+                    "description": "TaxMode tells whether the line totals excluded or included tax when the order was placed.",
+                    "type": "string"
+                },
+                "tax_region": {
+                    "description": "TaxRegion is the region the order was taxed in; orders without one are not taxed.",
+                    "type": "string"
+                },
+                "total_amount": {
+                    "type": "number"
+                },
+                "tracking_number": {
+                    "description": "TrackingNumber and Carrier are those of the latest shipment; ShippedAt is set once every line has shipped.",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OrderItem": {
+            "type": "object",
+            "properties": {
+                "delivered_quantity": {
+                    "type": "integer"
+                },
+                "discount_amount": {
+                    "description": "DiscountAmount is taken off the line total, UnitPrice times Quantity, before tax.",
+                    "type": "number"
+                },
+                "fulfillment_status": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "integer"
+                },
+                "shipped_quantity": {
+                    "description": "ShippedQuantity and DeliveredQuantity count the units of the line in shipments and in delivered\nshipments; FulfillmentStatus is derived from them.",
+                    "type": "integer"
+                },
+                "tax_amount": {
+                    "type": "number"
+                },
+                "tax_category": {
+                    "description": "TaxCategory is the tax category of the product when the order was placed.",
+                    "type": "string"
+                },
+                "unit_price": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.OrderStatusHistory": {
+            "type": "object",
+            "properties": {
+                "changed_by": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "from_status": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "to_status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OrderTaxLine": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "tax_amount": {
+                    "type": "number"
+                },
+                "taxable_amount": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Product": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "Category groups products for promotions and listing, such as \"books\".",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Currency is the ISO 4217 currency of Price.",
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "image_url": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "prices": {
+                    "description": "Prices holds the prices in currencies other than Currency.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductPrice"
+                    }
+                },
+                "stock": {
+                    "type": "integer"
+                },
+                "tax_category": {
+                    "description": "TaxCategory picks the tax rates of the product, such as \"standard\", \"food\" or \"books\".",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ProductPrice": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Promotion": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "amount": {
+                    "description": "Amount is the discount of fixed amount promotions, in Currency.",
+                    "type": "number"
+                },
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PromotionCategory"
                     }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
+                },
+                "code": {
+                    "description": "Code is the coupon code, stored in upper case.",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Currency is the ISO 4217 currency of Amount and MinOrderTotal. Orders in other currencies can only\nredeem the promotion when neither is set.",
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "min_order_total": {
+                    "description": "MinOrderTotal is the smallest sum of the order lines, in Currency, the promotion applies to.",
+                    "type": "number"
+                },
+                "percentage": {
+                    "description": "Percentage is the discount of percentage promotions, such as 0.15.",
+                    "type": "number"
+                },
+                "products": {
+                    "description": "Products and Categories restrict the promotion to the order lines of these products or categories.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PromotionProduct"
                     }
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "usage_limit_per_user": {
+                    "description": "UsageLimitPerUser caps the orders of one user that may redeem the promotion; 0 means no limit.",
+                    "type": "integer"
                 }
             }
-        }
-    },
-    "definitions": {
-        "fiber.Map": {
+        },
+        "models.PromotionCategory": {
             "type": "object",
-            "additionalProperties": true
+            "properties": {
+                "category": {
+                    "type": "string"
+                }
+            }
         },
-        "gorm.DeletedAt": {
+        "models.PromotionProduct": {
             "type": "object",
             "properties": {
-                "time": {
+                "product_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Shipment": {
+            "type": "object",
+            "properties": {
+                "carrier": {
                     "type": "string"
                 },
-                "valid": {
-                    "description": "Valid is true if Time is not NULL",
-                    "type": "boolean"
+                "delivered_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ShipmentItem"
+                    }
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "shipped_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tracking_number": {
+                    "type": "string"
                 }
             }
         },
-        "middleware.GlobalErrorHandlerResp": {
+        "models.ShipmentItem": {
             "type": "object",
             "properties": {
-                "code": {
+                "order_item_id": {
                     "type": "integer"
                 },
-                "errors": {
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pagination.Page-models_Order": {
+            "type": "object",
+            "properties": {
+                "items": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/models.Order"
                     }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
-        "models.Order": {
+        "pagination.Page-models_Product": {
             "type": "object",
             "properties": {
-                "createdAt": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Product"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
                     "type": "string"
                 },
-                "deletedAt": {
-                    "$ref": "#/definitions/gorm.DeletedAt"
+                "offset": {
+                    "type": "integer"
                 },
-                "id": {
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pagination.Page-models_Promotion": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Promotion"
+                    }
+                },
+                "limit": {
                     "type": "integer"
                 },
-                "order_items": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "pagination.Page-schemas_ProductSearchResult": {
+            "type": "object",
+            "properties": {
+                "items": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.OrderItem"
+                        "$ref": "#/definitions/schemas.ProductSearchResult"
                     }
                 },
-                "status": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
                     "type": "string"
                 },
-                "total_amount": {
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "schemas.AddressSchema": {
+            "type": "object",
+            "required": [
+                "city",
+                "country",
+                "line1",
+                "name"
+            ],
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "country": {
+                    "type": "string"
+                },
+                "line1": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "line2": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "postal_code": {
+                    "type": "string",
+                    "maxLength": 20
+                },
+                "region": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "schemas.CartItemSchema": {
+            "type": "object",
+            "required": [
+                "product_id",
+                "quantity"
+            ],
+            "properties": {
+                "product_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "quantity": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1
+                }
+            }
+        },
+        "schemas.CartItemUpdateSchema": {
+            "type": "object",
+            "required": [
+                "quantity"
+            ],
+            "properties": {
+                "quantity": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1
+                }
+            }
+        },
+        "schemas.CartLine": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "integer"
+                },
+                "line_total": {
                     "type": "number"
                 },
-                "updatedAt": {
+                "name": {
                     "type": "string"
                 },
-                "user_id": {
+                "product_id": {
+                    "type": "integer"
+                },
+                "quantity": {
                     "type": "integer"
+                },
+                "unit_price": {
+                    "type": "number"
+                },
+                "warning": {
+                    "type": "string"
                 }
             }
         },
-        "models.OrderItem": {
+        "schemas.CartResponse": {
             "type": "object",
             "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "product_id": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schemas.CartLine"
+                    }
+                },
+                "subtotal": {
+                    "type": "number"
+                },
+                "tax_region": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "integer"
+                }
+            }
+        },
+        "schemas.CartSchema": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "currency": {
+                    "type": "string"
                 },
-                "quantity": {
+                "tax_region": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "schemas.CheckoutSchema": {
+            "type": "object",
+            "properties": {
+                "billing_address": {
+                    "$ref": "#/definitions/schemas.AddressSchema"
+                },
+                "coupon_code": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "shipping_address": {
+                    "$ref": "#/definitions/schemas.AddressSchema"
+                },
+                "shipping_method": {
+                    "type": "string",
+                    "maxLength": 50
+                }
+            }
+        },
+        "schemas.CurrencyTotal": {
+            "type": "object",
+            "properties": {
+                "converted_amount": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "order_count": {
                     "type": "integer"
                 },
-                "unit_price": {
+                "total_amount": {
                     "type": "number"
                 }
             }
@@ -521,10 +2899,12 @@ const docTemplate = `{
             "type": "object",
             "required": [
                 "product_id",
-                "quantity",
-                "unit_price"
+                "quantity"
             ],
             "properties": {
+                "currency": {
+                    "type": "string"
+                },
                 "product_id": {
                     "type": "integer",
                     "minimum": 1
@@ -532,9 +2912,6 @@ const docTemplate = `{
                 "quantity": {
                     "type": "integer",
                     "minimum": 1
-                },
-                "unit_price": {
-                    "type": "number"
                 }
             }
         },
@@ -542,22 +2919,35 @@ const docTemplate = `{
             "type": "object",
             "required": [
                 "order_items",
-                "status",
                 "user_id"
             ],
             "properties": {
+                "billing_address": {
+                    "$ref": "#/definitions/schemas.AddressSchema"
+                },
+                "coupon_code": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "currency": {
+                    "type": "string"
+                },
                 "order_items": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/schemas.OrderItemSchema"
                     }
                 },
+                "shipping_address": {
+                    "$ref": "#/definitions/schemas.AddressSchema"
+                },
+                "shipping_method": {
+                    "type": "string",
+                    "maxLength": 50
+                },
                 "status": {
                     "enum": [
-                        "NEW",
-                        "SHIPPED",
-                        "DELIVERED",
-                        "CANCELLED"
+                        "NEW"
                     ],
                     "allOf": [
                         {
@@ -565,6 +2955,9 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "tax_region": {
+                    "type": "string"
+                },
                 "user_id": {
                     "type": "integer",
                     "minimum": 1
@@ -575,35 +2968,81 @@ const docTemplate = `{
             "type": "string",
             "enum": [
                 "NEW",
+                "CONFIRMED",
                 "SHIPPED",
                 "DELIVERED",
-                "CANCELLED"
+                "CANCELLED",
+                "RETURNED",
+                "PARTIALLY_SHIPPED",
+                "PARTIALLY_DELIVERED"
             ],
             "x-enum-varnames": [
                 "StatusNew",
+                "StatusConfirmed",
                 "StatusShipped",
                 "StatusDelivered",
-                "StatusCancelled"
+                "StatusCancelled",
+                "StatusReturned",
+                "StatusPartiallyShipped",
+                "StatusPartiallyDelivered"
             ]
         },
+        "schemas.OrderSummary": {
+            "type": "object",
+            "properties": {
+                "by_currency": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/schemas.CurrencyTotal"
+                    }
+                },
+                "order_count": {
+                    "type": "integer"
+                },
+                "report_currency": {
+                    "type": "string"
+                },
+                "total_amount": {
+                    "type": "number"
+                }
+            }
+        },
         "schemas.OrderUpdateSchema": {
             "type": "object",
             "required": [
                 "status"
             ],
             "properties": {
+                "carrier": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "changed_by": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500
+                },
                 "status": {
                     "enum": [
                         "NEW",
+                        "CONFIRMED",
                         "SHIPPED",
                         "DELIVERED",
-                        "CANCELLED"
+                        "CANCELLED",
+                        "RETURNED"
                     ],
                     "allOf": [
                         {
                             "$ref": "#/definitions/schemas.OrderStatus"
                         }
                     ]
+                },
+                "tracking_number": {
+                    "type": "string",
+                    "maxLength": 100
                 }
             }
         },
@@ -615,6 +3054,13 @@ const docTemplate = `{
                 "price"
             ],
             "properties": {
+                "category": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "currency": {
+                    "type": "string"
+                },
                 "description": {
                     "type": "string",
                     "maxLength": 1000,
@@ -631,8 +3077,324 @@ const docTemplate = `{
                 "price": {
                     "type": "number"
                 },
+                "prices": {
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "$ref": "#/definitions/schemas.ProductPrice"
+                    }
+                },
+                "stock": {
+                    "type": "integer"
+                },
+                "tax_category": {
+                    "type": "string",
+                    "maxLength": 50
+                }
+            }
+        },
+        "schemas.ProductPrice": {
+            "type": "object",
+            "required": [
+                "amount",
+                "currency"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                }
+            }
+        },
+        "schemas.ProductSearchResult": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "description": "Category groups products for promotions and listing, such as \"books\".",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "Currency is the ISO 4217 currency of Price.",
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "$ref": "#/definitions/gorm.DeletedAt"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "image_url": {
+                    "type": "string"
+                },
+                "match": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "name_highlight": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "prices": {
+                    "description": "Prices holds the prices in currencies other than Currency.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductPrice"
+                    }
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "snippet": {
+                    "type": "string"
+                },
                 "stock": {
                     "type": "integer"
+                },
+                "tax_category": {
+                    "description": "TaxCategory picks the tax rates of the product, such as \"standard\", \"food\" or \"books\".",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "schemas.PromotionCategorySchema": {
+            "type": "object",
+            "required": [
+                "category"
+            ],
+            "properties": {
+                "category": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "schemas.PromotionProductSchema": {
+            "type": "object",
+            "required": [
+                "product_id"
+            ],
+            "properties": {
+                "product_id": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "schemas.PromotionSchema": {
+            "type": "object",
+            "required": [
+                "code",
+                "type"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "amount": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "categories": {
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "$ref": "#/definitions/schemas.PromotionCategorySchema"
+                    }
+                },
+                "code": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "ends_at": {
+                    "type": "string"
+                },
+                "min_order_total": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "percentage": {
+                    "type": "number",
+                    "maximum": 1000000,
+                    "minimum": 0
+                },
+                "products": {
+                    "type": "array",
+                    "uniqueItems": true,
+                    "items": {
+                        "$ref": "#/definitions/schemas.PromotionProductSchema"
+                    }
+                },
+                "starts_at": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "percentage",
+                        "fixed_amount",
+                        "free_shipping"
+                    ]
+                },
+                "usage_limit_per_user": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "schemas.ShipmentItemSchema": {
+            "type": "object",
+            "required": [
+                "order_item_id",
+                "quantity"
+            ],
+            "properties": {
+                "order_item_id": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "quantity": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "schemas.ShipmentSchema": {
+            "type": "object",
+            "required": [
+                "carrier",
+                "items",
+                "tracking_number"
+            ],
+            "properties": {
+                "carrier": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "items": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/schemas.ShipmentItemSchema"
+                    }
+                },
+                "tracking_number": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "schemas.ShippingMethodSchema": {
+            "type": "object",
+            "required": [
+                "currency",
+                "name"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "cost": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "schemas.TaxRateSchema": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "rate": {
+                    "type": "number",
+                    "maximum": 1000000,
+                    "minimum": 0
+                }
+            }
+        },
+        "services.OrderResponse": {
+            "type": "object",
+            "properties": {
+                "order": {
+                    "$ref": "#/definitions/models.Order"
+                },
+                "user": {}
+            }
+        },
+        "shipping.Method": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Active methods can be chosen for new orders.",
+                    "type": "boolean"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "cost": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "tax.TaxRate": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "region": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         }
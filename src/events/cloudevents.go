@@ -0,0 +1,131 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CloudEventsSpecVersion = "1.0"
+	// CloudEventsContentType marks a message whose value is a structured-mode CloudEvent.
+	CloudEventsContentType = "application/cloudevents+json"
+	DataContentTypeJSON    = "application/json"
+)
+
+// Headers carried next to the event. In structured mode only content-type, ce_id and ce_type are set so
+// consumers can route without decoding the value; in binary mode all attributes travel as ce_ headers.
+const (
+	HeaderContentType   = "content-type"
+	HeaderEventID       = "ce_id"
+	headerSpecVersion   = "ce_specversion"
+	headerSource        = "ce_source"
+	headerSubject       = "ce_subject"
+	headerTime          = "ce_time"
+	headerSchemaVersion = "ce_schemaversion"
+	headerCorrelationID = "ce_correlationid"
+)
+
+// CloudEvent is the versioned envelope of every published event, following the CloudEvents 1.0
+// specification in structured JSON mode. SchemaVersion and CorrelationID are extension attributes:
+// SchemaVersion versions the shape of Data independently of the envelope, and CorrelationID ties
+// the event to the request (or the event) that caused it.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	Source          string          `json:"source"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	SchemaVersion   string          `json:"schemaversion"`
+	CorrelationID   string          `json:"correlationid,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// NewCloudEvent creates an envelope with a fresh ID, taking the correlation ID from ctx.
+// Data and DataContentType are filled in when the event is serialized.
+func NewCloudEvent(ctx context.Context, eventType, source, subject, schemaVersion string) CloudEvent {
+	return CloudEvent{
+		SpecVersion:   CloudEventsSpecVersion,
+		ID:            uuid.New().String(),
+		Type:          eventType,
+		Source:        source,
+		Subject:       subject,
+		Time:          time.Now().UTC(),
+		SchemaVersion: schemaVersion,
+		CorrelationID: CorrelationID(ctx),
+	}
+}
+
+// ParseCloudEvent decodes a structured-mode event and checks the attributes the specification requires.
+func ParseCloudEvent(value []byte) (CloudEvent, error) {
+	var event CloudEvent
+	if err := json.Unmarshal(value, &event); err != nil {
+		return event, err
+	}
+	return event, event.validate()
+}
+
+// BinaryHeaders returns the event attributes as headers for binary-mode messages, whose value is the data alone.
+func (e CloudEvent) BinaryHeaders() map[string]string {
+	headers := map[string]string{
+		HeaderContentType:   e.DataContentType,
+		HeaderEventID:       e.ID,
+		EventTypeHeader:     e.Type,
+		headerSpecVersion:   e.SpecVersion,
+		headerSource:        e.Source,
+		headerTime:          e.Time.Format(time.RFC3339Nano),
+		headerSchemaVersion: e.SchemaVersion,
+	}
+	if e.Subject != "" {
+		headers[headerSubject] = e.Subject
+	}
+	if e.CorrelationID != "" {
+		headers[headerCorrelationID] = e.CorrelationID
+	}
+	return headers
+}
+
+// ParseBinaryCloudEvent rebuilds an event from binary-mode headers and the message value.
+func ParseBinaryCloudEvent(headers map[string]string, value []byte) (CloudEvent, error) {
+	event := CloudEvent{
+		SpecVersion:     headers[headerSpecVersion],
+		ID:              headers[HeaderEventID],
+		Type:            headers[EventTypeHeader],
+		Source:          headers[headerSource],
+		Subject:         headers[headerSubject],
+		DataContentType: headers[HeaderContentType],
+		SchemaVersion:   headers[headerSchemaVersion],
+		CorrelationID:   headers[headerCorrelationID],
+		Data:            value,
+	}
+	if eventTime, err := time.Parse(time.RFC3339Nano, headers[headerTime]); err == nil {
+		event.Time = eventTime
+	}
+	return event, event.validate()
+}
+
+func (e CloudEvent) validate() error {
+	if e.SpecVersion != CloudEventsSpecVersion {
+		return errors.New("unsupported cloudevents specversion: " + e.SpecVersion)
+	}
+	if e.ID == "" || e.Type == "" || e.Source == "" {
+		return errors.New("cloudevent is missing id, type or source")
+	}
+	return nil
+}
+
+type correlationIDKey struct{}
+
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, correlationID)
+}
+
+func CorrelationID(ctx context.Context) string {
+	correlationID, _ := ctx.Value(correlationIDKey{}).(string)
+	return correlationID
+}
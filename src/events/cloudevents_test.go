@@ -0,0 +1,52 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloudEvent(t *testing.T) {
+
+	t.Run("Envelope round trips with data and correlation ID", func(t *testing.T) {
+		ctx := WithCorrelationID(context.Background(), "request-1")
+		event := NewCloudEvent(ctx, "order.created", "/orders", "orders/1", "1.0")
+		event.DataContentType = DataContentTypeJSON
+		event.Data = []byte(`{"order_id":1}`)
+
+		value, err := json.Marshal(event)
+		assert.NoError(t, err)
+		parsed, err := ParseCloudEvent(value)
+		assert.NoError(t, err)
+		assert.Equal(t, event.ID, parsed.ID)
+		assert.Equal(t, CloudEventsSpecVersion, parsed.SpecVersion)
+		assert.Equal(t, "request-1", parsed.CorrelationID)
+		assert.Equal(t, "1.0", parsed.SchemaVersion)
+
+		var data map[string]int
+		assert.NoError(t, json.Unmarshal(parsed.Data, &data))
+		assert.Equal(t, 1, data["order_id"])
+	})
+
+	t.Run("Binary mode carries attributes in headers", func(t *testing.T) {
+		event := NewCloudEvent(context.Background(), "order.deleted", "/orders", "orders/2", "1.0")
+		event.DataContentType = "application/avro"
+
+		parsed, err := ParseBinaryCloudEvent(event.BinaryHeaders(), []byte{0, 1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, event.ID, parsed.ID)
+		assert.Equal(t, "order.deleted", parsed.Type)
+		assert.Equal(t, "orders/2", parsed.Subject)
+		assert.True(t, event.Time.Equal(parsed.Time))
+		assert.Equal(t, []byte{0, 1, 2}, []byte(parsed.Data))
+	})
+
+	t.Run("Events without required attributes are rejected", func(t *testing.T) {
+		_, err := ParseCloudEvent([]byte(`{"specversion":"1.0","type":"order.created"}`))
+		assert.Error(t, err)
+		_, err = ParseCloudEvent([]byte(`{"ID":1,"user_id":2}`))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,53 @@
+package events
+
+import "context"
+
+// Message is a broker-agnostic event as it travels over the bus.
+type Message struct {
+	Topic     string
+	Key       []byte
+	Value     []byte
+	Headers   map[string]string
+	Partition int32
+	Offset    int64
+}
+
+// Handler processes a consumed message. Returning an error leaves the message unacknowledged.
+type Handler func(ctx context.Context, msg Message) error
+
+type PublisherStats struct {
+	Produced  int64 `json:"produced"`
+	Delivered int64 `json:"delivered"`
+	Failed    int64 `json:"failed"`
+	InFlight  int   `json:"in_flight"`
+}
+
+type EventPublisher interface {
+	// Publish sends msg and returns once the bus has accepted it durably.
+	Publish(ctx context.Context, msg Message) error
+	Stats() PublisherStats
+	Close()
+}
+
+type EventSubscriber interface {
+	// Subscribe delivers messages from topics to handler until ctx is cancelled.
+	Subscribe(ctx context.Context, topics []string, handler Handler) error
+}
+
+type PartitionStatus struct {
+	Topic           string `json:"topic"`
+	Partition       int32  `json:"partition"`
+	CommittedOffset int64  `json:"committed_offset"`
+	HighWatermark   int64  `json:"high_watermark"`
+	Lag             int64  `json:"lag"`
+}
+
+type SubscriptionStatus struct {
+	Partitions []PartitionStatus `json:"partitions"`
+	LastError  string            `json:"last_error,omitempty"`
+}
+
+// StatusReporter is implemented by subscribers that can describe their active subscription.
+type StatusReporter interface {
+	Status() SubscriptionStatus
+}
@@ -0,0 +1,111 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// MemoryBus is an in-process EventPublisher and EventSubscriber backed by channels.
+// It keeps every published message so tests can assert on them without a broker.
+type MemoryBus struct {
+	mu          sync.Mutex
+	published   []Message
+	subscribers map[string][]chan Message
+	offsets     map[string]int64
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{
+		subscribers: map[string][]chan Message{},
+		offsets:     map[string]int64{},
+	}
+}
+
+func (b *MemoryBus) Publish(ctx context.Context, msg Message) error {
+	b.mu.Lock()
+	msg.Offset = b.offsets[msg.Topic]
+	b.offsets[msg.Topic]++
+	b.published = append(b.published, msg)
+	subscribers := append([]chan Message(nil), b.subscribers[msg.Topic]...)
+	b.mu.Unlock()
+
+	for _, ch := range subscribers {
+		select {
+		case ch <- msg:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBus) Subscribe(ctx context.Context, topics []string, handler Handler) error {
+	ch := make(chan Message, 1024)
+	b.mu.Lock()
+	for _, topic := range topics {
+		b.subscribers[topic] = append(b.subscribers[topic], ch)
+	}
+	b.mu.Unlock()
+
+	defer b.unsubscribe(topics, ch)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg := <-ch:
+			// Retried messages that are not due yet come back once due. There is no other redelivery in
+			// memory, so a failed message is simply dropped.
+			var notDue *NotDueError
+			if err := handler(ctx, msg); errors.As(err, &notDue) {
+				redeliver(ctx, ch, msg, notDue.Until)
+			}
+		}
+	}
+}
+
+// redeliver puts msg back on ch at the given time without holding up the other messages.
+func redeliver(ctx context.Context, ch chan Message, msg Message, at time.Time) {
+	time.AfterFunc(time.Until(at), func() {
+		select {
+		case ch <- msg:
+		case <-ctx.Done():
+		}
+	})
+}
+
+func (b *MemoryBus) unsubscribe(topics []string, ch chan Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, topic := range topics {
+		subscribers := b.subscribers[topic]
+		for i, sub := range subscribers {
+			if sub == ch {
+				b.subscribers[topic] = append(subscribers[:i], subscribers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Published returns the messages published to topic so far, oldest first.
+func (b *MemoryBus) Published(topic string) []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var messages []Message
+	for _, msg := range b.published {
+		if msg.Topic == topic {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+func (b *MemoryBus) Stats() PublisherStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return PublisherStats{Produced: int64(len(b.published)), Delivered: int64(len(b.published))}
+}
+
+func (b *MemoryBus) Close() {}
@@ -0,0 +1,134 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBus(t *testing.T) {
+
+	t.Run("Published messages are recorded per topic", func(t *testing.T) {
+		bus := NewMemoryBus()
+		assert.NoError(t, bus.Publish(context.Background(), Message{Topic: "orders", Key: []byte("1")}))
+		assert.NoError(t, bus.Publish(context.Background(), Message{Topic: "orders", Key: []byte("2")}))
+		assert.NoError(t, bus.Publish(context.Background(), Message{Topic: "products", Key: []byte("3")}))
+
+		published := bus.Published("orders")
+		assert.Len(t, published, 2)
+		assert.Equal(t, "1", string(published[0].Key))
+		assert.Equal(t, int64(1), published[1].Offset)
+		assert.Equal(t, int64(3), bus.Stats().Produced)
+	})
+
+	t.Run("Subscribers receive messages of their topics", func(t *testing.T) {
+		bus := NewMemoryBus()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		received := make(chan Message, 1)
+		go func() {
+			bus.Subscribe(ctx, []string{"orders"}, func(ctx context.Context, msg Message) error {
+				received <- msg
+				return nil
+			})
+		}()
+		assert.Eventually(t, func() bool {
+			bus.mu.Lock()
+			defer bus.mu.Unlock()
+			return len(bus.subscribers["orders"]) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		assert.NoError(t, bus.Publish(ctx, Message{Topic: "orders", Value: []byte("payload")}))
+		select {
+		case msg := <-received:
+			assert.Equal(t, "payload", string(msg.Value))
+		case <-time.After(time.Second):
+			t.Fatal("message was not delivered")
+		}
+	})
+
+	t.Run("Order events reach every subscriber of the orders topic", func(t *testing.T) {
+		bus := NewMemoryBus()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		orders := make(chan Message, 2)
+		products := make(chan Message, 1)
+		for _, topic := range []string{"orders", "orders", "products"} {
+			received := orders
+			if topic == "products" {
+				received = products
+			}
+			go func() {
+				bus.Subscribe(ctx, []string{topic}, func(ctx context.Context, msg Message) error {
+					received <- msg
+					return nil
+				})
+			}()
+		}
+		assert.Eventually(t, func() bool {
+			bus.mu.Lock()
+			defer bus.mu.Unlock()
+			return len(bus.subscribers["orders"]) == 2 && len(bus.subscribers["products"]) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		assert.NoError(t, bus.Publish(ctx, Message{
+			Topic:   "orders",
+			Key:     []byte("42"),
+			Value:   []byte(`{"id": 42, "status": "NEW"}`),
+			Headers: map[string]string{"event_type": "OrderCreated", "aggregate_type": "order", "aggregate_id": "42"},
+		}))
+		for i := 0; i < 2; i++ {
+			select {
+			case msg := <-orders:
+				assert.Equal(t, "OrderCreated", msg.Headers["event_type"])
+				assert.Equal(t, "42", string(msg.Key))
+				assert.JSONEq(t, `{"id": 42, "status": "NEW"}`, string(msg.Value))
+			case <-time.After(time.Second):
+				t.Fatal("order event was not delivered to every orders subscriber")
+			}
+		}
+		select {
+		case msg := <-products:
+			t.Fatalf("products subscriber received %s", msg.Topic)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("Messages not due yet are redelivered without holding up the others", func(t *testing.T) {
+		bus := NewMemoryBus()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		due := time.Now().Add(50 * time.Millisecond)
+		received := make(chan string, 2)
+		go func() {
+			bus.Subscribe(ctx, []string{"orders.retry.1", "orders"}, func(ctx context.Context, msg Message) error {
+				if msg.Topic == "orders.retry.1" && time.Now().Before(due) {
+					return &NotDueError{Until: due}
+				}
+				received <- string(msg.Value)
+				return nil
+			})
+		}()
+		assert.Eventually(t, func() bool {
+			bus.mu.Lock()
+			defer bus.mu.Unlock()
+			return len(bus.subscribers["orders"]) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		assert.NoError(t, bus.Publish(ctx, Message{Topic: "orders.retry.1", Value: []byte("retried")}))
+		assert.NoError(t, bus.Publish(ctx, Message{Topic: "orders", Value: []byte("fresh")}))
+		for _, want := range []string{"fresh", "retried"} {
+			select {
+			case value := <-received:
+				assert.Equal(t, want, value)
+			case <-time.After(time.Second):
+				t.Fatalf("%s message was not delivered", want)
+			}
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// EventTypeHeader is the message header carrying the event type used for routing.
+const EventTypeHeader = "ce_type"
+
+// Registry routes consumed messages to the handler registered for their event type.
+type Registry struct {
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	logger   *slog.Logger
+}
+
+func NewRegistry(logger *slog.Logger) *Registry {
+	return &Registry{handlers: map[string]Handler{}, logger: logger.With("component", "EventRegistry")}
+}
+
+// Register sets the handler for eventType, replacing any previous one.
+func (r *Registry) Register(eventType string, handler Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[eventType] = handler
+}
+
+// Handle dispatches msg to its handler. Messages without a registered handler are
+// acknowledged and skipped so they do not block the partition.
+func (r *Registry) Handle(ctx context.Context, msg Message) error {
+	eventType := msg.Headers[EventTypeHeader]
+	r.mu.RLock()
+	handler, ok := r.handlers[eventType]
+	r.mu.RUnlock()
+	if !ok {
+		r.logger.Debug("No handler registered for event type, skipping", "eventType", eventType, "topic", msg.Topic, "offset", msg.Offset)
+		return nil
+	}
+	return handler(ctx, msg)
+}
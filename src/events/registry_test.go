@@ -0,0 +1,40 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryHandle(t *testing.T) {
+
+	registry := NewRegistry(slog.Default())
+	var handled []string
+	registry.Register("order.created", func(ctx context.Context, msg Message) error {
+		handled = append(handled, string(msg.Key))
+		return nil
+	})
+	registry.Register("order.status_changed", func(ctx context.Context, msg Message) error {
+		return errors.New("restock failed")
+	})
+
+	t.Run("Message is routed by its event type header", func(t *testing.T) {
+		err := registry.Handle(context.Background(), Message{Key: []byte("1"), Headers: map[string]string{EventTypeHeader: "order.created"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"1"}, handled)
+	})
+
+	t.Run("Handler errors are returned to the consumer", func(t *testing.T) {
+		err := registry.Handle(context.Background(), Message{Headers: map[string]string{EventTypeHeader: "order.status_changed"}})
+		assert.EqualError(t, err, "restock failed")
+	})
+
+	t.Run("Unknown event types are skipped", func(t *testing.T) {
+		err := registry.Handle(context.Background(), Message{Headers: map[string]string{EventTypeHeader: "product.created"}})
+		assert.NoError(t, err)
+		assert.Len(t, handled, 1)
+	})
+}
@@ -0,0 +1,202 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// Headers added to messages forwarded to retry and dead-letter topics.
+const (
+	HeaderOriginalTopic     = "x-original-topic"
+	HeaderOriginalPartition = "x-original-partition"
+	HeaderOriginalOffset    = "x-original-offset"
+	HeaderRetryCount        = "x-retry-count"
+	HeaderNotBefore         = "x-not-before"
+	HeaderError             = "x-error"
+	HeaderErrorType         = "x-error-type"
+	HeaderFailedAt          = "x-failed-at"
+)
+
+const (
+	ErrorTypePermanent        = "permanent"
+	ErrorTypeRetriesExhausted = "retries_exhausted"
+)
+
+// PermanentError marks a failure that retrying cannot fix, such as an undecodable payload.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+func Permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// NotDueError is returned for a retried message whose backoff has not elapsed yet. Subscribers hold back
+// the partition of the message until Until and redeliver it then, while their other partitions keep flowing.
+type NotDueError struct {
+	Until time.Time
+}
+
+func (e *NotDueError) Error() string {
+	return "message is not due until " + e.Until.UTC().Format(time.RFC3339)
+}
+
+// DeadLetter is a message that could not be processed, together with why.
+type DeadLetter struct {
+	Topic             string
+	OriginalTopic     string
+	OriginalPartition int32
+	OriginalOffset    int64
+	Key               []byte
+	Value             []byte
+	Headers           map[string]string
+	Error             string
+	ErrorType         string
+	RetryCount        int
+	FailedAt          time.Time
+}
+
+// DeadLetterStore keeps dead letters so they can be listed and replayed. A letter is identified by the
+// original topic, partition and offset of its message, so a redelivered message maps to the same letter.
+type DeadLetterStore interface {
+	// Save stores letter unless the letter of the same message exists, and reports whether that letter
+	// was already published to the dead-letter topic.
+	Save(ctx context.Context, letter DeadLetter) (published bool, err error)
+	// MarkPublished records that letter reached the dead-letter topic.
+	MarkPublished(ctx context.Context, letter DeadLetter) error
+}
+
+type RetryPolicy struct {
+	Topic           string
+	MaxRetries      int
+	Backoff         time.Duration
+	DeadLetterTopic string
+}
+
+// RetryTopic is the topic holding messages waiting for their attempt-th retry.
+func (p RetryPolicy) RetryTopic(attempt int) string {
+	return fmt.Sprintf("%s.retry.%d", p.Topic, attempt)
+}
+
+// Topics lists the main topic followed by its retry chain; a consumer must subscribe to all of them.
+func (p RetryPolicy) Topics() []string {
+	topics := []string{p.Topic}
+	for attempt := 1; attempt <= p.MaxRetries; attempt++ {
+		topics = append(topics, p.RetryTopic(attempt))
+	}
+	return topics
+}
+
+// delay grows exponentially with every retry level.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	return p.Backoff * time.Duration(1<<(attempt-1))
+}
+
+// WithRetries wraps handler so that a failed message is forwarded along the retry topic chain
+// and finally to the dead-letter topic, instead of blocking its partition. The wrapper returns a
+// NotDueError for a retried message that is not due yet, and otherwise only returns an error when
+// forwarding itself fails; in both cases the message must be redelivered.
+func WithRetries(handler Handler, policy RetryPolicy, publisher EventPublisher, store DeadLetterStore, logger *slog.Logger) Handler {
+	logger = logger.With("component", "RetryHandler")
+	return func(ctx context.Context, msg Message) error {
+		if until, ok := notBefore(msg); ok && time.Now().Before(until) {
+			return &NotDueError{Until: until}
+		}
+		err := handler(ctx, msg)
+		if err == nil {
+			return nil
+		}
+
+		retryCount, _ := strconv.Atoi(msg.Headers[HeaderRetryCount])
+		headers := failureHeaders(msg, err)
+		var permanent *PermanentError
+		if errors.As(err, &permanent) || retryCount >= policy.MaxRetries {
+			errorType := ErrorTypeRetriesExhausted
+			if permanent != nil {
+				errorType = ErrorTypePermanent
+			}
+			headers[HeaderErrorType] = errorType
+			logger.Error("Moving message to dead-letter topic", "topic", msg.Topic, "offset", msg.Offset, "retryCount", retryCount, "errorType", errorType, "error", err)
+			return deadLetter(ctx, msg, headers, retryCount, policy, publisher, store)
+		}
+
+		attempt := retryCount + 1
+		headers[HeaderRetryCount] = strconv.Itoa(attempt)
+		headers[HeaderNotBefore] = strconv.FormatInt(time.Now().Add(policy.delay(attempt)).UnixMilli(), 10)
+		logger.Warn("Scheduling message retry", "topic", msg.Topic, "offset", msg.Offset, "attempt", attempt, "error", err)
+		return publisher.Publish(ctx, Message{
+			Topic:   policy.RetryTopic(attempt),
+			Key:     msg.Key,
+			Value:   msg.Value,
+			Headers: headers,
+		})
+	}
+}
+
+// notBefore returns when a retried message becomes due, if it carries a backoff.
+func notBefore(msg Message) (time.Time, bool) {
+	millis, err := strconv.ParseInt(msg.Headers[HeaderNotBefore], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(millis), true
+}
+
+// failureHeaders copies the message headers and records where the message originally came from,
+// keeping the first origin when the message is already on a retry topic.
+func failureHeaders(msg Message, err error) map[string]string {
+	headers := make(map[string]string, len(msg.Headers)+6)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	if _, ok := headers[HeaderOriginalTopic]; !ok {
+		headers[HeaderOriginalTopic] = msg.Topic
+		headers[HeaderOriginalPartition] = strconv.Itoa(int(msg.Partition))
+		headers[HeaderOriginalOffset] = strconv.FormatInt(msg.Offset, 10)
+	}
+	headers[HeaderError] = err.Error()
+	headers[HeaderFailedAt] = time.Now().UTC().Format(time.RFC3339)
+	delete(headers, HeaderNotBefore)
+	return headers
+}
+
+// deadLetter stores the letter before publishing it and records the publish afterwards, so a message
+// redelivered after either step failed is neither stored nor published twice.
+func deadLetter(ctx context.Context, msg Message, headers map[string]string, retryCount int, policy RetryPolicy, publisher EventPublisher, store DeadLetterStore) error {
+	partition, _ := strconv.Atoi(headers[HeaderOriginalPartition])
+	offset, _ := strconv.ParseInt(headers[HeaderOriginalOffset], 10, 64)
+	letter := DeadLetter{
+		Topic:             policy.DeadLetterTopic,
+		OriginalTopic:     headers[HeaderOriginalTopic],
+		OriginalPartition: int32(partition),
+		OriginalOffset:    offset,
+		Key:               msg.Key,
+		Value:             msg.Value,
+		Headers:           headers,
+		Error:             headers[HeaderError],
+		ErrorType:         headers[HeaderErrorType],
+		RetryCount:        retryCount,
+		FailedAt:          time.Now(),
+	}
+	published, err := store.Save(ctx, letter)
+	if err != nil || published {
+		return err
+	}
+	if err := publisher.Publish(ctx, Message{
+		Topic:   policy.DeadLetterTopic,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}); err != nil {
+		return err
+	}
+	return store.MarkPublished(ctx, letter)
+}
@@ -0,0 +1,161 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryDeadLetterStore keeps one letter per original message, like the SQL store.
+type memoryDeadLetterStore struct {
+	letters   []DeadLetter
+	published []bool
+}
+
+func (s *memoryDeadLetterStore) find(letter DeadLetter) int {
+	for i, stored := range s.letters {
+		if stored.OriginalTopic == letter.OriginalTopic && stored.OriginalPartition == letter.OriginalPartition && stored.OriginalOffset == letter.OriginalOffset {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *memoryDeadLetterStore) Save(ctx context.Context, letter DeadLetter) (bool, error) {
+	if i := s.find(letter); i >= 0 {
+		return s.published[i], nil
+	}
+	s.letters = append(s.letters, letter)
+	s.published = append(s.published, false)
+	return false, nil
+}
+
+func (s *memoryDeadLetterStore) MarkPublished(ctx context.Context, letter DeadLetter) error {
+	if i := s.find(letter); i >= 0 {
+		s.published[i] = true
+	}
+	return nil
+}
+
+// unavailableBus fails every publish while down is set.
+type unavailableBus struct {
+	*MemoryBus
+	down bool
+}
+
+func (b *unavailableBus) Publish(ctx context.Context, msg Message) error {
+	if b.down {
+		return errors.New("broker unavailable")
+	}
+	return b.MemoryBus.Publish(ctx, msg)
+}
+
+func TestWithRetries(t *testing.T) {
+
+	policy := RetryPolicy{Topic: "orders", MaxRetries: 2, Backoff: time.Millisecond, DeadLetterTopic: "orders.dlq"}
+	failing := func(ctx context.Context, msg Message) error { return errors.New("database unavailable") }
+
+	t.Run("Retry chain covers every attempt", func(t *testing.T) {
+		assert.Equal(t, []string{"orders", "orders.retry.1", "orders.retry.2"}, policy.Topics())
+	})
+
+	t.Run("Failed message is forwarded to the next retry topic", func(t *testing.T) {
+		bus := NewMemoryBus()
+		store := &memoryDeadLetterStore{}
+		handler := WithRetries(failing, policy, bus, store, slog.Default())
+
+		err := handler(context.Background(), Message{Topic: "orders", Partition: 2, Offset: 7, Key: []byte("1"), Value: []byte("{}")})
+		assert.NoError(t, err)
+
+		retried := bus.Published("orders.retry.1")
+		assert.Len(t, retried, 1)
+		assert.Equal(t, "1", retried[0].Headers[HeaderRetryCount])
+		assert.Equal(t, "orders", retried[0].Headers[HeaderOriginalTopic])
+		assert.Equal(t, "7", retried[0].Headers[HeaderOriginalOffset])
+		assert.Equal(t, "database unavailable", retried[0].Headers[HeaderError])
+		assert.Empty(t, store.letters)
+	})
+
+	t.Run("Message is dead-lettered once retries are exhausted", func(t *testing.T) {
+		bus := NewMemoryBus()
+		store := &memoryDeadLetterStore{}
+		handler := WithRetries(failing, policy, bus, store, slog.Default())
+
+		err := handler(context.Background(), Message{Topic: "orders.retry.2", Value: []byte("{}"), Headers: map[string]string{
+			HeaderRetryCount:        "2",
+			HeaderOriginalTopic:     "orders",
+			HeaderOriginalPartition: "2",
+			HeaderOriginalOffset:    "7",
+		}})
+		assert.NoError(t, err)
+
+		dead := bus.Published("orders.dlq")
+		assert.Len(t, dead, 1)
+		assert.Equal(t, ErrorTypeRetriesExhausted, dead[0].Headers[HeaderErrorType])
+		assert.Len(t, store.letters, 1)
+		assert.Equal(t, "orders", store.letters[0].OriginalTopic)
+		assert.Equal(t, int64(7), store.letters[0].OriginalOffset)
+		assert.Equal(t, 2, store.letters[0].RetryCount)
+	})
+
+	t.Run("Retried messages are held back until due", func(t *testing.T) {
+		bus := NewMemoryBus()
+		calls := 0
+		counting := func(ctx context.Context, msg Message) error { calls++; return nil }
+		handler := WithRetries(counting, policy, bus, &memoryDeadLetterStore{}, slog.Default())
+		due := time.Now().Add(time.Hour)
+
+		err := handler(context.Background(), Message{Topic: "orders.retry.1", Headers: map[string]string{
+			HeaderRetryCount: "1",
+			HeaderNotBefore:  strconv.FormatInt(due.UnixMilli(), 10),
+		}})
+		var notDue *NotDueError
+		assert.ErrorAs(t, err, &notDue)
+		assert.Equal(t, due.UnixMilli(), notDue.Until.UnixMilli())
+		assert.Zero(t, calls)
+
+		err = handler(context.Background(), Message{Topic: "orders.retry.1", Headers: map[string]string{
+			HeaderRetryCount: "1",
+			HeaderNotBefore:  strconv.FormatInt(time.Now().Add(-time.Second).UnixMilli(), 10),
+		}})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Permanent errors skip the retry chain", func(t *testing.T) {
+		bus := NewMemoryBus()
+		store := &memoryDeadLetterStore{}
+		poison := func(ctx context.Context, msg Message) error { return Permanent(errors.New("invalid payload")) }
+		handler := WithRetries(poison, policy, bus, store, slog.Default())
+
+		assert.NoError(t, handler(context.Background(), Message{Topic: "orders", Value: []byte("not json")}))
+		assert.Empty(t, bus.Published("orders.retry.1"))
+		assert.Len(t, bus.Published("orders.dlq"), 1)
+		assert.Equal(t, ErrorTypePermanent, store.letters[0].ErrorType)
+	})
+
+	t.Run("A redelivered dead letter is stored and published once", func(t *testing.T) {
+		bus := &unavailableBus{MemoryBus: NewMemoryBus(), down: true}
+		store := &memoryDeadLetterStore{}
+		poison := func(ctx context.Context, msg Message) error { return Permanent(errors.New("invalid payload")) }
+		handler := WithRetries(poison, policy, bus, store, slog.Default())
+		msg := Message{Topic: "orders", Partition: 2, Offset: 7, Value: []byte("not json")}
+
+		assert.Error(t, handler(context.Background(), msg), "the message is redelivered when the dead-letter topic is down")
+		assert.Len(t, store.letters, 1)
+		assert.Equal(t, []bool{false}, store.published)
+
+		bus.down = false
+		for range 2 {
+			assert.NoError(t, handler(context.Background(), msg))
+		}
+		assert.Len(t, store.letters, 1)
+		assert.Equal(t, []bool{true}, store.published)
+		assert.Len(t, bus.Published("orders.dlq"), 1)
+	})
+}
@@ -0,0 +1,66 @@
+package inbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProcessedEvent records that a consumer applied the side effects of an event. It is written in
+// the same transaction as those side effects, so a redelivered event is recognised and skipped
+// if and only if its effects were committed.
+type ProcessedEvent struct {
+	Consumer    string    `json:"consumer" gorm:"column:consumer;primaryKey;size:100"`
+	EventID     string    `json:"event_id" gorm:"column:event_id;primaryKey;size:255"`
+	Topic       string    `json:"topic" gorm:"column:topic;not null;size:255"`
+	Partition   int32     `json:"partition" gorm:"column:partition;not null"`
+	Offset      int64     `json:"offset" gorm:"column:offset;not null"`
+	ProcessedAt time.Time `json:"processed_at" gorm:"column:processed_at;not null;index:idx_processed_events_processed_at"`
+}
+
+func (ProcessedEvent) TableName() string {
+	return "processed_events"
+}
+
+// EventID identifies msg for deduplication: its CloudEvents ID, which survives retry topics and
+// DLQ replays, or its position in the log for messages without one.
+func EventID(msg events.Message) string {
+	if id := msg.Headers[events.HeaderEventID]; id != "" {
+		return id
+	}
+	return fmt.Sprintf("%s/%d/%d", msg.Topic, msg.Partition, msg.Offset)
+}
+
+// MarkProcessed records msg as processed by consumer within tx and reports whether it was
+// seen for the first time. A concurrent transaction recording the same event blocks until
+// the other one finishes, so at most one of them applies the side effects.
+func MarkProcessed(tx *gorm.DB, consumer string, msg events.Message) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&ProcessedEvent{
+		Consumer:    consumer,
+		EventID:     EventID(msg),
+		Topic:       msg.Topic,
+		Partition:   msg.Partition,
+		Offset:      msg.Offset,
+		ProcessedAt: time.Now(),
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Process runs fn in a transaction unless consumer already processed msg, making fn's
+// database side effects exactly-once even though delivery is at-least-once.
+func Process(ctx context.Context, db *gorm.DB, consumer string, msg events.Message, fn func(tx *gorm.DB) error) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		first, err := MarkProcessed(tx, consumer, msg)
+		if err != nil || !first {
+			return err
+		}
+		return fn(tx)
+	})
+}
@@ -0,0 +1,190 @@
+package inbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// processedEvents is an in-memory processed_events table behind a database/sql driver. It understands the
+// statements this package sends: inserts that skip conflicting keys, and deletes of rows processed before
+// a time. Inserts made in a transaction only become visible to other connections on commit.
+type processedEvents struct {
+	mu   sync.Mutex
+	rows map[string]time.Time
+}
+
+func (t *processedEvents) Connect(ctx context.Context) (driver.Conn, error) {
+	return &processedEventsConn{table: t}, nil
+}
+
+func (t *processedEvents) Driver() driver.Driver {
+	return nil
+}
+
+// add stores a row processed at processedAt, as if an earlier transaction had committed it.
+func (t *processedEvents) add(consumer, eventID string, processedAt time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rows[consumer+"|"+eventID] = processedAt
+}
+
+func (t *processedEvents) has(consumer, eventID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.rows[consumer+"|"+eventID]
+	return ok
+}
+
+type processedEventsConn struct {
+	table   *processedEvents
+	pending map[string]time.Time
+}
+
+func (c *processedEventsConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.table.mu.Lock()
+	defer c.table.mu.Unlock()
+	switch {
+	case strings.HasPrefix(query, `INSERT INTO "processed_events"`):
+		values := map[string]driver.Value{}
+		columns := strings.Split(query[strings.Index(query, "(")+1:strings.Index(query, ")")], ",")
+		for i, column := range columns {
+			values[strings.Trim(column, `" `)] = args[i].Value
+		}
+		key := fmt.Sprintf("%v|%v", values["consumer"], values["event_id"])
+		_, committed := c.table.rows[key]
+		_, staged := c.pending[key]
+		if committed || staged {
+			return driver.RowsAffected(0), nil
+		}
+		if c.pending != nil {
+			c.pending[key] = values["processed_at"].(time.Time)
+		} else {
+			c.table.rows[key] = values["processed_at"].(time.Time)
+		}
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(query, `DELETE FROM "processed_events" WHERE processed_at < $1`):
+		cutoff := args[0].Value.(time.Time)
+		var deleted int64
+		for key, processedAt := range c.table.rows {
+			if processedAt.Before(cutoff) {
+				delete(c.table.rows, key)
+				deleted++
+			}
+		}
+		return driver.RowsAffected(deleted), nil
+	}
+	return nil, fmt.Errorf("unexpected statement %q", query)
+}
+
+func (c *processedEventsConn) Begin() (driver.Tx, error) {
+	c.pending = map[string]time.Time{}
+	return c, nil
+}
+
+func (c *processedEventsConn) Commit() error {
+	c.table.mu.Lock()
+	defer c.table.mu.Unlock()
+	for key, processedAt := range c.pending {
+		c.table.rows[key] = processedAt
+	}
+	c.pending = nil
+	return nil
+}
+
+func (c *processedEventsConn) Rollback() error {
+	c.pending = nil
+	return nil
+}
+
+func (c *processedEventsConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *processedEventsConn) Close() error {
+	return nil
+}
+
+// newProcessedEventsDB opens gorm on an empty in-memory processed_events table.
+func newProcessedEventsDB(t *testing.T) (*gorm.DB, *processedEvents) {
+	table := &processedEvents{rows: map[string]time.Time{}}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(table)}), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	return db, table
+}
+
+func TestMarkProcessed(t *testing.T) {
+
+	msg := events.Message{Topic: "orders", Partition: 1, Offset: 42, Headers: map[string]string{events.HeaderEventID: "event-1"}}
+
+	t.Run("Events are processed the first time only", func(t *testing.T) {
+		db, table := newProcessedEventsDB(t)
+
+		first, err := MarkProcessed(db, "payments", msg)
+		require.NoError(t, err)
+		assert.True(t, first)
+		assert.True(t, table.has("payments", "event-1"))
+
+		redelivered := msg
+		redelivered.Topic, redelivered.Offset = "orders.retry.1", 3
+		first, err = MarkProcessed(db, "payments", redelivered)
+		require.NoError(t, err)
+		assert.False(t, first, "the event ID survives retry topics")
+	})
+
+	t.Run("Consumers deduplicate independently", func(t *testing.T) {
+		db, _ := newProcessedEventsDB(t)
+		_, err := MarkProcessed(db, "payments", msg)
+		require.NoError(t, err)
+
+		first, err := MarkProcessed(db, "emails", msg)
+		require.NoError(t, err)
+		assert.True(t, first)
+	})
+
+	t.Run("Messages without an event ID are identified by their position", func(t *testing.T) {
+		assert.Equal(t, "orders/1/42", EventID(events.Message{Topic: "orders", Partition: 1, Offset: 42}))
+	})
+}
+
+func TestProcess(t *testing.T) {
+
+	msg := events.Message{Topic: "orders", Headers: map[string]string{events.HeaderEventID: "event-1"}}
+
+	t.Run("Duplicates skip the handler", func(t *testing.T) {
+		db, _ := newProcessedEventsDB(t)
+		calls := 0
+		handler := func(tx *gorm.DB) error { calls++; return nil }
+
+		require.NoError(t, Process(context.Background(), db, "payments", msg, handler))
+		require.NoError(t, Process(context.Background(), db, "payments", msg, handler))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Handler errors roll back the marker", func(t *testing.T) {
+		db, table := newProcessedEventsDB(t)
+		failed := errors.New("payment declined")
+
+		err := Process(context.Background(), db, "payments", msg, func(tx *gorm.DB) error { return failed })
+		assert.ErrorIs(t, err, failed)
+		assert.False(t, table.has("payments", "event-1"))
+
+		calls := 0
+		require.NoError(t, Process(context.Background(), db, "payments", msg, func(tx *gorm.DB) error { calls++; return nil }))
+		assert.Equal(t, 1, calls, "the redelivered event is processed again")
+		assert.True(t, table.has("payments", "event-1"))
+	})
+}
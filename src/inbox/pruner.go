@@ -0,0 +1,65 @@
+package inbox
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Pruner periodically deletes processed events older than the retention window. The window must
+// exceed the longest time an event can be redelivered, including retry topics and DLQ replays.
+type Pruner struct {
+	db        *gorm.DB
+	logger    *slog.Logger
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewPruner(db *gorm.DB, logger *slog.Logger) *Pruner {
+	return &Pruner{
+		db:        db,
+		logger:    logger.With("component", "ProcessedEventsPruner"),
+		retention: envDuration("PROCESSED_EVENTS_RETENTION", 7*24*time.Hour),
+		interval:  envDuration("PROCESSED_EVENTS_PRUNE_INTERVAL", time.Hour),
+	}
+}
+
+// Start prunes on every interval until ctx is cancelled.
+func (p *Pruner) Start(ctx context.Context) {
+	p.logger.Info("Processed events pruner started", "retention", p.retention, "interval", p.interval)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("Processed events pruner stopped")
+			return
+		case <-ticker.C:
+			if _, err := p.Prune(ctx); err != nil {
+				p.logger.Error("Failed to prune processed events", "error", err)
+			}
+		}
+	}
+}
+
+// Prune deletes processed events past the retention window and returns how many were removed.
+func (p *Pruner) Prune(ctx context.Context) (int64, error) {
+	result := p.db.WithContext(ctx).Where("processed_at < ?", time.Now().Add(-p.retention)).Delete(&ProcessedEvent{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected > 0 {
+		p.logger.Info("Pruned processed events", "count", result.RowsAffected)
+	}
+	return result.RowsAffected, nil
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
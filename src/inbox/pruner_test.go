@@ -0,0 +1,29 @@
+package inbox
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrune(t *testing.T) {
+
+	t.Run("Only events past the retention window are deleted", func(t *testing.T) {
+		db, table := newProcessedEventsDB(t)
+		table.add("payments", "expired", time.Now().Add(-25*time.Hour))
+		table.add("payments", "recent", time.Now().Add(-23*time.Hour))
+		table.add("emails", "fresh", time.Now())
+		pruner := &Pruner{db: db, logger: slog.Default(), retention: 24 * time.Hour}
+
+		pruned, err := pruner.Prune(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), pruned)
+		assert.False(t, table.has("payments", "expired"))
+		assert.True(t, table.has("payments", "recent"))
+		assert.True(t, table.has("emails", "fresh"))
+	})
+}
@@ -1,32 +1,107 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/swagger"
+	adminRepository "github.com/svadikari/golang_fiber_orders/src/admin/repository"
+	adminRouters "github.com/svadikari/golang_fiber_orders/src/admin/routers"
+	cartRepository "github.com/svadikari/golang_fiber_orders/src/carts/repository"
+	cartRouters "github.com/svadikari/golang_fiber_orders/src/carts/routers"
+	cartServices "github.com/svadikari/golang_fiber_orders/src/carts/services"
+	"github.com/svadikari/golang_fiber_orders/src/currency"
 	"github.com/svadikari/golang_fiber_orders/src/database"
 	_ "github.com/svadikari/golang_fiber_orders/src/docs"
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"github.com/svadikari/golang_fiber_orders/src/inbox"
 	"github.com/svadikari/golang_fiber_orders/src/middleware"
+	"github.com/svadikari/golang_fiber_orders/src/migrations"
 	orderRouters "github.com/svadikari/golang_fiber_orders/src/orders/routers"
+	"github.com/svadikari/golang_fiber_orders/src/outbox"
 	productRouters "github.com/svadikari/golang_fiber_orders/src/products/routers"
+	promotionRouters "github.com/svadikari/golang_fiber_orders/src/promotions/routers"
+	"github.com/svadikari/golang_fiber_orders/src/serialization"
 	"gorm.io/gorm"
 )
 
 func main() {
-	// This is just a placeholder to avoid "no main function" error.
-	database.ConnectDB()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrations.RunCLI(os.Args[2:], database.Open, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := database.ConnectDB(); err != nil {
+		panic(err)
+	}
 	db := database.Database.Db
 	if db.Error != nil {
 		panic("Failed to connect to database!")
 	}
-	app := initApp(db)
 
-	if err := app.Listen(":3000"); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	publisher, subscriber, err := middleware.NewEventBus(slog.Default())
+	if err != nil {
+		panic(err)
+	}
+	serializer, err := middleware.NewEventSerializer()
+	if err != nil {
 		panic(err)
 	}
+	registry := events.NewRegistry(slog.Default())
+	orderRouters.InitEventHandlers(registry, db, serializer)
+	retryPolicy := middleware.RetryPolicyFromEnv()
+	deadLetters := adminRepository.NewDeadLetterRepository(db)
+	middleware.ConfigureOrderConsumer(ctx, subscriber, retryPolicy.Topics(),
+		events.WithRetries(registry.Handle, retryPolicy, publisher, deadLetters, slog.Default()))
+	if middleware.AutoStartConsumer() {
+		if err := middleware.StartKafkaConsumer(); err != nil {
+			panic(err)
+		}
+	}
+	app := initApp(db, publisher, serializer)
+
+	// Relay order events written to the outbox table to Kafka
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		outbox.NewRelay(db, publisher, slog.Default()).Start(ctx)
+	}()
+	go inbox.NewPruner(db, slog.Default()).Start(ctx)
+	go middleware.PruneIdempotencyKeys(ctx, db, time.Hour)
+	go currency.NewRefresher(db, currency.SourceFromEnv(), slog.Default()).Start(ctx)
+	go cartServices.NewPruner(cartRepository.NewCartRepository(db), slog.Default()).Start(ctx)
+
+	go func() {
+		<-ctx.Done()
+		slog.Info("Shutting down server")
+		if err := app.ShutdownWithTimeout(10 * time.Second); err != nil {
+			slog.Error("Failed to shut down server", "error", err)
+		}
+	}()
+
 	slog.Info("Server is running on port 3000")
+	if err := app.Listen(":3000"); err != nil {
+		panic(err)
+	}
+
+	stop()
+	// Let the consumer leave its group before the publisher it forwards retries to goes away
+	middleware.StopKafkaConsumer()
+	<-relayDone
+	publisher.Close()
 }
 
 //@title Order, Products API
@@ -42,7 +117,7 @@ func main() {
 
 // @host		localhost:3000
 // @BasePath	/
-func initApp(db *gorm.DB) *fiber.App {
+func initApp(db *gorm.DB, publisher events.EventPublisher, serializer *serialization.EventSerializer) *fiber.App {
 	app := fiber.New(fiber.Config{
 		AppName: "Orders API",
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
@@ -56,7 +131,7 @@ func initApp(db *gorm.DB) *fiber.App {
 				message = e.Message
 			}
 
-			return c.Status(fiber.StatusBadRequest).JSON(middleware.GlobalErrorHandlerResp{
+			return c.Status(statusCode).JSON(middleware.GlobalErrorHandlerResp{
 				Code:   statusCode,
 				Errors: strings.Split(message, ","),
 			})
@@ -75,7 +150,10 @@ func initApp(db *gorm.DB) *fiber.App {
 	})
 
 	productRouters.Init(app, db)
-	orderRouters.Init(app)
+	orderRouters.Init(app, db, publisher, serializer)
+	promotionRouters.Init(app, db)
+	cartRouters.Init(app, db, orderRouters.NewOrderService(db, serializer))
+	adminRouters.Init(app, db, publisher)
 
 	return app
 }
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"github.com/svadikari/golang_fiber_orders/src/schemaregistry"
+	"github.com/svadikari/golang_fiber_orders/src/serialization"
+)
+
+const (
+	EventBusKafka  = "kafka"
+	EventBusMemory = "memory"
+)
+
+// OrdersTopic returns the topic order events are published to.
+func OrdersTopic() string {
+	return getEnv("KAFKA_TOPIC", "orders")
+}
+
+// NewEventBus builds the publisher and subscriber selected by EVENT_BUS ("kafka" or "memory").
+// The memory bus needs neither a broker nor cgo and is meant for local runs and tests.
+func NewEventBus(logger *slog.Logger) (events.EventPublisher, events.EventSubscriber, error) {
+	switch kind := getEnv("EVENT_BUS", EventBusKafka); kind {
+	case EventBusMemory:
+		bus := events.NewMemoryBus()
+		logger.Info("Using in-memory event bus")
+		return bus, bus, nil
+	case EventBusKafka:
+		producer, err := NewKafkaProducer(ProducerConfigFromEnv(), logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		return producer, NewKafkaSubscriber(ConsumerConfigFromEnv(), logger), nil
+	default:
+		return nil, nil, fmt.Errorf("unknown EVENT_BUS %q", kind)
+	}
+}
+
+// NewEventSerializer builds the serializer selected by EVENT_SERIALIZATION_FORMAT ("json", "protobuf" or "avro").
+// Protobuf and Avro schemas are registered with the schema registry at SCHEMA_REGISTRY_URL.
+func NewEventSerializer() (*serialization.EventSerializer, error) {
+	var registry schemaregistry.Registry
+	if url := os.Getenv("SCHEMA_REGISTRY_URL"); url != "" {
+		registry = schemaregistry.NewClient(url)
+	}
+	return serialization.NewEventSerializer(getEnv("EVENT_SERIALIZATION_FORMAT", serialization.FormatJSON), registry)
+}
+
+type ProducerConfig struct {
+	Broker            string
+	Acks              string
+	EnableIdempotence bool
+	LingerMs          int
+	BatchNumMessages  int
+	CompressionType   string
+	MessageTimeoutMs  int
+	FlushTimeout      time.Duration
+}
+
+// ProducerConfigFromEnv reads the producer settings, defaulting to durable, idempotent delivery.
+func ProducerConfigFromEnv() ProducerConfig {
+	cfg := ProducerConfig{
+		Broker:            getEnv("KAFKA_BROKER", "localhost:9092"),
+		Acks:              getEnv("KAFKA_ACKS", "all"),
+		EnableIdempotence: getEnv("KAFKA_ENABLE_IDEMPOTENCE", "true") == "true",
+		LingerMs:          getEnvInt("KAFKA_LINGER_MS", 5),
+		BatchNumMessages:  getEnvInt("KAFKA_BATCH_NUM_MESSAGES", 10000),
+		CompressionType:   getEnv("KAFKA_COMPRESSION_TYPE", "none"),
+		MessageTimeoutMs:  getEnvInt("KAFKA_MESSAGE_TIMEOUT_MS", 30000),
+		FlushTimeout:      15 * time.Second,
+	}
+	if flushTimeout, err := time.ParseDuration(os.Getenv("KAFKA_FLUSH_TIMEOUT")); err == nil {
+		cfg.FlushTimeout = flushTimeout
+	}
+	return cfg
+}
+
+// RetryPolicyFromEnv configures the retry topic chain and dead-letter topic of the order consumer.
+func RetryPolicyFromEnv() events.RetryPolicy {
+	topic := OrdersTopic()
+	policy := events.RetryPolicy{
+		Topic:           topic,
+		MaxRetries:      getEnvInt("KAFKA_MAX_RETRIES", 3),
+		Backoff:         5 * time.Second,
+		DeadLetterTopic: getEnv("KAFKA_DLQ_TOPIC", topic+".dlq"),
+	}
+	if backoff, err := time.ParseDuration(os.Getenv("KAFKA_RETRY_BACKOFF")); err == nil {
+		policy.Backoff = backoff
+	}
+	return policy
+}
+
+type ConsumerConfig struct {
+	Broker        string
+	ConsumerGroup string
+}
+
+func ConsumerConfigFromEnv() ConsumerConfig {
+	return ConsumerConfig{
+		Broker:        getEnv("KAFKA_BROKER", "localhost:9092"),
+		ConsumerGroup: getEnv("KAFKA_CONSUMER_GROUP", "order-consumer-group"),
+	}
+}
+
+func getEnv(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(name string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil {
+		return value
+	}
+	return fallback
+}
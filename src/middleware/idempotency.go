@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotencyReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+)
+
+// IdempotencyKey stores the outcome of a request made with an Idempotency-Key header.
+// StatusCode is zero while the first request with the key is still being processed. That request
+// holds the key until LockedUntil; a key still in progress after it is taken over by the next
+// request, so a crash or a response that could not be stored does not block the key until it expires.
+type IdempotencyKey struct {
+	Key          string    `json:"key" gorm:"column:key;primaryKey;size:255"`
+	RequestHash  string    `json:"request_hash" gorm:"column:request_hash;not null;size:64"`
+	StatusCode   int       `json:"status_code" gorm:"column:status_code;not null;default:0"`
+	ContentType  string    `json:"content_type" gorm:"column:content_type;size:100"`
+	ResponseBody []byte    `json:"-" gorm:"column:response_body;type:bytea"`
+	CreatedAt    time.Time `json:"created_at" gorm:"column:created_at;not null"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"column:expires_at;not null;index:idx_idempotency_keys_expires_at"`
+	LockedUntil  time.Time `json:"locked_until" gorm:"column:locked_until;not null"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
+
+// IdempotencyKeyTTL is how long a key is remembered, configured by IDEMPOTENCY_KEY_TTL.
+func IdempotencyKeyTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_KEY_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 24 * time.Hour
+}
+
+// IdempotencyKeyLease is how long a request may keep its key in progress before a retry takes it
+// over, configured by IDEMPOTENCY_KEY_LEASE.
+func IdempotencyKeyLease() time.Duration {
+	if lease, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_KEY_LEASE")); err == nil && lease > 0 {
+		return lease
+	}
+	return time.Minute
+}
+
+// Idempotency makes retries of a request carrying an Idempotency-Key header safe: the first
+// successful response is stored and replayed to every retry with the same key and body, a key
+// reused with a different body is rejected with 422, and a retry racing the first request gets
+// 409. Failed requests have no effect, so their key is released and may be retried.
+func Idempotency(c *fiber.Ctx) error {
+	key := c.Get(HeaderIdempotencyKey)
+	if key == "" {
+		return c.Next()
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return fiber.NewError(fiber.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+	}
+	log := c.Locals("logger").(*slog.Logger)
+	db := c.Locals("db").(*gorm.DB).WithContext(c.UserContext())
+
+	requestHash := hashRequest(c)
+	record, claimed, err := claimIdempotencyKey(db, key, requestHash)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		switch {
+		case record.RequestHash != requestHash:
+			return fiber.NewError(fiber.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+		case record.StatusCode == 0:
+			return fiber.NewError(fiber.StatusConflict, "A request with this Idempotency-Key is still being processed")
+		}
+		log.Info("Replaying stored response", "idempotencyKey", key, "status", record.StatusCode)
+		c.Set(HeaderIdempotencyReplayed, "true")
+		c.Set(fiber.HeaderContentType, record.ContentType)
+		return c.Status(record.StatusCode).Send(record.ResponseBody)
+	}
+
+	// Both statements below only touch the key while this request still holds it, in case its lease
+	// expired and a retry took the key over.
+	err = c.Next()
+	status := c.Response().StatusCode()
+	if err != nil || status < fiber.StatusOK || status >= fiber.StatusMultipleChoices {
+		if releaseErr := db.Delete(&IdempotencyKey{}, "key = ? AND locked_until = ?", key, record.LockedUntil).Error; releaseErr != nil {
+			log.Error("Failed to release idempotency key", "idempotencyKey", key, "error", releaseErr)
+		}
+		return err
+	}
+	err = db.Model(&IdempotencyKey{}).Where("key = ? AND locked_until = ?", key, record.LockedUntil).Updates(map[string]any{
+		"status_code":   status,
+		"content_type":  string(c.Response().Header.ContentType()),
+		"response_body": append([]byte(nil), c.Response().Body()...),
+	}).Error
+	if err != nil {
+		// The request succeeded; a retry sees the key in progress until the lease expires and then runs again.
+		log.Error("Failed to store idempotent response", "idempotencyKey", key, "error", err)
+	}
+	return nil
+}
+
+// claimIdempotencyKey inserts key unless an unexpired record exists, which is returned instead.
+// An expired record, or one left in progress past its lease, is replaced.
+func claimIdempotencyKey(db *gorm.DB, key, requestHash string) (IdempotencyKey, bool, error) {
+	// The lease identifies the claim, so it is kept at the precision the database stores.
+	now := time.Now().Truncate(time.Microsecond)
+	record := IdempotencyKey{Key: key, RequestHash: requestHash, CreatedAt: now, ExpiresAt: now.Add(IdempotencyKeyTTL()), LockedUntil: now.Add(IdempotencyKeyLease())}
+	err := db.Where("key = ? AND (expires_at <= ? OR (status_code = 0 AND locked_until <= ?))", key, now, now).Delete(&IdempotencyKey{}).Error
+	if err != nil {
+		return record, false, err
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+	if result.Error != nil {
+		return record, false, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return record, true, nil
+	}
+
+	var existing IdempotencyKey
+	err = db.First(&existing, "key = ?", key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Released by a failed request in the meantime.
+		return claimIdempotencyKey(db, key, requestHash)
+	}
+	return existing, false, err
+}
+
+func hashRequest(c *fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Method() + " " + c.Path() + "\n"))
+	hash.Write(c.Body())
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// PruneIdempotencyKeys deletes expired keys every interval until ctx is cancelled.
+func PruneIdempotencyKeys(ctx context.Context, db *gorm.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result := db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&IdempotencyKey{})
+			if result.Error != nil {
+				slog.Error("Failed to prune idempotency keys", "error", result.Error)
+			} else if result.RowsAffected > 0 {
+				slog.Info("Pruned expired idempotency keys", "count", result.RowsAffected)
+			}
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var idempotencyColumns = []string{"key", "request_hash", "status_code", "content_type", "response_body", "created_at", "expires_at", "locked_until"}
+
+// idempotencyKeys is an in-memory idempotency_keys table behind a database/sql driver. It understands the
+// statements the Idempotency middleware sends and applies them immediately, transactions included.
+type idempotencyKeys struct {
+	mu   sync.Mutex
+	rows map[string]map[string]driver.Value
+}
+
+func (t *idempotencyKeys) Connect(ctx context.Context) (driver.Conn, error) {
+	return &idempotencyKeysConn{table: t}, nil
+}
+
+func (t *idempotencyKeys) Driver() driver.Driver {
+	return nil
+}
+
+func (t *idempotencyKeys) has(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, ok := t.rows[key]
+	return ok
+}
+
+// heldBy reports whether key is still held by the claim with the lease lockedUntil. The caller holds mu.
+func (t *idempotencyKeys) heldBy(key string, lockedUntil time.Time) bool {
+	row, ok := t.rows[key]
+	return ok && row["locked_until"].(time.Time).Equal(lockedUntil)
+}
+
+// add stores key as claimed by an earlier request that is still in progress.
+func (t *idempotencyKeys) add(key, requestHash string, lockedUntil time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rows[key] = map[string]driver.Value{
+		"key": key, "request_hash": requestHash, "status_code": int64(0), "content_type": "", "response_body": []byte(nil),
+		"created_at": time.Now(), "expires_at": time.Now().Add(time.Hour), "locked_until": lockedUntil,
+	}
+}
+
+type idempotencyKeysConn struct {
+	table *idempotencyKeys
+}
+
+func (c *idempotencyKeysConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.table.mu.Lock()
+	defer c.table.mu.Unlock()
+	switch {
+	case strings.HasPrefix(query, `INSERT INTO "idempotency_keys"`):
+		row := map[string]driver.Value{}
+		columns := strings.Split(query[strings.Index(query, "(")+1:strings.Index(query, ")")], ",")
+		for i, column := range columns {
+			row[strings.Trim(column, `" `)] = args[i].Value
+		}
+		key := row["key"].(string)
+		if _, ok := c.table.rows[key]; ok {
+			return driver.RowsAffected(0), nil
+		}
+		c.table.rows[key] = row
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(query, `DELETE FROM "idempotency_keys" WHERE key = $1 AND (expires_at <= $2 OR (status_code = 0 AND locked_until <= $3))`):
+		row, ok := c.table.rows[args[0].Value.(string)]
+		if !ok {
+			return driver.RowsAffected(0), nil
+		}
+		expired := !row["expires_at"].(time.Time).After(args[1].Value.(time.Time))
+		abandoned := row["status_code"].(int64) == 0 && !row["locked_until"].(time.Time).After(args[2].Value.(time.Time))
+		if !expired && !abandoned {
+			return driver.RowsAffected(0), nil
+		}
+		delete(c.table.rows, args[0].Value.(string))
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(query, `DELETE FROM "idempotency_keys" WHERE key = $1 AND locked_until = $2`):
+		if !c.table.heldBy(args[0].Value.(string), args[1].Value.(time.Time)) {
+			return driver.RowsAffected(0), nil
+		}
+		delete(c.table.rows, args[0].Value.(string))
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(query, `UPDATE "idempotency_keys" SET`):
+		key, lockedUntil := args[len(args)-2].Value.(string), args[len(args)-1].Value.(time.Time)
+		if !c.table.heldBy(key, lockedUntil) {
+			return driver.RowsAffected(0), nil
+		}
+		row := c.table.rows[key]
+		assignments := strings.Split(query[len(`UPDATE "idempotency_keys" SET `):strings.Index(query, " WHERE")], ",")
+		for i, assignment := range assignments {
+			row[strings.Trim(strings.Split(assignment, "=")[0], `" `)] = args[i].Value
+		}
+		return driver.RowsAffected(1), nil
+	}
+	return nil, fmt.Errorf("unexpected statement %q", query)
+}
+
+func (c *idempotencyKeysConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !strings.HasPrefix(query, `SELECT * FROM "idempotency_keys" WHERE key = $1`) {
+		return nil, fmt.Errorf("unexpected query %q", query)
+	}
+	c.table.mu.Lock()
+	defer c.table.mu.Unlock()
+	rows := &idempotencyKeyRows{}
+	if row, ok := c.table.rows[args[0].Value.(string)]; ok {
+		for _, column := range idempotencyColumns {
+			rows.values = append(rows.values, row[column])
+		}
+	}
+	return rows, nil
+}
+
+func (c *idempotencyKeysConn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c *idempotencyKeysConn) Commit() error {
+	return nil
+}
+
+func (c *idempotencyKeysConn) Rollback() error {
+	return nil
+}
+
+func (c *idempotencyKeysConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *idempotencyKeysConn) Close() error {
+	return nil
+}
+
+// idempotencyKeyRows holds at most one row.
+type idempotencyKeyRows struct {
+	values []driver.Value
+	read   bool
+}
+
+func (r *idempotencyKeyRows) Columns() []string {
+	return idempotencyColumns
+}
+
+func (r *idempotencyKeyRows) Close() error {
+	return nil
+}
+
+func (r *idempotencyKeyRows) Next(dest []driver.Value) error {
+	if r.read || r.values == nil {
+		return io.EOF
+	}
+	r.read = true
+	copy(dest, r.values)
+	return nil
+}
+
+// newIdempotentApp serves POST /orders with handler behind the Idempotency middleware, storing keys in
+// an in-memory table.
+func newIdempotentApp(t *testing.T, handler fiber.Handler) (*fiber.App, *idempotencyKeys) {
+	table := &idempotencyKeys{rows: map[string]map[string]driver.Value{}}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(table)}), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("logger", slog.Default())
+		c.Locals("db", db)
+		return c.Next()
+	})
+	app.Post("/orders", Idempotency, handler)
+	return app, table
+}
+
+func postOrder(t *testing.T, app *fiber.App, key, body string) (*http.Response, string) {
+	req := httptest.NewRequest(fiber.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.Header.Set(HeaderIdempotencyKey, key)
+	resp, err := app.Test(req, -1)
+	require.NoError(t, err)
+	responseBody, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(responseBody)
+}
+
+func TestIdempotency(t *testing.T) {
+
+	t.Run("Retries with the same key and body get the stored response", func(t *testing.T) {
+		calls := 0
+		app, _ := newIdempotentApp(t, func(c *fiber.Ctx) error {
+			calls++
+			return c.Status(fiber.StatusCreated).JSON(fiber.Map{"id": calls})
+		})
+
+		resp, body := postOrder(t, app, "key-1", `{"user_id": 7}`)
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get(HeaderIdempotencyReplayed))
+
+		resp, replayed := postOrder(t, app, "key-1", `{"user_id": 7}`)
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "true", resp.Header.Get(HeaderIdempotencyReplayed))
+		assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get(fiber.HeaderContentType))
+		assert.Equal(t, body, replayed)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Keys reused with a different body are rejected", func(t *testing.T) {
+		app, _ := newIdempotentApp(t, func(c *fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusCreated)
+		})
+
+		resp, _ := postOrder(t, app, "key-1", `{"user_id": 7}`)
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		resp, _ = postOrder(t, app, "key-1", `{"user_id": 8}`)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+	})
+
+	t.Run("Retries racing the first request are rejected", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		app, _ := newIdempotentApp(t, func(c *fiber.Ctx) error {
+			close(started)
+			<-release
+			return c.SendStatus(fiber.StatusCreated)
+		})
+
+		first := make(chan int)
+		go func() {
+			resp, _ := postOrder(t, app, "key-1", `{"user_id": 7}`)
+			first <- resp.StatusCode
+		}()
+		<-started
+		resp, _ := postOrder(t, app, "key-1", `{"user_id": 7}`)
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode)
+
+		close(release)
+		assert.Equal(t, fiber.StatusCreated, <-first)
+	})
+
+	t.Run("Keys of failed requests are released", func(t *testing.T) {
+		calls := 0
+		app, table := newIdempotentApp(t, func(c *fiber.Ctx) error {
+			calls++
+			if calls == 1 {
+				return fiber.NewError(fiber.StatusServiceUnavailable, "payment service unavailable")
+			}
+			return c.SendStatus(fiber.StatusCreated)
+		})
+
+		resp, _ := postOrder(t, app, "key-1", `{"user_id": 7}`)
+		assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+		assert.False(t, table.has("key-1"))
+
+		resp, _ = postOrder(t, app, "key-1", `{"user_id": 7}`)
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get(HeaderIdempotencyReplayed))
+		assert.Equal(t, 2, calls)
+	})
+	t.Run("Keys left in progress are taken over once their lease expires", func(t *testing.T) {
+		calls := 0
+		app, table := newIdempotentApp(t, func(c *fiber.Ctx) error {
+			calls++
+			return c.SendStatus(fiber.StatusCreated)
+		})
+		body := `{"user_id": 7}`
+		sum := sha256.Sum256([]byte("POST /orders\n" + body))
+		table.add("key-1", hex.EncodeToString(sum[:]), time.Now().Add(time.Minute))
+		table.add("key-2", hex.EncodeToString(sum[:]), time.Now().Add(-time.Second))
+
+		resp, _ := postOrder(t, app, "key-1", body)
+		assert.Equal(t, fiber.StatusConflict, resp.StatusCode, "the lease of key-1 still runs")
+
+		resp, _ = postOrder(t, app, "key-2", body)
+		assert.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		assert.Empty(t, resp.Header.Get(HeaderIdempotencyReplayed))
+		assert.Equal(t, 1, calls)
+
+		resp, _ = postOrder(t, app, "key-2", body)
+		assert.Equal(t, "true", resp.Header.Get(HeaderIdempotencyReplayed), "the response of the takeover is stored")
+	})
+}
@@ -1,88 +1,193 @@
+//go:build cgo
+
 package middleware
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"log/slog"
-	"os"
+	"sync"
+	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
-	"github.com/svadikari/golang_fiber_orders/src/orders/models"
+	"github.com/svadikari/golang_fiber_orders/src/events"
 )
 
-type consumeOrders struct {
-	run bool
-}
+var (
+	_ events.EventSubscriber = (*KafkaSubscriber)(nil)
+	_ events.StatusReporter  = (*KafkaSubscriber)(nil)
+)
 
-var consumerInstance *consumeOrders
+// redeliveryDelay is how long a partition pauses after a handler failure before the message is retried.
+const redeliveryDelay = time.Second
 
-func init() {
-	consumerInstance = &consumeOrders{run: false}
+// statusTimeoutMs bounds the broker round trips made while building a status report.
+const statusTimeoutMs = 2000
 
+// KafkaSubscriber consumes topics with a consumer group and hands every message to a handler.
+type KafkaSubscriber struct {
+	cfg       ConsumerConfig
+	logger    *slog.Logger
+	mu        sync.Mutex
+	consumer  *kafka.Consumer
+	lastError string
 }
 
-func StartKafkaConsumer() {
-	if !consumerInstance.run {
-		consumerInstance.run = true
-		go consumerInstance.ConsumeOrders()
-	} else {
-		slog.Warn("Kafka consumer is already running")
-	}
+func NewKafkaSubscriber(cfg ConsumerConfig, logger *slog.Logger) *KafkaSubscriber {
+	return &KafkaSubscriber{cfg: cfg, logger: logger.With("component", "KafkaSubscriber")}
 }
 
-func StopKafkaConsumer() {
-	if consumerInstance.run {
-		consumerInstance.run = false
-		slog.Info("Kafka consumer stopped")
-	} else {
-		slog.Warn("Kafka consumer is not running")
+func (s *KafkaSubscriber) Subscribe(ctx context.Context, topics []string, handler events.Handler) error {
+	c, err := kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers":  s.cfg.Broker,
+		"group.id":           s.cfg.ConsumerGroup,
+		"auto.offset.reset":  "earliest",
+		"enable.auto.commit": false,
+	})
+	if err != nil {
+		s.logger.Error("Failed to create Kafka consumer", "error", err)
+		s.recordError(err)
+		return err
+	}
+	defer s.close(c)
+
+	if err := c.SubscribeTopics(topics, nil); err != nil {
+		s.logger.Error("Failed to subscribe to Kafka topic", "error", err)
+		s.recordError(err)
+		return err
 	}
+	s.mu.Lock()
+	s.consumer = c
+	s.mu.Unlock()
+	s.logger.Info("Kafka consumer started, listening to topics", "topics", topics)
+
+	held := map[heldPartition]time.Time{}
+	for ctx.Err() == nil {
+		s.resumeDue(c, held)
+		msg, err := c.ReadMessage(pollTimeout)
+		if err != nil {
+			if kafkaErr, ok := err.(kafka.Error); ok && kafkaErr.Code() == kafka.ErrTimedOut {
+				continue
+			}
+			// The client will automatically try to recover from all errors.
+			s.logger.Error("Consumer error", "error", err)
+			s.recordError(err)
+			continue
+		}
+		if err := handler(ctx, events.Message{
+			Topic:     *msg.TopicPartition.Topic,
+			Key:       msg.Key,
+			Value:     msg.Value,
+			Headers:   fromKafkaHeaders(msg.Headers),
+			Partition: msg.TopicPartition.Partition,
+			Offset:    int64(msg.TopicPartition.Offset),
+		}); err != nil {
+			var notDue *events.NotDueError
+			if errors.As(err, &notDue) {
+				s.holdBack(c, held, msg.TopicPartition, notDue.Until)
+				continue
+			}
+			s.logger.Error("Failed to handle message, will redeliver", "topic", *msg.TopicPartition.Topic, "offset", msg.TopicPartition.Offset, "error", err)
+			s.recordError(err)
+			s.holdBack(c, held, msg.TopicPartition, time.Now().Add(redeliveryDelay))
+			continue
+		}
+		// Offsets are only committed once the handler succeeded.
+		if _, err := c.CommitMessage(msg); err != nil {
+			s.logger.Error("Failed to commit offset", "partition", msg.TopicPartition, "error", err)
+			s.recordError(err)
+		}
+	}
+	return nil
 }
 
-func (con *consumeOrders) ConsumeOrders() {
-	broker := os.Getenv("KAFKA_BROKER")
-	topic := os.Getenv("KAFKA_TOPIC")
-	consumerGroup := os.Getenv("KAFKA_CONSUMER_GROUP")
+type heldPartition struct {
+	topic     string
+	partition int32
+}
 
-	if broker == "" {
-		broker = "localhost:9092"
+// holdBack rewinds the partition to the message so it is read again instead of being skipped by the next
+// poll, and pauses the partition until the given time while the other partitions keep being consumed.
+func (s *KafkaSubscriber) holdBack(c *kafka.Consumer, held map[heldPartition]time.Time, tp kafka.TopicPartition, until time.Time) {
+	if err := c.Seek(tp, 0); err != nil {
+		s.logger.Error("Failed to rewind partition", "partition", tp, "error", err)
 	}
-	if topic == "" {
-		topic = "orders"
+	if err := c.Pause([]kafka.TopicPartition{{Topic: tp.Topic, Partition: tp.Partition}}); err != nil {
+		s.logger.Error("Failed to pause partition", "partition", tp, "error", err)
+		return
 	}
+	held[heldPartition{topic: *tp.Topic, partition: tp.Partition}] = until
+}
 
-	if consumerGroup == "" {
-		consumerGroup = "order-consumer-group"
+// resumeDue resumes the held partitions whose time has come. A partition revoked by a rebalance in the
+// meantime is no longer paused, and its message is redelivered from the committed offset by its new owner.
+func (s *KafkaSubscriber) resumeDue(c *kafka.Consumer, held map[heldPartition]time.Time) {
+	now := time.Now()
+	for key, until := range held {
+		if now.Before(until) {
+			continue
+		}
+		delete(held, key)
+		if err := c.Resume([]kafka.TopicPartition{{Topic: &key.topic, Partition: key.partition}}); err != nil {
+			s.logger.Warn("Failed to resume partition", "topic", key.topic, "partition", key.partition, "error", err)
+		}
 	}
+}
 
-	p, err := kafka.NewConsumer(&kafka.ConfigMap{
-		"bootstrap.servers": broker,
-		"group.id":          consumerGroup,
-		"auto.offset.reset": "earliest",
-	})
-	if err != nil {
-		slog.Error("Failed to create Kafka consumer", "error", err)
+// close leaves the consumer group cleanly so partitions are reassigned without waiting for a session timeout.
+func (s *KafkaSubscriber) close(c *kafka.Consumer) {
+	s.mu.Lock()
+	s.consumer = nil
+	s.mu.Unlock()
+	if err := c.Close(); err != nil {
+		s.logger.Error("Failed to close Kafka consumer", "error", err)
 		return
 	}
+	s.logger.Info("Kafka consumer stopped")
+}
 
-	if err := p.SubscribeTopics([]string{topic}, nil); err != nil {
-		slog.Error("Failed to subscribe to Kafka topic", "error", err)
-		return
+func (s *KafkaSubscriber) recordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastError = err.Error()
+}
+
+// Status reports the assigned partitions with their committed offset and lag.
+func (s *KafkaSubscriber) Status() events.SubscriptionStatus {
+	s.mu.Lock()
+	c, lastError := s.consumer, s.lastError
+	s.mu.Unlock()
+
+	status := events.SubscriptionStatus{Partitions: []events.PartitionStatus{}, LastError: lastError}
+	if c == nil {
+		return status
 	}
-	slog.Info("Kafka consumer started, listening to topic:", "topic", topic)
-
-	for con.run {
-		msg, err := p.ReadMessage(-1)
-		if err == nil {
-			var order models.Order
-			if err := json.Unmarshal(msg.Value, &order); err != nil {
-				slog.Error("Failed to unmarshal Kafka message", "error", err)
-				continue
-			}
-			slog.Info("Consumed order from Kafka", "key", msg.Key, "order", order)
-			// Process the order as needed, e.g., update database, trigger other actions, etc.
-		} else {
-			// The client will automatically try to recover from all errors.
-			slog.Error("Consumer error", "error", err)
+	assigned, err := c.Assignment()
+	if err != nil || len(assigned) == 0 {
+		return status
+	}
+	committed, err := c.Committed(assigned, statusTimeoutMs)
+	if err != nil {
+		s.logger.Warn("Failed to fetch committed offsets", "error", err)
+		committed = assigned
+	}
+	for _, tp := range committed {
+		low, high, err := c.GetWatermarkOffsets(*tp.Topic, tp.Partition)
+		if err != nil {
+			continue
+		}
+		position := int64(tp.Offset)
+		if position < 0 {
+			// Nothing committed yet: everything from the low watermark is outstanding.
+			position = low
 		}
+		status.Partitions = append(status.Partitions, events.PartitionStatus{
+			Topic:           *tp.Topic,
+			Partition:       tp.Partition,
+			CommittedOffset: int64(tp.Offset),
+			HighWatermark:   high,
+			Lag:             max(high-position, 0),
+		})
 	}
+	return status
 }
@@ -0,0 +1,40 @@
+//go:build !cgo
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/svadikari/golang_fiber_orders/src/events"
+)
+
+// confluent-kafka-go needs cgo; without it only the in-memory event bus is available.
+var errKafkaUnavailable = errors.New("kafka event bus requires a cgo build, set EVENT_BUS=memory")
+
+type KafkaProducer struct{}
+
+func NewKafkaProducer(cfg ProducerConfig, logger *slog.Logger) (*KafkaProducer, error) {
+	return nil, errKafkaUnavailable
+}
+
+func (kp *KafkaProducer) Publish(ctx context.Context, msg events.Message) error {
+	return errKafkaUnavailable
+}
+
+func (kp *KafkaProducer) Stats() events.PublisherStats {
+	return events.PublisherStats{}
+}
+
+func (kp *KafkaProducer) Close() {}
+
+type KafkaSubscriber struct{}
+
+func NewKafkaSubscriber(cfg ConsumerConfig, logger *slog.Logger) *KafkaSubscriber {
+	return &KafkaSubscriber{}
+}
+
+func (s *KafkaSubscriber) Subscribe(ctx context.Context, topics []string, handler events.Handler) error {
+	return errKafkaUnavailable
+}
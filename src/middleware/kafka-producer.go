@@ -1,62 +1,133 @@
+//go:build cgo
+
 package middleware
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
+	"errors"
 	"log/slog"
-	"os"
-	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
-	"github.com/svadikari/golang_fiber_orders/src/orders/models"
+	"github.com/svadikari/golang_fiber_orders/src/events"
 )
 
-func PublishOrder(order *models.Order, log *slog.Logger) {
-	broker := os.Getenv("KAFKA_BROKER")
-	topic := os.Getenv("KAFKA_TOPIC")
+var _ events.EventPublisher = (*KafkaProducer)(nil)
+
+// KafkaProducer is the application's single Kafka producer. It is safe for concurrent use
+// and must be closed on shutdown so buffered messages are flushed.
+type KafkaProducer struct {
+	producer     *kafka.Producer
+	logger       *slog.Logger
+	flushTimeout time.Duration
+	produced     atomic.Int64
+	delivered    atomic.Int64
+	failed       atomic.Int64
+}
 
-	if broker == "" {
-		broker = "localhost:9092"
+func NewKafkaProducer(cfg ProducerConfig, logger *slog.Logger) (*KafkaProducer, error) {
+	p, err := kafka.NewProducer(&kafka.ConfigMap{
+		"bootstrap.servers":  cfg.Broker,
+		"acks":               cfg.Acks,
+		"enable.idempotence": cfg.EnableIdempotence,
+		"linger.ms":          cfg.LingerMs,
+		"batch.num.messages": cfg.BatchNumMessages,
+		"compression.type":   cfg.CompressionType,
+		"message.timeout.ms": cfg.MessageTimeoutMs,
+	})
+	if err != nil {
+		return nil, err
 	}
-	if topic == "" {
-		topic = "orders"
+	producer := &KafkaProducer{
+		producer:     p,
+		logger:       logger.With("component", "KafkaProducer"),
+		flushTimeout: cfg.FlushTimeout,
 	}
-	p, err := kafka.NewProducer(&kafka.ConfigMap{"bootstrap.servers": broker})
-	if err != nil {
-		panic(err)
+	go producer.handleEvents()
+	producer.logger.Info("Kafka producer created", "broker", cfg.Broker, "acks", cfg.Acks, "idempotence", cfg.EnableIdempotence)
+	return producer, nil
+}
+
+// handleEvents logs client-level errors. Delivery reports are handled per message in Publish.
+func (kp *KafkaProducer) handleEvents() {
+	for e := range kp.producer.Events() {
+		switch ev := e.(type) {
+		case kafka.Error:
+			kp.logger.Error("Kafka producer error", "code", ev.Code(), "fatal", ev.IsFatal(), "error", ev)
+		case *kafka.Message:
+			kp.recordDelivery(ev)
+		}
 	}
-	jsonData, err := json.Marshal(order)
-	if err != nil {
-		log.Error("Error marshalling struct:", "error", err.Error())
-		return
-	}
-
-	wg := sync.WaitGroup{}
-
-	// Delivery report handler for produced messages
-	go func() {
-		defer wg.Done()
-		for e := range p.Events() {
-			switch ev := e.(type) {
-			case *kafka.Message:
-				if ev.TopicPartition.Error != nil {
-					log.Error("Failed to deliver message:", "partition", ev.TopicPartition, "error", ev.TopicPartition.Error)
-				} else {
-					log.Info("Published order event", "orderId", string(ev.Key), "partition", ev.TopicPartition.Partition, "Offset", ev.TopicPartition.Offset)
-				}
-			}
+}
+
+// Publish produces a single message and waits for its delivery report.
+func (kp *KafkaProducer) Publish(ctx context.Context, msg events.Message) error {
+	deliveryChan := make(chan kafka.Event, 1)
+	if err := kp.producer.Produce(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &msg.Topic, Partition: kafka.PartitionAny},
+		Key:            msg.Key,
+		Value:          msg.Value,
+		Headers:        toKafkaHeaders(msg.Headers),
+	}, deliveryChan); err != nil {
+		kp.failed.Add(1)
+		return err
+	}
+	kp.produced.Add(1)
+
+	select {
+	case e := <-deliveryChan:
+		ev, ok := e.(*kafka.Message)
+		if !ok {
+			return errors.New("unexpected delivery report")
 		}
-	}()
-	wg.Add(1)
-	if err := p.Produce(&kafka.Message{
-		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: int32(order.ID) % 5},
-		Key:            []byte(fmt.Sprintf("%d", order.ID)),
-		Value:          jsonData,
-	}, nil); err != nil {
-		log.Error("Failed to produce message to Kafka", "error", err)
-		return
-	}
-	p.Flush(15 * 1000)
-	p.Close()
-	wg.Wait()
+		return kp.recordDelivery(ev)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (kp *KafkaProducer) recordDelivery(msg *kafka.Message) error {
+	if msg.TopicPartition.Error != nil {
+		kp.failed.Add(1)
+		kp.logger.Error("Failed to deliver message", "topic", *msg.TopicPartition.Topic, "key", string(msg.Key), "error", msg.TopicPartition.Error)
+		return msg.TopicPartition.Error
+	}
+	kp.delivered.Add(1)
+	kp.logger.Debug("Delivered message", "topic", *msg.TopicPartition.Topic, "key", string(msg.Key), "partition", msg.TopicPartition.Partition, "offset", msg.TopicPartition.Offset)
+	return nil
+}
+
+func (kp *KafkaProducer) Stats() events.PublisherStats {
+	return events.PublisherStats{
+		Produced:  kp.produced.Load(),
+		Delivered: kp.delivered.Load(),
+		Failed:    kp.failed.Load(),
+		InFlight:  kp.producer.Len(),
+	}
+}
+
+// Close flushes outstanding messages, waiting at most the configured flush timeout, and closes the producer.
+func (kp *KafkaProducer) Close() {
+	if remaining := kp.producer.Flush(int(kp.flushTimeout.Milliseconds())); remaining > 0 {
+		kp.logger.Warn("Kafka producer closed with undelivered messages", "remaining", remaining)
+	}
+	kp.producer.Close()
+	kp.logger.Info("Kafka producer closed", "stats", kp.Stats())
+}
+
+func toKafkaHeaders(headers map[string]string) []kafka.Header {
+	kafkaHeaders := make([]kafka.Header, 0, len(headers))
+	for k, v := range headers {
+		kafkaHeaders = append(kafkaHeaders, kafka.Header{Key: k, Value: []byte(v)})
+	}
+	return kafkaHeaders
+}
+
+func fromKafkaHeaders(kafkaHeaders []kafka.Header) map[string]string {
+	headers := make(map[string]string, len(kafkaHeaders))
+	for _, h := range kafkaHeaders {
+		headers[h.Key] = string(h.Value)
+	}
+	return headers
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/svadikari/golang_fiber_orders/src/events"
 )
 
 func Logger(c *fiber.Ctx) error {
@@ -33,11 +34,16 @@ func Logger(c *fiber.Ctx) error {
 	}
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &opts))
 
-	// Generate a request ID
-	requestID := uuid.New().String()
+	// Reuse the caller's request ID so it correlates across services, or generate one
+	requestID := c.Get(fiber.HeaderXRequestID)
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+	c.Set(fiber.HeaderXRequestID, requestID)
 
 	// Create a new context with the request ID
 	cxt := context.WithValue(c.Context(), "requestID", requestID)
+	cxt = events.WithCorrelationID(cxt, requestID)
 
 	c.SetUserContext(cxt)
 
@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/events"
+)
+
+// pollTimeout bounds how long a consumer blocks waiting for a message, so cancellation is noticed promptly.
+const pollTimeout = 500 * time.Millisecond
+
+type ConsumerState string
+
+const (
+	ConsumerStopped  ConsumerState = "STOPPED"
+	ConsumerRunning  ConsumerState = "RUNNING"
+	ConsumerStopping ConsumerState = "STOPPING"
+	ConsumerFailed   ConsumerState = "FAILED"
+)
+
+var (
+	ErrConsumerRunning       = errors.New("order consumer is already running")
+	ErrConsumerNotRunning    = errors.New("order consumer is not running")
+	ErrConsumerNotConfigured = errors.New("order consumer is not configured")
+)
+
+type ConsumerStatus struct {
+	State      ConsumerState            `json:"state"`
+	Topics     []string                 `json:"topics"`
+	StartedAt  *time.Time               `json:"started_at"`
+	Partitions []events.PartitionStatus `json:"partitions"`
+	TotalLag   int64                    `json:"total_lag"`
+	LastError  string                   `json:"last_error,omitempty"`
+}
+
+type consumeOrders struct {
+	mu         sync.Mutex
+	parent     context.Context
+	subscriber events.EventSubscriber
+	topics     []string
+	handler    events.Handler
+	state      ConsumerState
+	cancel     context.CancelFunc
+	done       chan struct{}
+	startedAt  *time.Time
+	lastError  string
+}
+
+var consumerInstance = &consumeOrders{parent: context.Background(), state: ConsumerStopped}
+
+// ConfigureOrderConsumer sets the subscriber used by StartKafkaConsumer, the topics it reads
+// and the handler every consumed order event is dispatched to. The consumer never outlives ctx.
+func ConfigureOrderConsumer(ctx context.Context, subscriber events.EventSubscriber, topics []string, handler events.Handler) {
+	consumerInstance.mu.Lock()
+	defer consumerInstance.mu.Unlock()
+	consumerInstance.parent = ctx
+	consumerInstance.subscriber = subscriber
+	consumerInstance.topics = topics
+	consumerInstance.handler = handler
+}
+
+// AutoStartConsumer reports whether the order consumer should start with the application.
+func AutoStartConsumer() bool {
+	return getEnv("KAFKA_CONSUMER_AUTOSTART", "false") == "true"
+}
+
+func StartKafkaConsumer() error {
+	con := consumerInstance
+	con.mu.Lock()
+	defer con.mu.Unlock()
+	if con.subscriber == nil {
+		return ErrConsumerNotConfigured
+	}
+	if con.cancel != nil {
+		return ErrConsumerRunning
+	}
+
+	ctx, cancel := context.WithCancel(con.parent)
+	done := make(chan struct{})
+	now := time.Now()
+	con.state, con.cancel, con.done, con.startedAt, con.lastError = ConsumerRunning, cancel, done, &now, ""
+	go con.run(ctx, done)
+	slog.Info("Order consumer started", "topics", con.topics)
+	return nil
+}
+
+func (con *consumeOrders) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	err := con.subscriber.Subscribe(ctx, con.topics, con.handleRecordingErrors)
+
+	con.mu.Lock()
+	defer con.mu.Unlock()
+	con.cancel = nil
+	if err != nil {
+		slog.Error("Order consumer failed", "error", err)
+		con.state, con.lastError = ConsumerFailed, err.Error()
+		return
+	}
+	con.state = ConsumerStopped
+}
+
+func (con *consumeOrders) handleRecordingErrors(ctx context.Context, msg events.Message) error {
+	err := con.handler(ctx, msg)
+	// A retried message that is not due yet is held back, it did not fail.
+	var notDue *events.NotDueError
+	if err != nil && !errors.As(err, &notDue) {
+		con.mu.Lock()
+		con.lastError = err.Error()
+		con.mu.Unlock()
+	}
+	return err
+}
+
+// StopKafkaConsumer cancels the consumer and waits until it has left the consumer group.
+func StopKafkaConsumer() error {
+	con := consumerInstance
+	con.mu.Lock()
+	if con.cancel == nil {
+		con.mu.Unlock()
+		return ErrConsumerNotRunning
+	}
+	con.state = ConsumerStopping
+	con.cancel()
+	done := con.done
+	con.mu.Unlock()
+
+	<-done
+	slog.Info("Order consumer stopped")
+	return nil
+}
+
+func KafkaConsumerStatus() ConsumerStatus {
+	con := consumerInstance
+	con.mu.Lock()
+	status := ConsumerStatus{
+		State:      con.state,
+		Topics:     con.topics,
+		StartedAt:  con.startedAt,
+		Partitions: []events.PartitionStatus{},
+		LastError:  con.lastError,
+	}
+	reporter, ok := con.subscriber.(events.StatusReporter)
+	running := con.state == ConsumerRunning
+	con.mu.Unlock()
+
+	if ok && running {
+		subscription := reporter.Status()
+		status.Partitions = subscription.Partitions
+		if status.LastError == "" {
+			status.LastError = subscription.LastError
+		}
+		for _, partition := range subscription.Partitions {
+			status.TotalLag += partition.Lag
+		}
+	}
+	return status
+}
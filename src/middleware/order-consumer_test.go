@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svadikari/golang_fiber_orders/src/events"
+)
+
+func TestOrderConsumerLifecycle(t *testing.T) {
+
+	bus := events.NewMemoryBus()
+	handled := make(chan events.Message, 1)
+	ConfigureOrderConsumer(context.Background(), bus, []string{"orders"}, func(ctx context.Context, msg events.Message) error {
+		handled <- msg
+		return errors.New("payment service unavailable")
+	})
+
+	t.Run("Consumer starts once", func(t *testing.T) {
+		assert.NoError(t, StartKafkaConsumer())
+		assert.ErrorIs(t, StartKafkaConsumer(), ErrConsumerRunning)
+		assert.Equal(t, ConsumerRunning, KafkaConsumerStatus().State)
+	})
+
+	t.Run("Handler errors are reported as last error", func(t *testing.T) {
+		assert.Eventually(t, func() bool {
+			bus.Publish(context.Background(), events.Message{Topic: "orders"})
+			select {
+			case <-handled:
+				return true
+			default:
+				return false
+			}
+		}, time.Second, 10*time.Millisecond)
+		assert.Eventually(t, func() bool {
+			return KafkaConsumerStatus().LastError == "payment service unavailable"
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Stop waits for the consumer to finish", func(t *testing.T) {
+		assert.NoError(t, StopKafkaConsumer())
+		assert.Equal(t, ConsumerStopped, KafkaConsumerStatus().State)
+		assert.ErrorIs(t, StopKafkaConsumer(), ErrConsumerNotRunning)
+	})
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/svadikari/golang_fiber_orders/src/money"
+)
+
+type PaymentRequest struct {
+	IdempotencyKey string      `json:"idempotency_key"`
+	OrderID        uint        `json:"order_id"`
+	UserID         uint        `json:"user_id"`
+	Amount         money.Money `json:"amount"`
+}
+
+// RefundRequest gives back the payment made with PaymentKey.
+type RefundRequest struct {
+	PaymentKey string `json:"payment_key"`
+	OrderID    uint   `json:"order_id"`
+}
+
+type PaymentResponse struct {
+	Status string `json:"status"`
+}
+
+const PaymentApproved = "APPROVED"
+
+type PaymentService interface {
+	// ConfirmPayment reports whether the payment for an order was approved. An error means
+	// the outcome is unknown and the call should be retried. An order is charged at most once,
+	// however often it is called.
+	ConfirmPayment(orderId uint, userId uint, amount money.Money) (bool, error)
+	// RefundPayment gives back the payment of an order. Orders that were never charged, or
+	// were refunded already, are left as they are.
+	RefundPayment(orderId uint) error
+}
+
+// paymentKey identifies the payment of an order at the payment service, which charges and
+// refunds every key once, so retried calls are safe.
+func paymentKey(orderId uint) string {
+	return fmt.Sprintf("order-%d", orderId)
+}
+
+type paymentService struct {
+	restyClient *resty.Client
+}
+
+// NewPaymentService returns a client for PAYMENT_API_URL. Without it configured every
+// payment is approved, which keeps local setups working without a payment provider.
+func NewPaymentService() PaymentService {
+	PAYMENT_API_URL := os.Getenv("PAYMENT_API_URL")
+	if PAYMENT_API_URL == "" {
+		slog.Warn("PAYMENT_API_URL is not set, all payments will be approved")
+		return &autoApprovePaymentService{}
+	}
+	restyClient := resty.New()
+	restyClient.SetBaseURL(PAYMENT_API_URL).
+		SetHeader("Content-Type", "application/json").
+		SetTimeout(5 * time.Second).
+		SetRetryCount(3).
+		SetRetryWaitTime(1 * time.Second)
+
+	slog.Info("Initialized Payment Service Client", "baseURL", restyClient.BaseURL)
+	return &paymentService{restyClient}
+}
+
+func (ps *paymentService) ConfirmPayment(orderId uint, userId uint, amount money.Money) (bool, error) {
+	var payment PaymentResponse
+	resp, err := ps.restyClient.R().
+		SetHeader(HeaderIdempotencyKey, paymentKey(orderId)).
+		SetBody(PaymentRequest{IdempotencyKey: paymentKey(orderId), OrderID: orderId, UserID: userId, Amount: amount}).
+		SetResult(&payment).
+		Post("/payments")
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode() == 402 {
+		slog.Warn("Payment declined", "orderId", orderId)
+		return false, nil
+	}
+	if resp.IsError() {
+		return false, fmt.Errorf("payment service returned status %d", resp.StatusCode())
+	}
+	slog.Info("Payment processed", "orderId", orderId, "status", payment.Status)
+	return payment.Status == PaymentApproved, nil
+}
+
+func (ps *paymentService) RefundPayment(orderId uint) error {
+	resp, err := ps.restyClient.R().
+		SetHeader(HeaderIdempotencyKey, paymentKey(orderId)+"-refund").
+		SetBody(RefundRequest{PaymentKey: paymentKey(orderId), OrderID: orderId}).
+		Post("/refunds")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() == 404 {
+		slog.Info("No payment to refund", "orderId", orderId)
+		return nil
+	}
+	if resp.IsError() {
+		return fmt.Errorf("payment service returned status %d", resp.StatusCode())
+	}
+	slog.Info("Payment refunded", "orderId", orderId)
+	return nil
+}
+
+type autoApprovePaymentService struct{}
+
+func (ps *autoApprovePaymentService) ConfirmPayment(orderId uint, userId uint, amount money.Money) (bool, error) {
+	return true, nil
+}
+
+func (ps *autoApprovePaymentService) RefundPayment(orderId uint) error {
+	return nil
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svadikari/golang_fiber_orders/src/money"
+)
+
+func TestPaymentService(t *testing.T) {
+
+	t.Run("Payments are keyed by order", func(t *testing.T) {
+		var requests []PaymentRequest
+		var keys []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var request PaymentRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			requests = append(requests, request)
+			keys = append(keys, r.Header.Get(HeaderIdempotencyKey))
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"status": "APPROVED"}`))
+		}))
+		defer server.Close()
+		t.Setenv("PAYMENT_API_URL", server.URL)
+		payments := NewPaymentService()
+
+		for range 2 {
+			approved, err := payments.ConfirmPayment(42, 7, money.MustParse("12.25"))
+			require.NoError(t, err)
+			assert.True(t, approved)
+		}
+		require.Len(t, requests, 2)
+		assert.Equal(t, "order-42", requests[0].IdempotencyKey)
+		assert.Equal(t, requests[0], requests[1], "a retried charge is recognised by the payment service")
+		assert.Equal(t, []string{"order-42", "order-42"}, keys)
+	})
+
+	t.Run("Refunds name the payment they give back", func(t *testing.T) {
+		var request RefundRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/refunds", r.URL.Path)
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+		t.Setenv("PAYMENT_API_URL", server.URL)
+
+		assert.NoError(t, NewPaymentService().RefundPayment(42), "an order never charged has nothing to refund")
+		assert.Equal(t, RefundRequest{PaymentKey: "order-42", OrderID: 42}, request)
+	})
+}
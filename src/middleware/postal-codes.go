@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// postalCodeFormats holds the postal code formats of common destination countries.
+var postalCodeFormats = map[string]*regexp.Regexp{
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"BR": regexp.MustCompile(`^\d{5}-?\d{3}$`),
+	"CA": regexp.MustCompile(`^(?i)[A-Z]\d[A-Z] ?\d[A-Z]\d$`),
+	"CH": regexp.MustCompile(`^\d{4}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"ES": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"GB": regexp.MustCompile(`^(?i)[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+	"IN": regexp.MustCompile(`^\d{6}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+	"NL": regexp.MustCompile(`^(?i)\d{4} ?[A-Z]{2}$`),
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+}
+
+// postalCodePattern is what postal codes of other countries must look like: letters and digits,
+// optionally split by spaces or hyphens. They may be empty, as many countries have no postal codes.
+var postalCodePattern = regexp.MustCompile(`^(?i)([A-Z0-9]([A-Z0-9 -]{0,8}[A-Z0-9])?)?$`)
+
+func init() {
+	if err := validate.RegisterValidation("postal_code", isPostalCode); err != nil {
+		panic(err)
+	}
+}
+
+// isPostalCode validates a postal code against the format of the country held by the field named by
+// the tag parameter, such as `postal_code=Country`.
+func isPostalCode(fl validator.FieldLevel) bool {
+	country, kind, _, found := fl.GetStructFieldOKAdvanced2(fl.Parent(), fl.Param())
+	if !found || kind != reflect.String {
+		return false
+	}
+	code := fl.Field().String()
+	if format, ok := postalCodeFormats[strings.ToUpper(country.String())]; ok {
+		return format.MatchString(code)
+	}
+	return postalCodePattern.MatchString(code)
+}
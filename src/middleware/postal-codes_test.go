@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostalCode(t *testing.T) {
+
+	type address struct {
+		PostalCode string `validate:"postal_code=Country"`
+		Country    string
+	}
+	tests := []struct {
+		country, code string
+		valid         bool
+	}{
+		{"US", "94105", true},
+		{"US", "94105-1234", true},
+		{"US", "9410", false},
+		{"US", "", false},
+		{"GB", "SW1A 1AA", true},
+		{"CA", "k1a 0b1", true},
+		{"NL", "1234AB", true},
+		{"DE", "1011", false},
+		{"IE", "D02 X285", true},
+		{"HK", "", true},
+		{"IE", "D02_X285", false},
+	}
+	for _, test := range tests {
+		t.Run(test.country+" "+test.code, func(t *testing.T) {
+			err := validate.Struct(address{PostalCode: test.code, Country: test.country})
+			assert.Equal(t, test.valid, err == nil)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package migrations
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  up                 apply every pending migration
+  down [N]           revert the last N applied migrations (default 1)
+  status             list migrations and when they were applied
+  create [-dir D] <name>
+                     write an empty up/down pair for the next version (default dir src/migrations/sql)`
+
+// RunCLI runs the migrate subcommand with args, the arguments following "migrate". connect opens the
+// database and is not called for create, which only writes files.
+func RunCLI(args []string, connect func() (*gorm.DB, error), out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	command, args := args[0], args[1:]
+
+	if command == "create" {
+		flags := flag.NewFlagSet("create", flag.ContinueOnError)
+		flags.SetOutput(out)
+		dir := flags.String("dir", "src/migrations/sql", "directory holding the migration files")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		if flags.NArg() != 1 {
+			return errors.New(usage)
+		}
+		up, down, err := Create(*dir, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "created %s\ncreated %s\n", up, down)
+		return nil
+	}
+
+	steps := 1
+	switch command {
+	case "up", "status":
+		if len(args) != 0 {
+			return errors.New(usage)
+		}
+	case "down":
+		if len(args) > 1 {
+			return errors.New(usage)
+		}
+		if len(args) == 1 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("down expects a positive number of steps, got %q", args[0])
+			}
+			steps = n
+		}
+	default:
+		return errors.New(usage)
+	}
+
+	db, err := connect()
+	if err != nil {
+		return err
+	}
+	migrator, err := New(db)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Fprintf(out, "applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(out, "schema is up to date")
+		}
+		return err
+	case "down":
+		reverted, err := migrator.Down(steps)
+		for _, migration := range reverted {
+			fmt.Fprintf(out, "reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Fprintln(out, "no migrations to revert")
+		}
+		return err
+	default:
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(out, "%04d_%-40s %s\n", status.Version, status.Name, appliedAt)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,105 @@
+package migrations
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("embedded migrations are complete and contiguous", func(t *testing.T) {
+		migrations, err := Load()
+		require.NoError(t, err)
+		require.NotEmpty(t, migrations)
+		for i, migration := range migrations {
+			assert.Equal(t, i+1, migration.Version)
+			assert.NotEmpty(t, migration.Up)
+			assert.NotEmpty(t, migration.Down)
+		}
+		assert.Equal(t, "baseline", migrations[0].Name)
+	})
+
+	t.Run("orders by version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"0010_later.up.sql":    {Data: []byte("SELECT 10")},
+			"0010_later.down.sql":  {Data: []byte("SELECT -10")},
+			"0002_second.up.sql":   {Data: []byte("SELECT 2")},
+			"0002_second.down.sql": {Data: []byte("SELECT -2")},
+		}
+		migrations, err := load(fsys, ".")
+		require.NoError(t, err)
+		require.Len(t, migrations, 2)
+		assert.Equal(t, Migration{Version: 2, Name: "second", Up: "SELECT 2", Down: "SELECT -2"}, migrations[0])
+		assert.Equal(t, 10, migrations[1].Version)
+	})
+
+	t.Run("rejects a migration without a down file", func(t *testing.T) {
+		_, err := load(fstest.MapFS{"0001_init.up.sql": {Data: []byte("SELECT 1")}}, ".")
+		assert.ErrorContains(t, err, "needs both an up and a down file")
+	})
+
+	t.Run("rejects badly named files", func(t *testing.T) {
+		_, err := load(fstest.MapFS{"init.sql": {Data: []byte("SELECT 1")}}, ".")
+		assert.ErrorContains(t, err, "does not match")
+	})
+
+	t.Run("rejects a version with two names", func(t *testing.T) {
+		_, err := load(fstest.MapFS{
+			"0001_init.up.sql":    {Data: []byte("SELECT 1")},
+			"0001_other.down.sql": {Data: []byte("SELECT -1")},
+		}, ".")
+		assert.ErrorContains(t, err, "has two names")
+	})
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("writes the next version", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "0001_baseline.up.sql"), []byte("SELECT 1"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "0001_baseline.down.sql"), []byte("SELECT -1"), 0o644))
+
+		up, down, err := Create(dir, "add_currency")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, "0002_add_currency.up.sql"), up)
+		assert.Equal(t, filepath.Join(dir, "0002_add_currency.down.sql"), down)
+		assert.FileExists(t, up)
+		assert.FileExists(t, down)
+	})
+
+	t.Run("starts at one in an empty directory", func(t *testing.T) {
+		up, _, err := Create(t.TempDir(), "init")
+		require.NoError(t, err)
+		assert.Equal(t, "0001_init.up.sql", filepath.Base(up))
+	})
+
+	t.Run("rejects invalid names", func(t *testing.T) {
+		_, _, err := Create(t.TempDir(), "Add Currency")
+		assert.ErrorContains(t, err, "must be lower case")
+	})
+}
+
+func TestRunCLI(t *testing.T) {
+	connect := func() (*gorm.DB, error) { return nil, errors.New("connect should not be called") }
+
+	t.Run("create does not connect", func(t *testing.T) {
+		dir := t.TempDir()
+		var out bytes.Buffer
+		require.NoError(t, RunCLI([]string{"create", "-dir", dir, "init"}, connect, &out))
+		assert.Contains(t, out.String(), "0001_init.up.sql")
+	})
+
+	t.Run("rejects unknown commands and arguments before connecting", func(t *testing.T) {
+		for _, args := range [][]string{nil, {"sideways"}, {"up", "2"}, {"down", "zero"}, {"down", "0"}, {"create"}} {
+			err := RunCLI(args, connect, &bytes.Buffer{})
+			require.Error(t, err, args)
+			assert.NotContains(t, err.Error(), "connect should not be called", args)
+		}
+	})
+}
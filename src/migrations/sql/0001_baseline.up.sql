@@ -0,0 +1,111 @@
+-- Baseline schema, matching what GORM AutoMigrate created before versioned migrations were introduced.
+-- Statements are idempotent so existing databases can be brought under version control.
+
+CREATE TABLE IF NOT EXISTS products (
+    id          bigserial PRIMARY KEY,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz,
+    name        varchar(200)  NOT NULL,
+    description varchar(1000) NOT NULL,
+    price       numeric       NOT NULL CONSTRAINT chk_products_price CHECK (price >= 0.1),
+    stock       bigint,
+    image_url   text
+);
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_name ON products (name);
+
+CREATE TABLE IF NOT EXISTS orders (
+    id             bigserial PRIMARY KEY,
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    deleted_at     timestamptz,
+    user_id        bigint       NOT NULL,
+    total_amount   numeric      NOT NULL CONSTRAINT chk_orders_total_amount CHECK (total_amount >= 0.1),
+    status         varchar(100) NOT NULL,
+    stock_released boolean      NOT NULL DEFAULT false
+);
+CREATE INDEX IF NOT EXISTS idx_orders_deleted_at ON orders (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_user_id ON orders (user_id);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id         bigserial PRIMARY KEY,
+    order_id   bigint  NOT NULL CONSTRAINT fk_orders_order_items REFERENCES orders (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    product_id bigint  NOT NULL,
+    quantity   bigint  NOT NULL CONSTRAINT chk_order_items_quantity CHECK (quantity > 0),
+    unit_price numeric NOT NULL CONSTRAINT chk_order_items_unit_price CHECK (unit_price >= 0.1)
+);
+CREATE INDEX IF NOT EXISTS idx_order_id ON order_items (order_id);
+CREATE INDEX IF NOT EXISTS idx_product_id ON order_items (product_id);
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id          bigserial PRIMARY KEY,
+    order_id    bigint       NOT NULL,
+    from_status varchar(100),
+    to_status   varchar(100) NOT NULL,
+    changed_by  varchar(100) NOT NULL,
+    reason      varchar(500),
+    created_at  timestamptz  NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_status_history_order_id ON order_status_history (order_id);
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id              bigserial PRIMARY KEY,
+    event_id        varchar(36)  NOT NULL,
+    aggregate_type  varchar(100) NOT NULL,
+    aggregate_id    varchar(100) NOT NULL,
+    event_type      varchar(100) NOT NULL,
+    topic           varchar(255) NOT NULL,
+    message_key     varchar(255),
+    headers         jsonb,
+    payload         bytea        NOT NULL,
+    status          varchar(20)  NOT NULL DEFAULT 'PENDING',
+    attempts        bigint       NOT NULL DEFAULT 0,
+    last_error      varchar(1000),
+    next_attempt_at timestamptz  NOT NULL,
+    created_at      timestamptz  NOT NULL,
+    sent_at         timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_event_id ON outbox (event_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (status, next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS processed_events (
+    consumer     varchar(100) NOT NULL,
+    event_id     varchar(255) NOT NULL,
+    topic        varchar(255) NOT NULL,
+    "partition"  integer      NOT NULL,
+    "offset"     bigint       NOT NULL,
+    processed_at timestamptz  NOT NULL,
+    PRIMARY KEY (consumer, event_id)
+);
+CREATE INDEX IF NOT EXISTS idx_processed_events_processed_at ON processed_events (processed_at);
+
+CREATE TABLE IF NOT EXISTS dead_letters (
+    id                 bigserial PRIMARY KEY,
+    topic              varchar(255) NOT NULL,
+    original_topic     varchar(255) NOT NULL,
+    original_partition integer      NOT NULL,
+    original_offset    bigint       NOT NULL,
+    message_key        varchar(255),
+    payload            bytea,
+    headers            jsonb,
+    error              varchar(2000),
+    error_type         varchar(50),
+    retry_count        bigint       NOT NULL DEFAULT 0,
+    failed_at          timestamptz  NOT NULL,
+    replay_count       bigint       NOT NULL DEFAULT 0,
+    replayed_at        timestamptz,
+    created_at         timestamptz  NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_dead_letters_original_topic ON dead_letters (original_topic);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key           varchar(255) PRIMARY KEY,
+    request_hash  varchar(64)  NOT NULL,
+    status_code   bigint       NOT NULL DEFAULT 0,
+    content_type  varchar(100),
+    response_body bytea,
+    created_at    timestamptz  NOT NULL,
+    expires_at    timestamptz  NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,3 @@
+ALTER TABLE outbox ADD COLUMN content_type varchar(100);
+UPDATE outbox SET content_type = coalesce(headers ->> 'content-type', 'application/cloudevents+json');
+ALTER TABLE outbox ALTER COLUMN content_type SET NOT NULL;
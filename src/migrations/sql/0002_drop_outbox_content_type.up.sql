@@ -0,0 +1,3 @@
+-- The content type of outbox messages moved into the headers column; AutoMigrate could not drop the
+-- old NOT NULL column, which makes inserts fail on databases created before the move.
+ALTER TABLE outbox DROP COLUMN IF EXISTS content_type;
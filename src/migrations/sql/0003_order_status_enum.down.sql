@@ -0,0 +1,5 @@
+ALTER TABLE orders
+    ALTER COLUMN status DROP DEFAULT,
+    ALTER COLUMN status TYPE varchar(100) USING status::text;
+
+DROP TYPE order_status;
@@ -0,0 +1,5 @@
+CREATE TYPE order_status AS ENUM ('NEW', 'CONFIRMED', 'SHIPPED', 'DELIVERED', 'CANCELLED', 'RETURNED');
+
+ALTER TABLE orders
+    ALTER COLUMN status TYPE order_status USING status::order_status,
+    ALTER COLUMN status SET DEFAULT 'NEW';
@@ -0,0 +1,3 @@
+ALTER TABLE order_items ALTER COLUMN unit_price TYPE numeric;
+ALTER TABLE orders ALTER COLUMN total_amount TYPE numeric;
+ALTER TABLE products ALTER COLUMN price TYPE numeric;
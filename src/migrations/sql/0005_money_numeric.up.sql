@@ -0,0 +1,4 @@
+-- Amounts are exact to the cent. Existing values are rounded half away from zero, as round() does for numeric.
+ALTER TABLE products ALTER COLUMN price TYPE numeric(12,2) USING round(price::numeric, 2);
+ALTER TABLE orders ALTER COLUMN total_amount TYPE numeric(12,2) USING round(total_amount::numeric, 2);
+ALTER TABLE order_items ALTER COLUMN unit_price TYPE numeric(12,2) USING round(unit_price::numeric, 2);
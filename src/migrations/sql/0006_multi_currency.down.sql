@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS exchange_rates;
+DROP TABLE IF EXISTS product_prices;
+ALTER TABLE orders DROP COLUMN IF EXISTS currency;
+ALTER TABLE products DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,19 @@
+-- Existing products and orders were all priced in the default currency.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS currency char(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS currency char(3) NOT NULL DEFAULT 'USD';
+
+CREATE TABLE IF NOT EXISTS product_prices (
+    id bigserial PRIMARY KEY,
+    product_id bigint NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    currency char(3) NOT NULL,
+    amount numeric(12,2) NOT NULL CHECK (amount > 0),
+    CONSTRAINT uq_product_prices_currency UNIQUE (product_id, currency)
+);
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base char(3) NOT NULL,
+    quote char(3) NOT NULL,
+    rate numeric(12,6) NOT NULL CHECK (rate > 0),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (base, quote)
+);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS tax_rates;
+DROP TABLE IF EXISTS order_tax_lines;
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_amount, DROP COLUMN IF EXISTS tax_category;
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS tax_mode,
+    DROP COLUMN IF EXISTS tax_region,
+    DROP COLUMN IF EXISTS tax_amount,
+    DROP COLUMN IF EXISTS subtotal;
+ALTER TABLE products DROP COLUMN IF EXISTS tax_category;
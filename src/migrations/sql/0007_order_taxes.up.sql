@@ -0,0 +1,34 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_category varchar(50) NOT NULL DEFAULT 'standard';
+
+-- Orders placed before taxes were untaxed: their subtotal is their total.
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS subtotal numeric(12,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tax_amount numeric(12,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tax_region varchar(6) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS tax_mode varchar(10) NOT NULL DEFAULT 'exclusive';
+UPDATE orders SET subtotal = total_amount;
+
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS tax_category varchar(50) NOT NULL DEFAULT 'standard',
+    ADD COLUMN IF NOT EXISTS tax_amount numeric(12,2) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS order_tax_lines (
+    id bigserial PRIMARY KEY,
+    order_id bigint NOT NULL REFERENCES orders (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    name varchar(100) NOT NULL,
+    region varchar(6) NOT NULL,
+    category varchar(50) NOT NULL,
+    rate numeric(12,6) NOT NULL,
+    taxable_amount numeric(12,2) NOT NULL,
+    tax_amount numeric(12,2) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_order_tax_lines_order_id ON order_tax_lines (order_id);
+
+CREATE TABLE IF NOT EXISTS tax_rates (
+    region varchar(6) NOT NULL,
+    category varchar(50) NOT NULL,
+    name varchar(100) NOT NULL,
+    rate numeric(12,6) NOT NULL CHECK (rate >= 0),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (region, category)
+);
@@ -0,0 +1,16 @@
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS chk_orders_total_amount;
+ALTER TABLE orders ADD CONSTRAINT chk_orders_total_amount CHECK (total_amount >= 0.1) NOT VALID;
+
+ALTER TABLE order_items DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS promotion_id,
+    DROP COLUMN IF EXISTS coupon_code,
+    DROP COLUMN IF EXISTS discount_amount;
+
+DROP TABLE IF EXISTS promotion_redemptions;
+DROP TABLE IF EXISTS promotion_categories;
+DROP TABLE IF EXISTS promotion_products;
+DROP TABLE IF EXISTS promotions;
+
+DROP INDEX IF EXISTS idx_products_category;
+ALTER TABLE products DROP COLUMN IF EXISTS category;
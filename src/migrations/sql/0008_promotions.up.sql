@@ -0,0 +1,54 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS category varchar(100) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_products_category ON products (category);
+
+CREATE TABLE IF NOT EXISTS promotions (
+    id                   bigserial PRIMARY KEY,
+    created_at           timestamptz,
+    updated_at           timestamptz,
+    deleted_at           timestamptz,
+    code                 varchar(50)   NOT NULL,
+    description          varchar(500)  NOT NULL DEFAULT '',
+    type                 varchar(20)   NOT NULL CHECK (type IN ('percentage', 'fixed_amount', 'free_shipping')),
+    percentage           numeric(12,6) NOT NULL DEFAULT 0 CHECK (percentage BETWEEN 0 AND 1),
+    amount               numeric(12,2) NOT NULL DEFAULT 0 CHECK (amount >= 0),
+    min_order_total      numeric(12,2) NOT NULL DEFAULT 0 CHECK (min_order_total >= 0),
+    currency             char(3)       NOT NULL DEFAULT 'USD',
+    usage_limit_per_user bigint        NOT NULL DEFAULT 0 CHECK (usage_limit_per_user >= 0),
+    starts_at            timestamptz,
+    ends_at              timestamptz,
+    active               boolean       NOT NULL DEFAULT true
+);
+CREATE INDEX IF NOT EXISTS idx_promotions_deleted_at ON promotions (deleted_at);
+-- Codes of deleted promotions can be reused.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_promotions_code ON promotions (code) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS promotion_products (
+    promotion_id bigint NOT NULL REFERENCES promotions (id) ON DELETE CASCADE,
+    product_id   bigint NOT NULL,
+    PRIMARY KEY (promotion_id, product_id)
+);
+
+CREATE TABLE IF NOT EXISTS promotion_categories (
+    promotion_id bigint       NOT NULL REFERENCES promotions (id) ON DELETE CASCADE,
+    category     varchar(100) NOT NULL,
+    PRIMARY KEY (promotion_id, category)
+);
+
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    id           bigserial PRIMARY KEY,
+    promotion_id bigint      NOT NULL REFERENCES promotions (id),
+    user_id      bigint      NOT NULL,
+    order_id     bigint      NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    created_at   timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_user ON promotion_redemptions (promotion_id, user_id);
+
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS discount_amount numeric(12,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS coupon_code varchar(50) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS promotion_id bigint REFERENCES promotions (id);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS discount_amount numeric(12,2) NOT NULL DEFAULT 0;
+
+-- A coupon may discount an order down to nothing.
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS chk_orders_total_amount;
+ALTER TABLE orders ADD CONSTRAINT chk_orders_total_amount CHECK (total_amount >= 0);
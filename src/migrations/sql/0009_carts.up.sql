@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS carts (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    currency char(3) NOT NULL,
+    tax_region varchar(6) NOT NULL DEFAULT '',
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_carts_user_id ON carts (user_id);
+CREATE INDEX IF NOT EXISTS idx_carts_expires_at ON carts (expires_at);
+
+CREATE TABLE IF NOT EXISTS cart_items (
+    id bigserial PRIMARY KEY,
+    cart_id bigint NOT NULL REFERENCES carts (id) ON DELETE CASCADE,
+    product_id bigint NOT NULL,
+    quantity bigint NOT NULL CHECK (quantity > 0),
+    created_at timestamptz,
+    updated_at timestamptz,
+    CONSTRAINT uq_cart_items_product UNIQUE (cart_id, product_id)
+);
@@ -0,0 +1,22 @@
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS shipped_at,
+    DROP COLUMN IF EXISTS carrier,
+    DROP COLUMN IF EXISTS tracking_number,
+    DROP COLUMN IF EXISTS shipping_cost,
+    DROP COLUMN IF EXISTS shipping_method,
+    DROP COLUMN IF EXISTS billing_country,
+    DROP COLUMN IF EXISTS billing_postal_code,
+    DROP COLUMN IF EXISTS billing_region,
+    DROP COLUMN IF EXISTS billing_city,
+    DROP COLUMN IF EXISTS billing_line2,
+    DROP COLUMN IF EXISTS billing_line1,
+    DROP COLUMN IF EXISTS billing_name,
+    DROP COLUMN IF EXISTS shipping_country,
+    DROP COLUMN IF EXISTS shipping_postal_code,
+    DROP COLUMN IF EXISTS shipping_region,
+    DROP COLUMN IF EXISTS shipping_city,
+    DROP COLUMN IF EXISTS shipping_line2,
+    DROP COLUMN IF EXISTS shipping_line1,
+    DROP COLUMN IF EXISTS shipping_name;
+
+DROP TABLE IF EXISTS shipping_methods;
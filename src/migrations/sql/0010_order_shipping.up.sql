@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS shipping_methods (
+    code varchar(50) PRIMARY KEY,
+    name varchar(100) NOT NULL,
+    cost numeric(12,2) NOT NULL CHECK (cost >= 0),
+    currency char(3) NOT NULL,
+    active boolean NOT NULL DEFAULT true,
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- Orders placed before shipping was recorded have empty addresses and no shipping method.
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS shipping_name varchar(200) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS shipping_line1 varchar(200) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS shipping_line2 varchar(200) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS shipping_city varchar(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS shipping_region varchar(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS shipping_postal_code varchar(20) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS shipping_country varchar(2) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS billing_name varchar(200) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS billing_line1 varchar(200) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS billing_line2 varchar(200) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS billing_city varchar(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS billing_region varchar(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS billing_postal_code varchar(20) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS billing_country varchar(2) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS shipping_method varchar(50) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS shipping_cost numeric(12,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS tracking_number varchar(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS carrier varchar(50) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS shipped_at timestamptz;
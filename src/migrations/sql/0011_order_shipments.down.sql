@@ -0,0 +1,19 @@
+DROP TABLE IF EXISTS shipment_items;
+DROP TABLE IF EXISTS shipments;
+
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS fulfillment_status,
+    DROP COLUMN IF EXISTS delivered_quantity,
+    DROP COLUMN IF EXISTS shipped_quantity;
+
+-- Partially fulfilled orders fall back to the last status they would have had without partial shipments.
+ALTER TABLE orders ALTER COLUMN status DROP DEFAULT;
+ALTER TYPE order_status RENAME TO order_status_old;
+CREATE TYPE order_status AS ENUM ('NEW', 'CONFIRMED', 'SHIPPED', 'DELIVERED', 'CANCELLED', 'RETURNED');
+ALTER TABLE orders
+    ALTER COLUMN status TYPE order_status USING (CASE status::text
+        WHEN 'PARTIALLY_SHIPPED' THEN 'CONFIRMED'
+        WHEN 'PARTIALLY_DELIVERED' THEN 'SHIPPED'
+        ELSE status::text END)::order_status,
+    ALTER COLUMN status SET DEFAULT 'NEW';
+DROP TYPE order_status_old;
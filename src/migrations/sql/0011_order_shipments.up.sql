@@ -0,0 +1,39 @@
+-- PARTIALLY_SHIPPED and PARTIALLY_DELIVERED are derived from the fulfillment of the order lines. The type is
+-- recreated rather than altered so the values can be used within this transaction.
+ALTER TABLE orders ALTER COLUMN status DROP DEFAULT;
+ALTER TYPE order_status RENAME TO order_status_old;
+CREATE TYPE order_status AS ENUM ('NEW', 'CONFIRMED', 'PARTIALLY_SHIPPED', 'SHIPPED', 'PARTIALLY_DELIVERED', 'DELIVERED', 'CANCELLED', 'RETURNED');
+ALTER TABLE orders
+    ALTER COLUMN status TYPE order_status USING status::text::order_status,
+    ALTER COLUMN status SET DEFAULT 'NEW';
+DROP TYPE order_status_old;
+
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS shipped_quantity bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS delivered_quantity bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS fulfillment_status varchar(20) NOT NULL DEFAULT 'UNFULFILLED';
+
+-- Lines of orders shipped before shipments were recorded shipped in full.
+UPDATE order_items SET shipped_quantity = quantity, fulfillment_status = 'SHIPPED'
+FROM orders WHERE orders.id = order_items.order_id AND orders.status IN ('SHIPPED', 'RETURNED');
+UPDATE order_items SET shipped_quantity = quantity, delivered_quantity = quantity, fulfillment_status = 'DELIVERED'
+FROM orders WHERE orders.id = order_items.order_id AND orders.status = 'DELIVERED';
+
+CREATE TABLE IF NOT EXISTS shipments (
+    id bigserial PRIMARY KEY,
+    order_id bigint NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    tracking_number varchar(100) NOT NULL,
+    carrier varchar(50) NOT NULL,
+    status varchar(20) NOT NULL,
+    shipped_at timestamptz NOT NULL,
+    delivered_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_shipments_order_id ON shipments (order_id);
+
+CREATE TABLE IF NOT EXISTS shipment_items (
+    id bigserial PRIMARY KEY,
+    shipment_id bigint NOT NULL REFERENCES shipments (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    order_item_id bigint NOT NULL REFERENCES order_items (id) ON DELETE CASCADE,
+    quantity bigint NOT NULL CHECK (quantity > 0)
+);
+CREATE INDEX IF NOT EXISTS idx_shipment_items_shipment_id ON shipment_items (shipment_id);
@@ -0,0 +1,3 @@
+-- The relay holds back a message while an older message with the same key is unsent; this index keeps that
+-- lookup off the sent rows.
+CREATE INDEX IF NOT EXISTS idx_outbox_unsent_key ON outbox (message_key, id) WHERE status <> 'SENT';
@@ -0,0 +1 @@
+UPDATE outbox SET status = 'FAILED' WHERE status = 'PENDING' AND attempts >= 10;
@@ -0,0 +1,3 @@
+-- The relay no longer gives up on messages: a FAILED row blocked the later messages of its key for good,
+-- so failed rows are retried like any other pending row.
+UPDATE outbox SET status = 'PENDING', next_attempt_at = now() WHERE status = 'FAILED';
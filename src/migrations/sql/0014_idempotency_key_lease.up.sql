@@ -0,0 +1,4 @@
+-- A request holds its idempotency key only until locked_until; a key still in progress after that is taken over
+-- by the next request instead of answering 409 until it expires.
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS locked_until timestamptz NOT NULL DEFAULT now();
+ALTER TABLE idempotency_keys ALTER COLUMN locked_until DROP DEFAULT;
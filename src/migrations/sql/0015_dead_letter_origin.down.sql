@@ -0,0 +1,3 @@
+ALTER TABLE dead_letters DROP COLUMN IF EXISTS published_at;
+DROP INDEX IF EXISTS idx_dead_letters_origin;
+CREATE INDEX IF NOT EXISTS idx_dead_letters_original_topic ON dead_letters (original_topic);
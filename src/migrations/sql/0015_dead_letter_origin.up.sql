@@ -0,0 +1,14 @@
+-- A dead letter is stored before it is published to the dead-letter topic and identified by the message it was
+-- made from, so a redelivered message neither adds a second letter nor publishes it again. Letters duplicated
+-- by such redeliveries before are merged into the first one.
+DELETE FROM dead_letters AS duplicate USING dead_letters AS first
+WHERE duplicate.original_topic = first.original_topic
+  AND duplicate.original_partition = first.original_partition
+  AND duplicate.original_offset = first.original_offset
+  AND duplicate.id > first.id;
+DROP INDEX IF EXISTS idx_dead_letters_original_topic;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_dead_letters_origin ON dead_letters (original_topic, original_partition, original_offset);
+
+-- Letters stored so far were published before they were saved.
+ALTER TABLE dead_letters ADD COLUMN IF NOT EXISTS published_at timestamptz;
+UPDATE dead_letters SET published_at = failed_at;
@@ -0,0 +1,304 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact amount in minor units (cents). It is stored as numeric(12,2) and encoded in JSON as a
+// decimal number with two decimal places. Amounts add and subtract exactly with + and -.
+//
+// Rounding rules: line totals (Mul) are exact; rates such as tax and percentage discounts (MulRate) round
+// each result half away from zero; amounts split across lines (Allocate) hand out the leftover cents by
+// largest remainder, so the parts always add up to the whole.
+type Money int64
+
+// Rate is an exact decimal ratio with six decimal places, such as a tax rate (0.0825) or a discount (0.15).
+// It is stored as numeric(12,6) and encoded in JSON as a decimal number.
+type Rate int64
+
+const (
+	moneyDecimals = 2
+	rateDecimals  = 6
+	rateScale     = 1_000_000
+	// maxDigits keeps parsed integer parts well inside int64 once scaled.
+	maxDigits = 12
+)
+
+var ErrInvalidAmount = errors.New("invalid decimal amount")
+
+// Cents returns the amount of c minor units.
+func Cents(c int64) Money {
+	return Money(c)
+}
+
+// Parse reads a decimal amount such as "12", "12.5" or "-0.25". More than two decimal places is an error.
+func Parse(s string) (Money, error) {
+	v, err := parseDecimal(s, moneyDecimals, false)
+	return Money(v), err
+}
+
+// MustParse is Parse for constants; it panics on invalid input.
+func MustParse(s string) Money {
+	m, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// FromFloat converts f to the nearest cent, rounding half away from zero.
+func FromFloat(f float64) (Money, error) {
+	v, err := parseDecimal(strconv.FormatFloat(f, 'f', -1, 64), moneyDecimals, true)
+	return Money(v), err
+}
+
+// Float64 returns m as a float, for APIs and wire formats that only carry floating point numbers.
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+func (m Money) String() string {
+	return formatDecimal(int64(m), moneyDecimals)
+}
+
+// Mul returns the line total of quantity units at m.
+func (m Money) Mul(quantity int) Money {
+	return m * Money(quantity)
+}
+
+// MulRate returns m multiplied by r, rounded half away from zero to the cent.
+func (m Money) MulRate(r Rate) Money {
+	product := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(int64(r)))
+	return Money(divRound(product, big.NewInt(rateScale)))
+}
+
+// Convert returns m multiplied by to/from, rounded half away from zero to the cent. It converts between
+// currencies quoted against a common base: from and to are how much of each currency one base unit buys.
+func (m Money) Convert(from, to Rate) Money {
+	product := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(int64(to)))
+	return Money(divRound(product, big.NewInt(int64(from))))
+}
+
+// Allocate splits m across parts in proportion to weights. Every part is rounded down to the cent and
+// the leftover cents go to the parts with the largest remainders, earliest first on ties, so the parts
+// add up to m exactly. With no positive weight, m goes to the first part.
+func (m Money) Allocate(weights []Money) []Money {
+	parts := make([]Money, len(weights))
+	if len(weights) == 0 {
+		return parts
+	}
+	var total int64
+	for _, w := range weights {
+		if w > 0 {
+			total += int64(w)
+		}
+	}
+	if total == 0 {
+		parts[0] = m
+		return parts
+	}
+
+	sign := int64(1)
+	amount := int64(m)
+	if amount < 0 {
+		sign, amount = -1, -amount
+	}
+	remainders := make([]*big.Int, len(weights))
+	allocated := int64(0)
+	for i, w := range weights {
+		remainders[i] = new(big.Int)
+		if w <= 0 {
+			continue
+		}
+		share, rem := new(big.Int).QuoRem(
+			new(big.Int).Mul(big.NewInt(amount), big.NewInt(int64(w))), big.NewInt(total), remainders[i])
+		parts[i] = Money(share.Int64())
+		remainders[i] = rem
+		allocated += share.Int64()
+	}
+	for leftover := amount - allocated; leftover > 0; leftover-- {
+		largest := 0
+		for i := range remainders {
+			if remainders[i].Cmp(remainders[largest]) > 0 {
+				largest = i
+			}
+		}
+		parts[largest]++
+		remainders[largest].SetInt64(-1)
+	}
+	for i := range parts {
+		parts[i] *= Money(sign)
+	}
+	return parts
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding one.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	return m.UnmarshalText([]byte(strings.Trim(string(data), `"`)))
+}
+
+func (m Money) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalText(text []byte) error {
+	v, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+func (m *Money) Scan(src any) error {
+	v, err := scanDecimal(src, moneyDecimals)
+	*m = Money(v)
+	return err
+}
+
+// ParseRate reads a decimal ratio such as "0.0825". More than six decimal places is an error.
+func ParseRate(s string) (Rate, error) {
+	v, err := parseDecimal(s, rateDecimals, false)
+	return Rate(v), err
+}
+
+// MustParseRate is ParseRate for constants; it panics on invalid input.
+func MustParseRate(s string) Rate {
+	r, err := ParseRate(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func (r Rate) String() string {
+	return formatDecimal(int64(r), rateDecimals)
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding one.
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	return r.UnmarshalText([]byte(strings.Trim(string(data), `"`)))
+}
+
+func (r Rate) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Rate) UnmarshalText(text []byte) error {
+	v, err := ParseRate(string(text))
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+func (r *Rate) Scan(src any) error {
+	v, err := scanDecimal(src, rateDecimals)
+	*r = Rate(v)
+	return err
+}
+
+// parseDecimal reads s as an integer number of 10^-decimals units. Extra decimal places are an error
+// unless round is set, in which case they round half away from zero.
+func parseDecimal(s string, decimals int, round bool) (int64, error) {
+	text := strings.TrimSpace(s)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(strings.TrimPrefix(text, "-"), "+")
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" && fraction == "" || len(whole) > maxDigits || !isDigits(whole) || !isDigits(fraction) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+
+	roundUp := false
+	if len(fraction) > decimals {
+		if !round {
+			return 0, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidAmount, s, decimals)
+		}
+		roundUp = fraction[decimals] >= '5'
+		fraction = fraction[:decimals]
+	}
+	fraction += strings.Repeat("0", decimals-len(fraction))
+	v, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	if roundUp {
+		v++
+	}
+	if negative {
+		v = -v
+	}
+	return v, nil
+}
+
+func scanDecimal(src any, decimals int) (int64, error) {
+	switch v := src.(type) {
+	case nil:
+		return 0, nil
+	case []byte:
+		return parseDecimal(string(v), decimals, true)
+	case string:
+		return parseDecimal(v, decimals, true)
+	case float64:
+		return parseDecimal(strconv.FormatFloat(v, 'f', -1, 64), decimals, true)
+	case int64:
+		return parseDecimal(strconv.FormatInt(v, 10), decimals, true)
+	default:
+		return 0, fmt.Errorf("cannot scan %T into a decimal amount", src)
+	}
+}
+
+func formatDecimal(v int64, decimals int) string {
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	digits := fmt.Sprintf("%0*d", decimals+1, v)
+	return sign + digits[:len(digits)-decimals] + "." + digits[len(digits)-decimals:]
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// divRound divides n by d, rounding half away from zero.
+func divRound(n, d *big.Int) int64 {
+	quotient, remainder := new(big.Int).QuoRem(n, d, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(d) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(n.Sign())))
+	}
+	return quotient.Int64()
+}
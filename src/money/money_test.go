@@ -0,0 +1,138 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("reads decimal amounts", func(t *testing.T) {
+		for text, want := range map[string]Money{"12": 1200, "12.5": 1250, "12.05": 1205, ".5": 50, "-0.25": -25, "+3": 300, "0": 0} {
+			got, err := Parse(text)
+			require.NoError(t, err, text)
+			assert.Equal(t, want, got, text)
+		}
+	})
+
+	t.Run("rejects more than two decimal places", func(t *testing.T) {
+		_, err := Parse("12.345")
+		assert.ErrorIs(t, err, ErrInvalidAmount)
+	})
+
+	t.Run("rejects malformed amounts", func(t *testing.T) {
+		for _, text := range []string{"", ".", "abc", "1.2.3", "1e3", "--1", "1234567890123"} {
+			_, err := Parse(text)
+			assert.ErrorIs(t, err, ErrInvalidAmount, text)
+		}
+	})
+}
+
+func TestFromFloat(t *testing.T) {
+	t.Run("rounds half away from zero", func(t *testing.T) {
+		for f, want := range map[float64]Money{1.005: 101, 2.675: 268, -1.005: -101, 0.1 + 0.2: 30, 12.25: 1225} {
+			got, err := FromFloat(f)
+			require.NoError(t, err)
+			assert.Equal(t, want, got, f)
+		}
+	})
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "12.50", Money(1250).String())
+	assert.Equal(t, "0.05", Money(5).String())
+	assert.Equal(t, "-0.05", Money(-5).String())
+	assert.Equal(t, "0.082500", MustParseRate("0.0825").String())
+}
+
+func TestMulRate(t *testing.T) {
+	t.Run("rounds half away from zero", func(t *testing.T) {
+		// 8.25% of 10.10 is 0.83325
+		assert.Equal(t, Money(83), MustParse("10.10").MulRate(MustParseRate("0.0825")))
+		// 15% of 0.10 is 0.015
+		assert.Equal(t, Money(2), MustParse("0.10").MulRate(MustParseRate("0.15")))
+		assert.Equal(t, Money(-2), MustParse("-0.10").MulRate(MustParseRate("0.15")))
+	})
+
+	t.Run("does not overflow on large rates", func(t *testing.T) {
+		assert.Equal(t, Money(150_000_000_000_000), MustParse("10000000000").MulRate(MustParseRate("150")))
+	})
+}
+
+func TestConvert(t *testing.T) {
+	// With USD as the base, 1 USD buys 0.92 EUR and 149.5 JPY
+	eur, jpy := MustParseRate("0.92"), MustParseRate("149.5")
+	assert.Equal(t, MustParse("100"), MustParse("92").Convert(eur, MustParseRate("1")))
+	assert.Equal(t, MustParse("16250"), MustParse("100").Convert(eur, jpy))
+	// 10 JPY is 0.0615... EUR
+	assert.Equal(t, Money(6), MustParse("10").Convert(jpy, eur))
+}
+
+func TestAllocate(t *testing.T) {
+	t.Run("parts add up to the whole", func(t *testing.T) {
+		parts := MustParse("10").Allocate([]Money{100, 100, 100})
+		assert.Equal(t, []Money{334, 333, 333}, parts)
+	})
+
+	t.Run("splits in proportion to weights", func(t *testing.T) {
+		parts := MustParse("3").Allocate([]Money{MustParse("10"), MustParse("20")})
+		assert.Equal(t, []Money{100, 200}, parts)
+	})
+
+	t.Run("gives leftover cents to the largest remainders", func(t *testing.T) {
+		parts := Money(5).Allocate([]Money{1, 3, 3})
+		assert.Equal(t, []Money{1, 2, 2}, parts)
+	})
+
+	t.Run("keeps the sign of negative amounts", func(t *testing.T) {
+		parts := Money(-100).Allocate([]Money{1, 2})
+		assert.Equal(t, []Money{-33, -67}, parts)
+	})
+
+	t.Run("without weights the first part takes it all", func(t *testing.T) {
+		assert.Equal(t, []Money{100, 0}, Money(100).Allocate([]Money{0, 0}))
+		assert.Empty(t, Money(100).Allocate(nil))
+	})
+}
+
+func TestJSON(t *testing.T) {
+	type product struct {
+		Price Money `json:"price"`
+		Rate  Rate  `json:"rate"`
+	}
+
+	t.Run("encodes decimal numbers", func(t *testing.T) {
+		data, err := json.Marshal(product{Price: 1250, Rate: MustParseRate("0.2")})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"price": 12.50, "rate": 0.2}`, string(data))
+	})
+
+	t.Run("decodes numbers and strings", func(t *testing.T) {
+		var p product
+		require.NoError(t, json.Unmarshal([]byte(`{"price": 12.5, "rate": "0.0825"}`), &p))
+		assert.Equal(t, Money(1250), p.Price)
+		assert.Equal(t, Rate(82500), p.Rate)
+	})
+
+	t.Run("rejects sub-cent amounts", func(t *testing.T) {
+		var p product
+		assert.Error(t, json.Unmarshal([]byte(`{"price": 12.505}`), &p))
+	})
+}
+
+func TestScan(t *testing.T) {
+	var m Money
+	require.NoError(t, m.Scan([]byte("12.34")))
+	assert.Equal(t, Money(1234), m)
+	require.NoError(t, m.Scan(12.345))
+	assert.Equal(t, Money(1235), m)
+	require.NoError(t, m.Scan(int64(7)))
+	assert.Equal(t, Money(700), m)
+	assert.Error(t, m.Scan(true))
+
+	value, err := Money(1234).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "12.34", value)
+}
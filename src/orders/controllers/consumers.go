@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"github.com/svadikari/golang_fiber_orders/src/orders/schemas"
+	"github.com/svadikari/golang_fiber_orders/src/orders/services"
+	"github.com/svadikari/golang_fiber_orders/src/serialization"
+)
+
+type orderEventHandlers struct {
+	workflow   services.OrderWorkflow
+	serializer *serialization.EventSerializer
+}
+
+// RegisterEventHandlers subscribes the order workflow to the order events it reacts to.
+func RegisterEventHandlers(registry *events.Registry, workflow services.OrderWorkflow, serializer *serialization.EventSerializer) {
+	h := &orderEventHandlers{workflow: workflow, serializer: serializer}
+	registry.Register(schemas.EventOrderCreated, h.confirmPayment)
+}
+
+func (h *orderEventHandlers) confirmPayment(ctx context.Context, msg events.Message) error {
+	ctx, orderEvent, err := h.decodeOrderEvent(ctx, msg)
+	if err != nil {
+		return err
+	}
+	return h.workflow.ConfirmPayment(ctx, msg, orderEvent.OrderID)
+}
+
+// decodeOrderEvent unwraps the CloudEvents envelope in whichever format it was published and carries
+// its correlation ID into the returned context, so events caused by this one are correlated with the
+// original request.
+func (h *orderEventHandlers) decodeOrderEvent(ctx context.Context, msg events.Message) (context.Context, schemas.OrderEvent, error) {
+	var orderEvent schemas.OrderEvent
+	event, err := h.serializer.Deserialize(msg)
+	if err != nil {
+		return ctx, orderEvent, events.Permanent(err)
+	}
+	if err := h.serializer.DecodeData(event, &orderEvent); err != nil {
+		return ctx, orderEvent, events.Permanent(err)
+	}
+	if orderEvent.OrderID == 0 {
+		return ctx, orderEvent, events.Permanent(errors.New("order event without order id"))
+	}
+	if event.CorrelationID != "" {
+		ctx = events.WithCorrelationID(ctx, event.CorrelationID)
+	}
+	return ctx, orderEvent, nil
+}
@@ -1,47 +1,154 @@
 package controllers
 
 import (
+	"errors"
 	"log/slog"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/svadikari/golang_fiber_orders/src/currency"
+	"github.com/svadikari/golang_fiber_orders/src/events"
 	"github.com/svadikari/golang_fiber_orders/src/middleware"
 	"github.com/svadikari/golang_fiber_orders/src/orders/models"
 	"github.com/svadikari/golang_fiber_orders/src/orders/schemas"
-	"gorm.io/gorm"
+	"github.com/svadikari/golang_fiber_orders/src/orders/services"
+	"github.com/svadikari/golang_fiber_orders/src/pagination"
 )
 
-// Get all orders
+type OrderController interface {
+	GetOrders(c *fiber.Ctx) error
+	GetOrderSummary(c *fiber.Ctx) error
+	CreateOrders(c *fiber.Ctx) error
+	UpdateOrder(c *fiber.Ctx) error
+	GetOrder(c *fiber.Ctx) error
+	DeleteOrder(c *fiber.Ctx) error
+	GetOrderHistory(c *fiber.Ctx) error
+	GetShipments(c *fiber.Ctx) error
+	CreateShipment(c *fiber.Ctx) error
+	DeliverShipment(c *fiber.Ctx) error
+	StartConsumer(c *fiber.Ctx) error
+	StopConsumer(c *fiber.Ctx) error
+	ConsumerStatus(c *fiber.Ctx) error
+	ProducerStats(c *fiber.Ctx) error
+}
+
+type orderController struct {
+	orderService services.OrderService
+	publisher    events.EventPublisher
+}
+
+func NewOrderController(orderService services.OrderService, publisher events.EventPublisher) OrderController {
+	return &orderController{orderService: orderService, publisher: publisher}
+}
+
+// Get orders
 //
-//	@Summary		Get all orders
-//	@Description	Retrieve a list of all orders
+//	@Summary		Get orders
+//	@Description	Retrieve a page of orders matching the filters. Pass next_cursor back as cursor to fetch the next page
 //	@Tags			Orders
 //	@Produce		json
-//	@Success		200	{array}	models.Order
+//	@Param			user_id			query		int		false	"Only orders of this user"
+//	@Param			status			query		string	false	"Only orders in this status"	Enums(NEW, CONFIRMED, PARTIALLY_SHIPPED, SHIPPED, PARTIALLY_DELIVERED, DELIVERED, CANCELLED, RETURNED)
+//	@Param			currency		query		string	false	"Only orders placed in this currency"
+//	@Param			product_id		query		int		false	"Only orders containing this product"
+//	@Param			created_from	query		string	false	"Created at or after (RFC 3339)"
+//	@Param			created_to		query		string	false	"Created at or before (RFC 3339)"
+//	@Param			updated_from	query		string	false	"Updated at or after (RFC 3339)"
+//	@Param			updated_to		query		string	false	"Updated at or before (RFC 3339)"
+//	@Param			min_total		query		number	false	"Minimum total amount"
+//	@Param			max_total		query		number	false	"Maximum total amount"
+//	@Param			sort			query		string	false	"Sort key, prefixed with - for descending order"	Enums(id, -id, created_at, -created_at, updated_at, -updated_at, total_amount, -total_amount)	default(-created_at)
+//	@Param			limit			query		int		false	"Page size"											minimum(1)																						maximum(100)	default(20)
+//	@Param			offset			query		int		false	"Number of orders to skip"
+//	@Param			cursor			query		string	false	"next_cursor of the previous page"
+//	@Success		200				{object}	pagination.Page[models.Order]
+//	@Failure		400				{object}	middleware.GlobalErrorHandlerResp
 //	@Router			/orders [get]
-func GetOrders(c *fiber.Ctx) error {
-	var orders []models.Order
-	db := c.Locals("db").(*gorm.DB)
-	db.Preload("OrderItems").Find(&orders)
-	return c.Status(fiber.StatusOK).JSON(orders)
+func (oc *orderController) GetOrders(c *fiber.Ctx) error {
+	var query schemas.OrderQuerySchema
+	if err := c.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	validationErrs := middleware.NewStructValidator().Validate(query)
+	if len(validationErrs) > 0 {
+		return fiber.NewError(fiber.StatusBadRequest, strings.Join(validationErrs, ","))
+	}
+	if query.Cursor != "" && query.Offset > 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "cursor cannot be combined with offset")
+	}
+
+	page, err := oc.orderService.GetOrders(c.UserContext(), query)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return fiber.NewError(fiber.StatusBadRequest, "cursor is invalid or was issued for another sort order")
+	}
+	if err != nil {
+		c.Locals("logger").(*slog.Logger).Error("Failed to fetch orders", "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch orders")
+	}
+	return c.Status(fiber.StatusOK).JSON(page)
+}
+
+// Order summary
+//
+//	@Summary		Order summary
+//	@Description	Count and total the orders matching the filters per currency and converted into the report currency with the stored exchange rates
+//	@Tags			Orders
+//	@Produce		json
+//	@Param			report_currency	query		string	false	"ISO 4217 currency of the converted totals. Defaults to DEFAULT_CURRENCY"
+//	@Param			user_id			query		int		false	"Only orders of this user"
+//	@Param			status			query		string	false	"Only orders in this status"	Enums(NEW, CONFIRMED, PARTIALLY_SHIPPED, SHIPPED, PARTIALLY_DELIVERED, DELIVERED, CANCELLED, RETURNED)
+//	@Param			currency		query		string	false	"Only orders placed in this currency"
+//	@Param			product_id		query		int		false	"Only orders containing this product"
+//	@Param			created_from	query		string	false	"Created at or after (RFC 3339)"
+//	@Param			created_to		query		string	false	"Created at or before (RFC 3339)"
+//	@Param			updated_from	query		string	false	"Updated at or after (RFC 3339)"
+//	@Param			updated_to		query		string	false	"Updated at or before (RFC 3339)"
+//	@Param			min_total		query		number	false	"Minimum total amount in the order currency"
+//	@Param			max_total		query		number	false	"Maximum total amount in the order currency"
+//	@Success		200				{object}	schemas.OrderSummary
+//	@Failure		400				{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		422				{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/orders/summary [get]
+func (oc *orderController) GetOrderSummary(c *fiber.Ctx) error {
+	var query schemas.OrderSummaryQuerySchema
+	if err := c.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	validationErrs := middleware.NewStructValidator().Validate(query)
+	if len(validationErrs) > 0 {
+		return fiber.NewError(fiber.StatusBadRequest, strings.Join(validationErrs, ","))
+	}
+
+	summary, err := oc.orderService.GetOrderSummary(c.UserContext(), query)
+	if errors.Is(err, currency.ErrNoRate) {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+	}
+	if err != nil {
+		c.Locals("logger").(*slog.Logger).Error("Failed to summarize orders", "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to summarize orders")
+	}
+	return c.Status(fiber.StatusOK).JSON(summary)
 }
 
 // Create Order
 //
 //	@Summary		Create Order
-//	@Description	Creates a new order
+//	@Description	Creates a new order priced from the product catalog in the order currency, discounted by the coupon, taxed in the tax region, plus the cost of the shipping method, and reserves stock for every order line
 //	@Tags			Orders
 //	@Produce		json
 //	@Accept			json
-//	@Param			order	body		schemas.OrderSchema	true	"Order payload"
-//	@Success		200		{object}	models.Order
+//	@Param			order			body		schemas.OrderSchema	true	"Order payload"
+//	@Param			Idempotency-Key	header		string				false	"Makes retries return the original response instead of creating another order"
+//	@Success		200				{object}	models.Order
 //
-//	@Failure		400		{object}	middleware.GlobalErrorHandlerResp
-//	@Failure		404		{object}	middleware.GlobalErrorHandlerResp
-//	@Failure		500		{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		400				{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		404				{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		409				{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		422				{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500				{object}	middleware.GlobalErrorHandlerResp
 //
 //	@Router			/orders [post]
-func CreateOrders(c *fiber.Ctx) error {
+func (oc *orderController) CreateOrders(c *fiber.Ctx) error {
 	var orderSchema schemas.OrderSchema
 	log := c.Locals("logger").(*slog.Logger)
 
@@ -54,34 +161,18 @@ func CreateOrders(c *fiber.Ctx) error {
 	if len(validationErrs) > 0 {
 		return fiber.NewError(fiber.StatusBadRequest, strings.Join(validationErrs, ","))
 	}
-	order := models.Order{Status: string(orderSchema.Status)}
-	var totalAmount float64
-	for _, item := range orderSchema.OrderItems {
-		orderItem := models.OrderItem{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-			UnitPrice: item.UnitPrice,
-		}
-		totalAmount = float64(item.Quantity) * item.UnitPrice
-		order.OrderItems = append(order.OrderItems, orderItem)
-	}
-	order.TotalAmount = totalAmount
 
-	// Save the order to the database
-	db := c.Locals("db").(*gorm.DB)
-	result := db.Save(&order)
-	if result.Error != nil {
-		log.Error("Failed to create order in the database", "error", result.Error)
-		return fiber.NewError(fiber.StatusInternalServerError, "Failed to create order"+result.Error.Error())
+	order, err := oc.orderService.CreateOrder(c.UserContext(), orderSchema)
+	if err != nil {
+		return OrderError(log, err, "Failed to create order")
 	}
-	go middleware.PublishOrder(&order, log)
 	return c.Status(fiber.StatusOK).JSON(order)
 }
 
 // Update Order
 //
 //	@Summary		Updaate Order
-//	@Description	Update an existing order by ID
+//	@Description	Update the status of an order by ID. Moving it to SHIPPED ships every line not shipped yet and requires the tracking_number and carrier; moving it to DELIVERED delivers every shipment
 //	@Tags			Orders
 //	@Produce		json
 //	@Accept			json
@@ -93,10 +184,11 @@ func CreateOrders(c *fiber.Ctx) error {
 //
 //	@Failure		400		{object}	middleware.GlobalErrorHandlerResp
 //	@Failure		404		{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		409		{object}	middleware.GlobalErrorHandlerResp
 //	@Failure		500		{object}	middleware.GlobalErrorHandlerResp
 //
 //	@Router			/orders/{id} [put]
-func UpdateOrder(c *fiber.Ctx) error {
+func (oc *orderController) UpdateOrder(c *fiber.Ctx) error {
 
 	log := c.Locals("logger").(*slog.Logger)
 	orderId, err := c.ParamsInt("id")
@@ -110,26 +202,49 @@ func UpdateOrder(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "Cannot parse JSON")
 	}
 
-	if orderSchema.Status == "" {
-		log.Error("Invalid status value: empty")
-		return fiber.NewError(fiber.StatusBadRequest, "Invalid status value: empty")
+	validationErrs := middleware.NewStructValidator().Validate(orderSchema)
+	if len(validationErrs) > 0 {
+		log.Error("Invalid order update payload", "errors", validationErrs)
+		return fiber.NewError(fiber.StatusBadRequest, strings.Join(validationErrs, ","))
 	}
 
 	log.Info("Order ID to be updated: ", "orderId", orderId)
-	// Parse the request body to get updated order details
+	order, err := oc.orderService.UpdateOrderStatus(c.UserContext(), uint(orderId), orderSchema)
+	if err != nil {
+		return OrderError(log, err, "Failed to update order")
+	}
+	return c.Status(fiber.StatusOK).JSON(order)
+}
 
-	db := c.Locals("db").(*gorm.DB)
-	var order models.Order
-	db.Preload("OrderItems").First(&order, orderId)
-	if order.ID == 0 {
-		return fiber.NewError(fiber.StatusNotFound, "Order not found")
+// Order status history
+//
+//	@Summary		Order status history
+//	@Description	Fetch the status change timeline of an order
+//	@Tags			Orders
+//	@Produce		json
+//
+//	@param			id	path		int	true	"Order ID"
+//
+//	@Success		200	{array}		models.OrderStatusHistory
+//
+//	@Failure		400	{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		404	{object}	middleware.GlobalErrorHandlerResp
+//
+//	@Router			/orders/{id}/history [get]
+func (oc *orderController) GetOrderHistory(c *fiber.Ctx) error {
+	log := c.Locals("logger").(*slog.Logger)
+	orderId, err := c.ParamsInt("id")
+	if err != nil {
+		log.Error("Invalid order ID parameter", "error", err)
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
 	}
 
-	order.Status = string(orderSchema.Status)
-	log.Info("Updated order details: ", "order", order)
-	// Save the order to the database
-	db.Save(&order)
-	return c.Status(fiber.StatusOK).JSON(order)
+	var history []models.OrderStatusHistory
+	history, err = oc.orderService.GetOrderHistory(c.UserContext(), uint(orderId))
+	if err != nil {
+		return OrderError(log, err, "Failed to fetch order history")
+	}
+	return c.Status(fiber.StatusOK).JSON(history)
 }
 
 // Fetch Order
@@ -142,31 +257,26 @@ func UpdateOrder(c *fiber.Ctx) error {
 //
 //	@param			id	path		int	true	"Order ID"
 //
-//	@Success		200	{object}	models.Order
+//	@Success		200	{object}	services.OrderResponse
 //
 //	@Failure		400	{object}	middleware.GlobalErrorHandlerResp
 //	@Failure		404	{object}	middleware.GlobalErrorHandlerResp
 //	@Failure		500	{object}	middleware.GlobalErrorHandlerResp
 //
 //	@Router			/orders/{id} [get]
-func GetOrder(c *fiber.Ctx) error {
+func (oc *orderController) GetOrder(c *fiber.Ctx) error {
 	log := c.Locals("logger").(*slog.Logger)
 	orderId, err := c.ParamsInt("id")
 	if err != nil {
 		log.Error("Invalid order ID parameter", "error", err)
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
 	}
-	// Parse the request body to get updated order details
 
-	db := c.Locals("db").(*gorm.DB)
-	var order models.Order
-
-	db.Preload("OrderItems").First(&order, uint(orderId))
-	if order.ID == 0 {
-		log.Error("Order not found", "orderId", orderId)
-		return fiber.NewError(fiber.StatusNotFound, "Order not found")
+	order, err := oc.orderService.GetOrder(c.UserContext(), uint(orderId))
+	if err != nil {
+		return OrderError(log, err, "Failed to fetch order")
 	}
-	return c.Status(fiber.StatusOK).JSON(OrderResponse{Order: order, User: populateUserDetails(order.UserId)})
+	return c.Status(fiber.StatusOK).JSON(order)
 }
 
 // Delete Order
@@ -186,36 +296,145 @@ func GetOrder(c *fiber.Ctx) error {
 //	@Failure		500	{object}	middleware.GlobalErrorHandlerResp
 //
 //	@Router			/orders/{id} [delete]
-func DeleteOrder(c *fiber.Ctx) error {
+func (oc *orderController) DeleteOrder(c *fiber.Ctx) error {
 	log := c.Locals("logger").(*slog.Logger)
 	orderId, err := c.ParamsInt("id")
 	if err != nil {
 		log.Error("Invalid order ID parameter", "error", err)
 		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
 	}
-	// Parse the request body to get updated order details
 
-	db := c.Locals("db").(*gorm.DB)
-	var order models.Order
-	db.First(&order, orderId)
-	if order.ID == 0 {
-		log.Error("Order not found", "orderId", orderId)
-		return fiber.NewError(fiber.StatusNotFound, "Order not found")
+	if err := oc.orderService.DeleteOrder(c.UserContext(), uint(orderId)); err != nil {
+		return OrderError(log, err, "Failed to delete order")
 	}
-	db.Delete(&order)
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+// Order shipments
+//
+//	@Summary		Order shipments
+//	@Description	List the shipments of an order with the quantity of every order line they carry
+//	@Tags			Orders
+//	@Produce		json
+//
+//	@param			id	path		int	true	"Order ID"
+//
+//	@Success		200	{array}		models.Shipment
+//
+//	@Failure		400	{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		404	{object}	middleware.GlobalErrorHandlerResp
+//
+//	@Router			/orders/{id}/shipments [get]
+func (oc *orderController) GetShipments(c *fiber.Ctx) error {
+	log := c.Locals("logger").(*slog.Logger)
+	orderId, err := c.ParamsInt("id")
+	if err != nil {
+		log.Error("Invalid order ID parameter", "error", err)
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	var shipments []models.Shipment
+	shipments, err = oc.orderService.GetShipments(c.UserContext(), uint(orderId))
+	if err != nil {
+		return OrderError(log, err, "Failed to fetch shipments")
+	}
+	return c.Status(fiber.StatusOK).JSON(shipments)
+}
+
+// Create Shipment
+//
+//	@Summary		Create Shipment
+//	@Description	Ship quantities of the order lines. The order moves to PARTIALLY_SHIPPED until every line has shipped and then to SHIPPED
+//	@Tags			Orders
+//	@Produce		json
+//	@Accept			json
+//
+//	@param			id			path		int						true	"Order ID"
+//	@Param			shipment	body		schemas.ShipmentSchema	true	"Shipment payload"
+//
+//	@Success		201			{object}	models.Shipment
+//
+//	@Failure		400			{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		404			{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		409			{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		422			{object}	middleware.GlobalErrorHandlerResp
+//
+//	@Router			/orders/{id}/shipments [post]
+func (oc *orderController) CreateShipment(c *fiber.Ctx) error {
+	log := c.Locals("logger").(*slog.Logger)
+	orderId, err := c.ParamsInt("id")
+	if err != nil {
+		log.Error("Invalid order ID parameter", "error", err)
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	var shipmentSchema schemas.ShipmentSchema
+	if err := c.BodyParser(&shipmentSchema); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Cannot parse JSON")
+	}
+	validationErrs := middleware.NewStructValidator().Validate(shipmentSchema)
+	if len(validationErrs) > 0 {
+		return fiber.NewError(fiber.StatusBadRequest, strings.Join(validationErrs, ","))
+	}
+
+	shipment, err := oc.orderService.CreateShipment(c.UserContext(), uint(orderId), shipmentSchema)
+	if err != nil {
+		return OrderError(log, err, "Failed to create shipment")
+	}
+	return c.Status(fiber.StatusCreated).JSON(shipment)
+}
+
+// Deliver Shipment
+//
+//	@Summary		Deliver Shipment
+//	@Description	Mark a shipment delivered. The order moves to PARTIALLY_DELIVERED until every line has been delivered and then to DELIVERED
+//	@Tags			Orders
+//	@Produce		json
+//
+//	@param			id			path		int	true	"Order ID"
+//	@param			shipmentId	path		int	true	"Shipment ID"
+//
+//	@Success		200			{object}	models.Shipment
+//
+//	@Failure		400			{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		404			{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		409			{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		422			{object}	middleware.GlobalErrorHandlerResp
+//
+//	@Router			/orders/{id}/shipments/{shipmentId}/deliver [post]
+func (oc *orderController) DeliverShipment(c *fiber.Ctx) error {
+	log := c.Locals("logger").(*slog.Logger)
+	orderId, err := c.ParamsInt("id")
+	if err != nil {
+		log.Error("Invalid order ID parameter", "error", err)
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid order ID")
+	}
+	shipmentId, err := c.ParamsInt("shipmentId")
+	if err != nil {
+		log.Error("Invalid shipment ID parameter", "error", err)
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid shipment ID")
+	}
+
+	shipment, err := oc.orderService.DeliverShipment(c.UserContext(), uint(orderId), uint(shipmentId))
+	if err != nil {
+		return OrderError(log, err, "Failed to deliver shipment")
+	}
+	return c.Status(fiber.StatusOK).JSON(shipment)
+}
+
 // Start Kafka Consumer
 //
 //	@Summary		Start Kafka Consumer
 //	@Description	Start the Kafka consumer to process orders
 //	@Tags			Orders
 //	@Success		200	{object}	fiber.Map	"Kafka consumer started successfully"
+//	@Failure		409	{object}	middleware.GlobalErrorHandlerResp
 //
-//	@Router			/orders/consumer/start [get]
-func StartConsumer(c *fiber.Ctx) error {
-	middleware.StartKafkaConsumer()
+//	@Router			/orders/consumer/start [post]
+func (oc *orderController) StartConsumer(c *fiber.Ctx) error {
+	if err := middleware.StartKafkaConsumer(); err != nil {
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Kafka consumer started successfully",
 	})
@@ -224,23 +443,84 @@ func StartConsumer(c *fiber.Ctx) error {
 // Stop Kafka Consumer
 //
 //	@Summary		Stop Kafka Consumer
-//	@Description	Stop the Kafka consumer to process orders
+//	@Description	Stop the Kafka consumer and wait until it has committed and left the consumer group
 //	@Tags			Orders
 //	@Success		200	{object}	fiber.Map	"Kafka consumer stopped successfully"
+//	@Failure		409	{object}	middleware.GlobalErrorHandlerResp
 //
-//	@Router			/orders/consumer/stop [get]
-func StopConsumer(c *fiber.Ctx) error {
-	middleware.StopKafkaConsumer()
+//	@Router			/orders/consumer/stop [post]
+func (oc *orderController) StopConsumer(c *fiber.Ctx) error {
+	if err := middleware.StopKafkaConsumer(); err != nil {
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	}
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Kafka consumer stopped successfully",
 	})
 }
 
-func populateUserDetails(userId uint) interface{} {
-	return middleware.NewUserService().GetUser(userId)
+// Kafka Consumer Status
+//
+//	@Summary		Kafka Consumer Status
+//	@Description	State, assigned partitions, lag and last error of the Kafka consumer
+//	@Tags			Orders
+//	@Produce		json
+//	@Success		200	{object}	middleware.ConsumerStatus
+//
+//	@Router			/orders/consumer/status [get]
+func (oc *orderController) ConsumerStatus(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(middleware.KafkaConsumerStatus())
+}
+
+// Event Producer Stats
+//
+//	@Summary		Event Producer Stats
+//	@Description	Delivery report counters of the shared event publisher
+//	@Tags			Orders
+//	@Produce		json
+//	@Success		200	{object}	events.PublisherStats
+//
+//	@Router			/orders/producer/stats [get]
+func (oc *orderController) ProducerStats(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(oc.publisher.Stats())
 }
 
-type OrderResponse struct {
-	Order models.Order `json:"order"`
-	User  any          `json:"user"`
+// OrderError turns an order service error into an HTTP error. Rejected requests keep their message;
+// failures are logged and reported with fallback.
+func OrderError(log *slog.Logger, err error, fallback string) error {
+	var transitionErr *services.TransitionError
+	var stockErr *services.StockError
+	var currencyErr *services.CurrencyError
+	var couponErr *services.CouponError
+	var shippingErr *services.ShippingError
+	var shipmentErr *services.ShipmentError
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Order not found")
+	case errors.Is(err, services.ErrShipmentNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Shipment not found")
+	case errors.As(err, &transitionErr):
+		log.Warn("Rejected order status transition", "from", transitionErr.From, "to", transitionErr.To)
+		return fiber.NewError(fiber.StatusConflict, transitionErr.Error())
+	case errors.As(err, &stockErr):
+		log.Warn("Order rejected", "error", stockErr.Error())
+		if stockErr.ProductNotFound {
+			return fiber.NewError(fiber.StatusNotFound, stockErr.Error())
+		}
+		return fiber.NewError(fiber.StatusConflict, stockErr.Error())
+	case errors.As(err, &currencyErr):
+		log.Warn("Order rejected", "error", currencyErr.Error())
+		return fiber.NewError(fiber.StatusUnprocessableEntity, currencyErr.Error())
+	case errors.As(err, &couponErr):
+		log.Warn("Order rejected", "error", couponErr.Error())
+		return fiber.NewError(fiber.StatusUnprocessableEntity, couponErr.Error())
+	case errors.As(err, &shippingErr):
+		log.Warn("Order rejected", "error", shippingErr.Error())
+		return fiber.NewError(fiber.StatusUnprocessableEntity, shippingErr.Error())
+	case errors.As(err, &shipmentErr):
+		log.Warn("Shipment rejected", "error", shipmentErr.Error())
+		return fiber.NewError(fiber.StatusUnprocessableEntity, shipmentErr.Error())
+	default:
+		log.Error(fallback, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, fallback)
+	}
 }
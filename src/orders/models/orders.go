@@ -1,19 +1,100 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/money"
+	"gorm.io/gorm"
+)
 
 type Order struct {
 	gorm.Model
-	UserId      uint        `json:"user_id" gorm:"not null;column:user_id;index:idx_user_id"`
-	TotalAmount float64     `json:"total_amount" gorm:"column:total_amount;not null;check:total_amount >= 0.1"`
-	Status      string      `json:"status" gorm:"column:status;not null;size:100 enum:'NEW','SHIPPED','DELIVERED','CANCELLED' default:'NEW'"`
-	OrderItems  []OrderItem `json:"order_items" gorm:"foreignKey:OrderID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	UserId uint `json:"user_id" gorm:"not null;column:user_id;index:idx_user_id"`
+	// Subtotal is the amount after discount and before tax; TotalAmount is Subtotal plus TaxAmount plus ShippingCost.
+	Subtotal    money.Money `json:"subtotal" gorm:"column:subtotal;type:numeric(12,2);not null;default:0"`
+	TaxAmount   money.Money `json:"tax_amount" gorm:"column:tax_amount;type:numeric(12,2);not null;default:0"`
+	TotalAmount money.Money `json:"total_amount" gorm:"column:total_amount;type:numeric(12,2);not null;check:total_amount >= 0"`
+	// DiscountAmount is the sum of the line discounts of the redeemed coupon.
+	DiscountAmount money.Money `json:"discount_amount" gorm:"column:discount_amount;type:numeric(12,2);not null;default:0"`
+	CouponCode     string      `json:"coupon_code,omitempty" gorm:"column:coupon_code;size:50;not null;default:''"`
+	PromotionID    *uint       `json:"promotion_id,omitempty" gorm:"column:promotion_id"`
+	// TaxRegion is the region the order was taxed in; orders without one are not taxed.
+	TaxRegion string `json:"tax_region" gorm:"column:tax_region;size:6;not null;default:''"`
+	// TaxMode tells whether the line totals excluded or included tax when the order was placed.
+	TaxMode  string         `json:"tax_mode" gorm:"column:tax_mode;size:10;not null;default:'exclusive'"`
+	TaxLines []OrderTaxLine `json:"tax_lines" gorm:"foreignKey:OrderID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	// Currency is the ISO 4217 currency the order was placed and priced in.
+	Currency   string      `json:"currency" gorm:"column:currency;type:char(3);not null;default:'USD'"`
+	Status     string      `json:"status" gorm:"column:status;not null;type:order_status;default:'NEW'"`
+	OrderItems []OrderItem `json:"order_items" gorm:"foreignKey:OrderID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	// ShippingAddress and BillingAddress are empty for orders placed without a shipping method.
+	ShippingAddress Address `json:"shipping_address" gorm:"embedded;embeddedPrefix:shipping_"`
+	BillingAddress  Address `json:"billing_address" gorm:"embedded;embeddedPrefix:billing_"`
+	// ShippingMethod is the code of the shipping method and ShippingCost its cost in the order currency,
+	// waived by free shipping promotions. Shipping is not taxed.
+	ShippingMethod string      `json:"shipping_method" gorm:"column:shipping_method;size:50;not null;default:''"`
+	ShippingCost   money.Money `json:"shipping_cost" gorm:"column:shipping_cost;type:numeric(12,2);not null;default:0"`
+	// TrackingNumber and Carrier are those of the latest shipment; ShippedAt is set once every line has shipped.
+	TrackingNumber string     `json:"tracking_number,omitempty" gorm:"column:tracking_number;size:100;not null;default:''"`
+	Carrier        string     `json:"carrier,omitempty" gorm:"column:carrier;size:50;not null;default:''"`
+	ShippedAt      *time.Time `json:"shipped_at,omitempty" gorm:"column:shipped_at"`
+	// StockReleased is set once the stock reserved by the order has been returned to the catalog.
+	StockReleased bool `json:"-" gorm:"column:stock_released;not null;default:false"`
 }
 
 type OrderItem struct {
-	ID        uint    `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
-	OrderID   uint    `json:"-" gorm:"not null;column:order_id;index:idx_order_id"`
-	ProductID uint    `json:"product_id" gorm:"not null;column:product_id;index:idx_product_id"`
-	Quantity  int     `json:"quantity" gorm:"column:quantity;not null;check:quantity > 0"`
-	UnitPrice float64 `json:"unit_price" gorm:"column:unit_price;not null;check:unit_price >= 0.1"`
+	ID        uint        `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	OrderID   uint        `json:"-" gorm:"not null;column:order_id;index:idx_order_id"`
+	ProductID uint        `json:"product_id" gorm:"not null;column:product_id;index:idx_product_id"`
+	Quantity  int         `json:"quantity" gorm:"column:quantity;not null;check:quantity > 0"`
+	UnitPrice money.Money `json:"unit_price" gorm:"column:unit_price;type:numeric(12,2);not null;check:unit_price >= 0.1"`
+	// TaxCategory is the tax category of the product when the order was placed.
+	TaxCategory string      `json:"tax_category" gorm:"column:tax_category;size:50;not null;default:'standard'"`
+	TaxAmount   money.Money `json:"tax_amount" gorm:"column:tax_amount;type:numeric(12,2);not null;default:0"`
+	// DiscountAmount is taken off the line total, UnitPrice times Quantity, before tax.
+	DiscountAmount money.Money `json:"discount_amount" gorm:"column:discount_amount;type:numeric(12,2);not null;default:0"`
+	// ShippedQuantity and DeliveredQuantity count the units of the line in shipments and in delivered
+	// shipments; FulfillmentStatus is derived from them.
+	ShippedQuantity   int    `json:"shipped_quantity" gorm:"column:shipped_quantity;not null;default:0"`
+	DeliveredQuantity int    `json:"delivered_quantity" gorm:"column:delivered_quantity;not null;default:0"`
+	FulfillmentStatus string `json:"fulfillment_status" gorm:"column:fulfillment_status;size:20;not null;default:'UNFULFILLED'"`
+}
+
+// Address is a postal address stored in the columns of its order.
+type Address struct {
+	Name       string `json:"name" gorm:"column:name;size:200;not null;default:''"`
+	Line1      string `json:"line1" gorm:"column:line1;size:200;not null;default:''"`
+	Line2      string `json:"line2,omitempty" gorm:"column:line2;size:200;not null;default:''"`
+	City       string `json:"city" gorm:"column:city;size:100;not null;default:''"`
+	Region     string `json:"region,omitempty" gorm:"column:region;size:100;not null;default:''"`
+	PostalCode string `json:"postal_code" gorm:"column:postal_code;size:20;not null;default:''"`
+	// Country is an ISO 3166-1 alpha-2 country code.
+	Country string `json:"country" gorm:"column:country;size:2;not null;default:''"`
+}
+
+// OrderTaxLine totals one tax rate across the order lines it applies to.
+type OrderTaxLine struct {
+	ID            uint        `json:"-" gorm:"primaryKey;autoIncrement;column:id"`
+	OrderID       uint        `json:"-" gorm:"not null;column:order_id;index:idx_order_tax_lines_order_id"`
+	Name          string      `json:"name" gorm:"column:name;size:100;not null"`
+	Region        string      `json:"region" gorm:"column:region;size:6;not null"`
+	Category      string      `json:"category" gorm:"column:category;size:50;not null"`
+	Rate          money.Rate  `json:"rate" gorm:"column:rate;type:numeric(12,6);not null"`
+	TaxableAmount money.Money `json:"taxable_amount" gorm:"column:taxable_amount;type:numeric(12,2);not null"`
+	TaxAmount     money.Money `json:"tax_amount" gorm:"column:tax_amount;type:numeric(12,2);not null"`
+}
+
+// OrderStatusHistory records every status change of an order, including the initial NEW status.
+type OrderStatusHistory struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	OrderID    uint      `json:"order_id" gorm:"not null;column:order_id;index:idx_status_history_order_id"`
+	FromStatus string    `json:"from_status" gorm:"column:from_status;size:100"`
+	ToStatus   string    `json:"to_status" gorm:"column:to_status;not null;size:100"`
+	ChangedBy  string    `json:"changed_by" gorm:"column:changed_by;not null;size:100"`
+	Reason     string    `json:"reason" gorm:"column:reason;size:500"`
+	CreatedAt  time.Time `json:"created_at" gorm:"column:created_at;not null"`
+}
+
+func (OrderStatusHistory) TableName() string {
+	return "order_status_history"
 }
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Shipment is a parcel carrying some quantity of one or more lines of an order.
+type Shipment struct {
+	ID             uint           `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	OrderID        uint           `json:"order_id" gorm:"not null;column:order_id;index:idx_shipments_order_id"`
+	TrackingNumber string         `json:"tracking_number" gorm:"column:tracking_number;size:100;not null"`
+	Carrier        string         `json:"carrier" gorm:"column:carrier;size:50;not null"`
+	Status         string         `json:"status" gorm:"column:status;size:20;not null"`
+	Items          []ShipmentItem `json:"items" gorm:"foreignKey:ShipmentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	ShippedAt      time.Time      `json:"shipped_at" gorm:"column:shipped_at;not null"`
+	DeliveredAt    *time.Time     `json:"delivered_at,omitempty" gorm:"column:delivered_at"`
+}
+
+// ShipmentItem is the quantity of an order line carried by a shipment.
+type ShipmentItem struct {
+	ID          uint `json:"-" gorm:"primaryKey;autoIncrement;column:id"`
+	ShipmentID  uint `json:"-" gorm:"not null;column:shipment_id;index:idx_shipment_items_shipment_id"`
+	OrderItemID uint `json:"order_item_id" gorm:"not null;column:order_item_id"`
+	Quantity    int  `json:"quantity" gorm:"column:quantity;not null;check:quantity > 0"`
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"github.com/svadikari/golang_fiber_orders/src/inbox"
+	"github.com/svadikari/golang_fiber_orders/src/orders/models"
+	"github.com/svadikari/golang_fiber_orders/src/orders/schemas"
+	"github.com/svadikari/golang_fiber_orders/src/outbox"
+	"github.com/svadikari/golang_fiber_orders/src/pagination"
+	productModels "github.com/svadikari/golang_fiber_orders/src/products/models"
+	promotionModels "github.com/svadikari/golang_fiber_orders/src/promotions/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrPromotionNotFound = errors.New("promotion not found")
+	ErrShipmentNotFound  = errors.New("shipment not found")
+)
+
+type orderRepository struct {
+	Db *gorm.DB
+}
+
+func NewOrderRepository(db *gorm.DB) OrderRepository {
+	return &orderRepository{Db: db}
+}
+
+func (r *orderRepository) Transaction(ctx context.Context, fn func(tx OrderRepository) error) error {
+	return r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&orderRepository{Db: tx})
+	})
+}
+
+func (r *orderRepository) FindByID(ctx context.Context, id uint) (models.Order, error) {
+	var order models.Order
+	err := r.Db.WithContext(ctx).Preload("OrderItems").Preload("TaxLines").First(&order, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return order, ErrOrderNotFound
+	}
+	return order, err
+}
+
+func (r *orderRepository) LockByID(ctx context.Context, id uint, includeDeleted bool) (models.Order, error) {
+	var order models.Order
+	tx := r.Db.WithContext(ctx)
+	if includeDeleted {
+		tx = tx.Unscoped()
+	}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("OrderItems").Preload("TaxLines").First(&order, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return order, ErrOrderNotFound
+	}
+	return order, err
+}
+
+func (r *orderRepository) History(ctx context.Context, orderId uint) ([]models.OrderStatusHistory, error) {
+	history := []models.OrderStatusHistory{}
+	err := r.Db.WithContext(ctx).Where("order_id = ?", orderId).Order("created_at, id").Find(&history).Error
+	return history, err
+}
+
+func (r *orderRepository) Create(ctx context.Context, order *models.Order) error {
+	return r.Db.WithContext(ctx).Create(order).Error
+}
+
+func (r *orderRepository) Save(ctx context.Context, order *models.Order) error {
+	return r.Db.WithContext(ctx).Save(order).Error
+}
+
+func (r *orderRepository) Delete(ctx context.Context, order *models.Order) error {
+	return r.Db.WithContext(ctx).Delete(order).Error
+}
+
+func (r *orderRepository) AddHistory(ctx context.Context, entry *models.OrderStatusHistory) error {
+	return r.Db.WithContext(ctx).Create(entry).Error
+}
+
+// SaveItem saves an order line without its order.
+func (r *orderRepository) SaveItem(ctx context.Context, item *models.OrderItem) error {
+	return r.Db.WithContext(ctx).Save(item).Error
+}
+
+func (r *orderRepository) Shipments(ctx context.Context, orderId uint) ([]models.Shipment, error) {
+	shipments := []models.Shipment{}
+	err := r.Db.WithContext(ctx).Preload("Items").Where("order_id = ?", orderId).Order("id").Find(&shipments).Error
+	return shipments, err
+}
+
+func (r *orderRepository) FindShipment(ctx context.Context, orderId, shipmentId uint) (models.Shipment, error) {
+	var shipment models.Shipment
+	err := r.Db.WithContext(ctx).Preload("Items").Where("order_id = ?", orderId).First(&shipment, shipmentId).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return shipment, ErrShipmentNotFound
+	}
+	return shipment, err
+}
+
+func (r *orderRepository) CreateShipment(ctx context.Context, shipment *models.Shipment) error {
+	return r.Db.WithContext(ctx).Create(shipment).Error
+}
+
+// SaveShipment saves a shipment without its items, which do not change once shipped.
+func (r *orderRepository) SaveShipment(ctx context.Context, shipment *models.Shipment) error {
+	return r.Db.WithContext(ctx).Omit("Items").Save(shipment).Error
+}
+
+// LockProducts locks the given products for update, in ID order so concurrent orders cannot deadlock,
+// and returns those that exist with their prices.
+func (r *orderRepository) LockProducts(ctx context.Context, ids []uint) (map[uint]productModels.Product, error) {
+	sorted := append([]uint(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	products := make(map[uint]productModels.Product, len(sorted))
+	for _, productId := range sorted {
+		var product productModels.Product
+		err := r.Db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Prices").First(&product, productId).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		products[productId] = product
+	}
+	return products, nil
+}
+
+// LockPromotion loads the promotion of a coupon code with its targets and locks it until the end of the
+// transaction, so concurrent orders cannot redeem it past its usage limit.
+func (r *orderRepository) LockPromotion(ctx context.Context, code string) (promotionModels.Promotion, error) {
+	var promotion promotionModels.Promotion
+	err := r.Db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Products").Preload("Categories").Where("code = ?", code).First(&promotion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return promotion, ErrPromotionNotFound
+	}
+	return promotion, err
+}
+
+// CountRedemptions counts the orders of a user that redeemed a promotion. Cancelled and deleted orders
+// give their redemption back.
+func (r *orderRepository) CountRedemptions(ctx context.Context, promotionId, userId uint) (int64, error) {
+	var count int64
+	err := r.Db.WithContext(ctx).Model(&promotionModels.PromotionRedemption{}).
+		Joins("JOIN orders ON orders.id = promotion_redemptions.order_id AND orders.deleted_at IS NULL").
+		Where("promotion_redemptions.promotion_id = ? AND promotion_redemptions.user_id = ?", promotionId, userId).
+		Where("orders.status <> ?", schemas.StatusCancelled).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *orderRepository) AddRedemption(ctx context.Context, redemption *promotionModels.PromotionRedemption) error {
+	return r.Db.WithContext(ctx).Create(redemption).Error
+}
+
+// AdjustStock adds delta to the stock of a product, including deleted products so returned stock is not lost.
+func (r *orderRepository) AdjustStock(ctx context.Context, productId uint, delta int) error {
+	return r.Db.WithContext(ctx).Unscoped().Model(&productModels.Product{}).Where("id = ?", productId).
+		Update("stock", gorm.Expr("stock + ?", delta)).Error
+}
+
+func (r *orderRepository) MarkStockReleased(ctx context.Context, order *models.Order) error {
+	order.StockReleased = true
+	return r.Db.WithContext(ctx).Unscoped().Model(order).UpdateColumn("stock_released", true).Error
+}
+
+func (r *orderRepository) EnqueueEvent(ctx context.Context, orderId uint, topic string, event events.CloudEvent, value []byte, headers map[string]string) error {
+	return outbox.EnqueueEvent(r.Db.WithContext(ctx), topic, "Order", strconv.FormatUint(uint64(orderId), 10), event, value, headers)
+}
+
+func (r *orderRepository) MarkProcessed(ctx context.Context, consumer string, msg events.Message) (bool, error) {
+	return inbox.MarkProcessed(r.Db.WithContext(ctx), consumer, msg)
+}
+
+type OrderRepository interface {
+	// Transaction runs fn with a repository whose operations all share one database transaction.
+	Transaction(ctx context.Context, fn func(tx OrderRepository) error) error
+	Find(ctx context.Context, query schemas.OrderQuerySchema) (pagination.Page[models.Order], error)
+	TotalsByCurrency(ctx context.Context, filters schemas.OrderFilters) ([]schemas.CurrencyTotal, error)
+	FindByID(ctx context.Context, id uint) (models.Order, error)
+	// LockByID loads an order and locks it until the end of the transaction.
+	LockByID(ctx context.Context, id uint, includeDeleted bool) (models.Order, error)
+	History(ctx context.Context, orderId uint) ([]models.OrderStatusHistory, error)
+	Create(ctx context.Context, order *models.Order) error
+	Save(ctx context.Context, order *models.Order) error
+	Delete(ctx context.Context, order *models.Order) error
+	AddHistory(ctx context.Context, entry *models.OrderStatusHistory) error
+	SaveItem(ctx context.Context, item *models.OrderItem) error
+	Shipments(ctx context.Context, orderId uint) ([]models.Shipment, error)
+	FindShipment(ctx context.Context, orderId, shipmentId uint) (models.Shipment, error)
+	CreateShipment(ctx context.Context, shipment *models.Shipment) error
+	SaveShipment(ctx context.Context, shipment *models.Shipment) error
+	LockProducts(ctx context.Context, ids []uint) (map[uint]productModels.Product, error)
+	AdjustStock(ctx context.Context, productId uint, delta int) error
+	MarkStockReleased(ctx context.Context, order *models.Order) error
+	LockPromotion(ctx context.Context, code string) (promotionModels.Promotion, error)
+	CountRedemptions(ctx context.Context, promotionId, userId uint) (int64, error)
+	AddRedemption(ctx context.Context, redemption *promotionModels.PromotionRedemption) error
+	// EnqueueEvent writes a serialized order event to the outbox.
+	EnqueueEvent(ctx context.Context, orderId uint, topic string, event events.CloudEvent, value []byte, headers map[string]string) error
+	// MarkProcessed records that consumer handled msg and reports whether it is the first time.
+	MarkProcessed(ctx context.Context, consumer string, msg events.Message) (bool, error)
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/orders/models"
+	"github.com/svadikari/golang_fiber_orders/src/orders/schemas"
+	"github.com/svadikari/golang_fiber_orders/src/pagination"
+	"gorm.io/gorm"
+)
+
+// orderSortColumn describes a sortable column: its SQL type, used to compare against cursor values,
+// and how to read the cursor value from an order.
+type orderSortColumn struct {
+	sqlType string
+	value   func(order *models.Order) string
+}
+
+var orderSortColumns = map[string]orderSortColumn{
+	"id": {"bigint", func(order *models.Order) string {
+		return strconv.FormatUint(uint64(order.ID), 10)
+	}},
+	"created_at": {"timestamptz", func(order *models.Order) string {
+		return order.CreatedAt.Format(time.RFC3339Nano)
+	}},
+	"updated_at": {"timestamptz", func(order *models.Order) string {
+		return order.UpdatedAt.Format(time.RFC3339Nano)
+	}},
+	"total_amount": {"numeric", func(order *models.Order) string {
+		return order.TotalAmount.String()
+	}},
+}
+
+// Find returns one page of orders matching query. With a cursor, the page starts after the
+// cursor position (keyset pagination); otherwise it starts at the offset. query must be normalized
+// by the caller: Sort and Limit are set and valid.
+func (r *orderRepository) Find(ctx context.Context, query schemas.OrderQuerySchema) (pagination.Page[models.Order], error) {
+	db := r.Db.WithContext(ctx)
+	page := pagination.Page[models.Order]{Items: []models.Order{}, Limit: query.Limit, Offset: query.Offset}
+	sort := pagination.ParseSort(query.Sort)
+	column := orderSortColumns[sort.Field]
+
+	filters := func(tx *gorm.DB) *gorm.DB { return filterOrders(tx, query.OrderFilters) }
+	if err := db.Model(&models.Order{}).Scopes(filters).Count(&page.Total).Error; err != nil {
+		return page, err
+	}
+
+	direction, comparison := "ASC", ">"
+	if sort.Desc {
+		direction, comparison = "DESC", "<"
+	}
+	tx := db.Scopes(filters).Preload("OrderItems").Preload("TaxLines").
+		Order(fmt.Sprintf("orders.%s %s, orders.id %s", sort.Field, direction, direction)).
+		Limit(page.Limit + 1)
+	if query.Cursor != "" {
+		cursor, err := pagination.DecodeCursor(query.Cursor)
+		if err != nil || cursor.Sort != sort.String() {
+			return page, pagination.ErrInvalidCursor
+		}
+		tx = tx.Where(fmt.Sprintf("(orders.%s, orders.id) %s (CAST(? AS %s), ?)", sort.Field, comparison, column.sqlType), cursor.Value, cursor.ID)
+	} else {
+		tx = tx.Offset(query.Offset)
+	}
+	if err := tx.Find(&page.Items).Error; err != nil {
+		return page, err
+	}
+
+	// The extra row only tells whether another page follows.
+	if len(page.Items) > page.Limit {
+		page.Items = page.Items[:page.Limit]
+		last := &page.Items[page.Limit-1]
+		page.NextCursor = pagination.Cursor{Sort: sort.String(), Value: column.value(last), ID: last.ID}.Encode()
+	}
+	return page, nil
+}
+
+// TotalsByCurrency counts and sums the orders matching filters per currency, ordered by currency.
+func (r *orderRepository) TotalsByCurrency(ctx context.Context, filters schemas.OrderFilters) ([]schemas.CurrencyTotal, error) {
+	totals := []schemas.CurrencyTotal{}
+	err := r.Db.WithContext(ctx).Model(&models.Order{}).
+		Scopes(func(tx *gorm.DB) *gorm.DB { return filterOrders(tx, filters) }).
+		Select("orders.currency AS currency, count(*) AS order_count, coalesce(sum(orders.total_amount), 0) AS total_amount").
+		Group("orders.currency").Order("orders.currency").
+		Scan(&totals).Error
+	return totals, err
+}
+
+func filterOrders(tx *gorm.DB, query schemas.OrderFilters) *gorm.DB {
+	if query.UserId != 0 {
+		tx = tx.Where("orders.user_id = ?", query.UserId)
+	}
+	if query.Status != "" {
+		tx = tx.Where("orders.status = ?", query.Status)
+	}
+	if query.Currency != "" {
+		tx = tx.Where("orders.currency = ?", query.Currency)
+	}
+	if query.ProductId != 0 {
+		tx = tx.Where("EXISTS (SELECT 1 FROM order_items WHERE order_items.order_id = orders.id AND order_items.product_id = ?)", query.ProductId)
+	}
+	tx = whereTime(tx, "orders.created_at >= ?", query.CreatedFrom)
+	tx = whereTime(tx, "orders.created_at <= ?", query.CreatedTo)
+	tx = whereTime(tx, "orders.updated_at >= ?", query.UpdatedFrom)
+	tx = whereTime(tx, "orders.updated_at <= ?", query.UpdatedTo)
+	if query.MinTotal != nil {
+		tx = tx.Where("orders.total_amount >= ?", *query.MinTotal)
+	}
+	if query.MaxTotal != nil {
+		tx = tx.Where("orders.total_amount <= ?", *query.MaxTotal)
+	}
+	return tx
+}
+
+// whereTime adds condition for an RFC 3339 timestamp that has already been validated.
+func whereTime(tx *gorm.DB, condition, value string) *gorm.DB {
+	if value == "" {
+		return tx
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return tx
+	}
+	return tx.Where(condition, parsed)
+}
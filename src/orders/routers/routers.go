@@ -1,18 +1,48 @@
 package routers
 
 import (
+	"log/slog"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/svadikari/golang_fiber_orders/src/currency"
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"github.com/svadikari/golang_fiber_orders/src/middleware"
 	"github.com/svadikari/golang_fiber_orders/src/orders/controllers"
+	"github.com/svadikari/golang_fiber_orders/src/orders/repository"
+	"github.com/svadikari/golang_fiber_orders/src/orders/services"
+	"github.com/svadikari/golang_fiber_orders/src/serialization"
+	"github.com/svadikari/golang_fiber_orders/src/shipping"
+	"github.com/svadikari/golang_fiber_orders/src/tax"
+	"gorm.io/gorm"
 )
 
-func Init(app *fiber.App) {
+func Init(app *fiber.App, db *gorm.DB, publisher events.EventPublisher, serializer *serialization.EventSerializer) {
+	orderController := controllers.NewOrderController(NewOrderService(db, serializer), publisher)
 	app.Route("/orders", func(router fiber.Router) {
-		router.Get("/", controllers.GetOrders)
-		router.Post("/", controllers.CreateOrders)
-		router.Put("/:id", controllers.UpdateOrder)
-		router.Get("/:id", controllers.GetOrder)
-		router.Delete("/:id", controllers.DeleteOrder)
-		router.Get("/consumer/start", controllers.StartConsumer)
-		router.Get("/consumer/stop", controllers.StopConsumer)
+		router.Get("/", orderController.GetOrders)
+		router.Post("/", middleware.Idempotency, orderController.CreateOrders)
+		router.Get("/summary", orderController.GetOrderSummary)
+		router.Put("/:id", orderController.UpdateOrder)
+		router.Get("/:id", orderController.GetOrder)
+		router.Delete("/:id", orderController.DeleteOrder)
+		router.Get("/:id/history", orderController.GetOrderHistory)
+		router.Get("/:id/shipments", orderController.GetShipments)
+		router.Post("/:id/shipments", orderController.CreateShipment)
+		router.Post("/:id/shipments/:shipmentId/deliver", orderController.DeliverShipment)
+		router.Post("/consumer/start", orderController.StartConsumer)
+		router.Post("/consumer/stop", orderController.StopConsumer)
+		router.Get("/consumer/status", orderController.ConsumerStatus)
+		router.Get("/producer/stats", orderController.ProducerStats)
 	})
 }
+
+// InitEventHandlers registers the order workflow handlers for consumed order events.
+func InitEventHandlers(registry *events.Registry, db *gorm.DB, serializer *serialization.EventSerializer) {
+	controllers.RegisterEventHandlers(registry, NewOrderService(db, serializer), serializer)
+}
+
+// NewOrderService builds the order service; carts check out through it.
+func NewOrderService(db *gorm.DB, serializer *serialization.EventSerializer) services.OrderService {
+	orderRepository := repository.NewOrderRepository(db)
+	return services.NewOrderService(orderRepository, serializer, middleware.NewPaymentService(), middleware.NewUserService(), currency.NewRates(db), tax.NewEngine(db), shipping.NewMethods(db), slog.Default())
+}
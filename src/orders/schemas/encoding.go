@@ -0,0 +1,581 @@
+package schemas
+
+import (
+	_ "embed"
+	"math"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/svadikari/golang_fiber_orders/src/money"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Schemas registered with the schema registry for the binary encodings of OrderEvent.
+// Changes must stay backward compatible with the registered versions.
+var (
+	//go:embed order_event.proto
+	orderEventProto string
+	//go:embed order_event.avsc
+	orderEventAvro string
+)
+
+// Protobuf field numbers from order_event.proto.
+const (
+	fieldOrderID         = 1
+	fieldUserID          = 2
+	fieldStatus          = 3
+	fieldPreviousStatus  = 4
+	fieldTotalAmount     = 5
+	fieldItems           = 6
+	fieldCreatedAt       = 7
+	fieldUpdatedAt       = 8
+	fieldCurrency        = 9
+	fieldSubtotal        = 10
+	fieldTaxAmount       = 11
+	fieldTaxMode         = 12
+	fieldTaxLines        = 13
+	fieldDiscountAmount  = 14
+	fieldCouponCode      = 15
+	fieldShippingMethod  = 16
+	fieldShippingCost    = 17
+	fieldShippingAddress = 18
+	fieldBillingAddress  = 19
+	fieldTrackingNumber  = 20
+	fieldCarrier         = 21
+
+	fieldItemProductID      = 1
+	fieldItemQuantity       = 2
+	fieldItemUnitPrice      = 3
+	fieldItemDiscountAmount = 4
+
+	fieldTaxLineName          = 1
+	fieldTaxLineRegion        = 2
+	fieldTaxLineCategory      = 3
+	fieldTaxLineRate          = 4
+	fieldTaxLineTaxableAmount = 5
+	fieldTaxLineTaxAmount     = 6
+
+	fieldAddressName       = 1
+	fieldAddressLine1      = 2
+	fieldAddressLine2      = 3
+	fieldAddressCity       = 4
+	fieldAddressRegion     = 5
+	fieldAddressPostalCode = 6
+	fieldAddressCountry    = 7
+)
+
+func (e *OrderEvent) ProtoSchema() string {
+	return orderEventProto
+}
+
+func (e *OrderEvent) MarshalProto() ([]byte, error) {
+	var b []byte
+	b = appendVarintField(b, fieldOrderID, uint64(e.OrderID))
+	b = appendVarintField(b, fieldUserID, uint64(e.UserID))
+	b = appendStringField(b, fieldStatus, e.Status)
+	b = appendStringField(b, fieldPreviousStatus, e.PreviousStatus)
+	b = appendDoubleField(b, fieldTotalAmount, e.TotalAmount.Float64())
+	for _, item := range e.Items {
+		var ib []byte
+		ib = appendVarintField(ib, fieldItemProductID, uint64(item.ProductID))
+		ib = appendVarintField(ib, fieldItemQuantity, uint64(int32(item.Quantity)))
+		ib = appendDoubleField(ib, fieldItemUnitPrice, item.UnitPrice.Float64())
+		ib = appendDoubleField(ib, fieldItemDiscountAmount, item.DiscountAmount.Float64())
+		b = protowire.AppendTag(b, fieldItems, protowire.BytesType)
+		b = protowire.AppendBytes(b, ib)
+	}
+	b = appendVarintField(b, fieldCreatedAt, uint64(e.CreatedAt.UnixMilli()))
+	b = appendVarintField(b, fieldUpdatedAt, uint64(e.UpdatedAt.UnixMilli()))
+	b = appendStringField(b, fieldCurrency, e.Currency)
+	b = appendDoubleField(b, fieldSubtotal, e.Subtotal.Float64())
+	b = appendDoubleField(b, fieldTaxAmount, e.TaxAmount.Float64())
+	b = appendStringField(b, fieldTaxMode, e.TaxMode)
+	for _, line := range e.TaxLines {
+		var lb []byte
+		lb = appendStringField(lb, fieldTaxLineName, line.Name)
+		lb = appendStringField(lb, fieldTaxLineRegion, line.Region)
+		lb = appendStringField(lb, fieldTaxLineCategory, line.Category)
+		lb = appendStringField(lb, fieldTaxLineRate, line.Rate.String())
+		lb = appendDoubleField(lb, fieldTaxLineTaxableAmount, line.TaxableAmount.Float64())
+		lb = appendDoubleField(lb, fieldTaxLineTaxAmount, line.TaxAmount.Float64())
+		b = protowire.AppendTag(b, fieldTaxLines, protowire.BytesType)
+		b = protowire.AppendBytes(b, lb)
+	}
+	b = appendDoubleField(b, fieldDiscountAmount, e.DiscountAmount.Float64())
+	b = appendStringField(b, fieldCouponCode, e.CouponCode)
+	b = appendStringField(b, fieldShippingMethod, e.ShippingMethod)
+	b = appendDoubleField(b, fieldShippingCost, e.ShippingCost.Float64())
+	b = appendAddressField(b, fieldShippingAddress, e.ShippingAddress)
+	b = appendAddressField(b, fieldBillingAddress, e.BillingAddress)
+	b = appendStringField(b, fieldTrackingNumber, e.TrackingNumber)
+	b = appendStringField(b, fieldCarrier, e.Carrier)
+	return b, nil
+}
+
+func appendAddressField(b []byte, num protowire.Number, address *OrderEventAddress) []byte {
+	if address == nil {
+		return b
+	}
+	var ab []byte
+	ab = appendStringField(ab, fieldAddressName, address.Name)
+	ab = appendStringField(ab, fieldAddressLine1, address.Line1)
+	ab = appendStringField(ab, fieldAddressLine2, address.Line2)
+	ab = appendStringField(ab, fieldAddressCity, address.City)
+	ab = appendStringField(ab, fieldAddressRegion, address.Region)
+	ab = appendStringField(ab, fieldAddressPostalCode, address.PostalCode)
+	ab = appendStringField(ab, fieldAddressCountry, address.Country)
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, ab)
+}
+
+func (e *OrderEvent) UnmarshalProto(b []byte) error {
+	*e = OrderEvent{}
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == fieldStatus && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(b)
+			e.Status = v
+			return n, nil
+		case num == fieldPreviousStatus && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(b)
+			e.PreviousStatus = v
+			return n, nil
+		case num == fieldCurrency && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(b)
+			e.Currency = v
+			return n, nil
+		case num == fieldTaxMode && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(b)
+			e.TaxMode = v
+			return n, nil
+		case num == fieldCouponCode && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(b)
+			e.CouponCode = v
+			return n, nil
+		case num == fieldShippingMethod && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(b)
+			e.ShippingMethod = v
+			return n, nil
+		case num == fieldTrackingNumber && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(b)
+			e.TrackingNumber = v
+			return n, nil
+		case num == fieldCarrier && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(b)
+			e.Carrier = v
+			return n, nil
+		case (num == fieldShippingAddress || num == fieldBillingAddress) && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n, nil
+			}
+			address := &OrderEventAddress{}
+			if err := address.unmarshalProto(v); err != nil {
+				return 0, err
+			}
+			if num == fieldShippingAddress {
+				e.ShippingAddress = address
+			} else {
+				e.BillingAddress = address
+			}
+			return n, nil
+		case num == fieldShippingCost && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			amount, err := money.FromFloat(math.Float64frombits(v))
+			e.ShippingCost = amount
+			return n, err
+		case num == fieldTotalAmount && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			amount, err := money.FromFloat(math.Float64frombits(v))
+			e.TotalAmount = amount
+			return n, err
+		case num == fieldSubtotal && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			amount, err := money.FromFloat(math.Float64frombits(v))
+			e.Subtotal = amount
+			return n, err
+		case num == fieldTaxAmount && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			amount, err := money.FromFloat(math.Float64frombits(v))
+			e.TaxAmount = amount
+			return n, err
+		case num == fieldDiscountAmount && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			amount, err := money.FromFloat(math.Float64frombits(v))
+			e.DiscountAmount = amount
+			return n, err
+		case num == fieldTaxLines && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n, nil
+			}
+			var line OrderEventTaxLine
+			if err := line.unmarshalProto(v); err != nil {
+				return 0, err
+			}
+			e.TaxLines = append(e.TaxLines, line)
+			return n, nil
+		case num == fieldItems && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return n, nil
+			}
+			var item OrderEventItem
+			if err := item.unmarshalProto(v); err != nil {
+				return 0, err
+			}
+			e.Items = append(e.Items, item)
+			return n, nil
+		case typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			switch num {
+			case fieldOrderID:
+				e.OrderID = uint(v)
+			case fieldUserID:
+				e.UserID = uint(v)
+			case fieldCreatedAt:
+				e.CreatedAt = time.UnixMilli(int64(v)).UTC()
+			case fieldUpdatedAt:
+				e.UpdatedAt = time.UnixMilli(int64(v)).UTC()
+			}
+			return n, nil
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+}
+
+func (i *OrderEventItem) unmarshalProto(b []byte) error {
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case num == fieldItemUnitPrice && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			price, err := money.FromFloat(math.Float64frombits(v))
+			i.UnitPrice = price
+			return n, err
+		case num == fieldItemDiscountAmount && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			discount, err := money.FromFloat(math.Float64frombits(v))
+			i.DiscountAmount = discount
+			return n, err
+		case typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			switch num {
+			case fieldItemProductID:
+				i.ProductID = uint(v)
+			case fieldItemQuantity:
+				i.Quantity = int(int32(v))
+			}
+			return n, nil
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+}
+
+func (l *OrderEventTaxLine) unmarshalProto(b []byte) error {
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch {
+		case typ == protowire.BytesType && num <= fieldTaxLineRate:
+			v, n := protowire.ConsumeString(b)
+			if n < 0 {
+				return n, nil
+			}
+			switch num {
+			case fieldTaxLineName:
+				l.Name = v
+			case fieldTaxLineRegion:
+				l.Region = v
+			case fieldTaxLineCategory:
+				l.Category = v
+			case fieldTaxLineRate:
+				rate, err := money.ParseRate(v)
+				if err != nil {
+					return 0, err
+				}
+				l.Rate = rate
+			}
+			return n, nil
+		case typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			amount, err := money.FromFloat(math.Float64frombits(v))
+			switch num {
+			case fieldTaxLineTaxableAmount:
+				l.TaxableAmount = amount
+			case fieldTaxLineTaxAmount:
+				l.TaxAmount = amount
+			}
+			return n, err
+		}
+		return protowire.ConsumeFieldValue(num, typ, b), nil
+	})
+}
+
+func (a *OrderEventAddress) unmarshalProto(b []byte) error {
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if typ != protowire.BytesType {
+			return protowire.ConsumeFieldValue(num, typ, b), nil
+		}
+		v, n := protowire.ConsumeString(b)
+		switch num {
+		case fieldAddressName:
+			a.Name = v
+		case fieldAddressLine1:
+			a.Line1 = v
+		case fieldAddressLine2:
+			a.Line2 = v
+		case fieldAddressCity:
+			a.City = v
+		case fieldAddressRegion:
+			a.Region = v
+		case fieldAddressPostalCode:
+			a.PostalCode = v
+		case fieldAddressCountry:
+			a.Country = v
+		}
+		return n, nil
+	})
+}
+
+// consumeFields walks the fields of a protobuf message, calling field with the bytes after each tag.
+// Unknown fields are skipped so older consumers can read newer events.
+func consumeFields(b []byte, field func(protowire.Number, protowire.Type, []byte) (int, error)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		n, err := field(num, typ, b)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// Zero values are omitted, as proto3 does.
+func appendVarintField(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendStringField(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendDoubleField(b []byte, num protowire.Number, v float64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(v))
+}
+
+// avroOrderEvent mirrors OrderEvent with the types of order_event.avsc, which has no unsigned integers.
+// Amounts are carried as doubles, which hold every two-decimal amount closely enough to round back exactly.
+type avroOrderEvent struct {
+	OrderID         int64                   `avro:"order_id"`
+	UserID          int64                   `avro:"user_id"`
+	Status          string                  `avro:"status"`
+	PreviousStatus  string                  `avro:"previous_status"`
+	TotalAmount     float64                 `avro:"total_amount"`
+	Items           []avroOrderEventItem    `avro:"items"`
+	CreatedAt       time.Time               `avro:"created_at"`
+	UpdatedAt       time.Time               `avro:"updated_at"`
+	Currency        string                  `avro:"currency"`
+	Subtotal        float64                 `avro:"subtotal"`
+	TaxAmount       float64                 `avro:"tax_amount"`
+	TaxMode         string                  `avro:"tax_mode"`
+	TaxLines        []avroOrderEventTaxLine `avro:"tax_lines"`
+	DiscountAmount  float64                 `avro:"discount_amount"`
+	CouponCode      string                  `avro:"coupon_code"`
+	ShippingMethod  string                  `avro:"shipping_method"`
+	ShippingCost    float64                 `avro:"shipping_cost"`
+	ShippingAddress *avroOrderEventAddress  `avro:"shipping_address"`
+	BillingAddress  *avroOrderEventAddress  `avro:"billing_address"`
+	TrackingNumber  string                  `avro:"tracking_number"`
+	Carrier         string                  `avro:"carrier"`
+}
+
+type avroOrderEventItem struct {
+	ProductID      int64   `avro:"product_id"`
+	Quantity       int32   `avro:"quantity"`
+	UnitPrice      float64 `avro:"unit_price"`
+	DiscountAmount float64 `avro:"discount_amount"`
+}
+
+type avroOrderEventTaxLine struct {
+	Name          string  `avro:"name"`
+	Region        string  `avro:"region"`
+	Category      string  `avro:"category"`
+	Rate          string  `avro:"rate"`
+	TaxableAmount float64 `avro:"taxable_amount"`
+	TaxAmount     float64 `avro:"tax_amount"`
+}
+
+// avroOrderEventAddress has the same fields as OrderEventAddress; the addresses are nullable unions.
+type avroOrderEventAddress struct {
+	Name       string `avro:"name"`
+	Line1      string `avro:"line1"`
+	Line2      string `avro:"line2"`
+	City       string `avro:"city"`
+	Region     string `avro:"region"`
+	PostalCode string `avro:"postal_code"`
+	Country    string `avro:"country"`
+}
+
+func (e *OrderEvent) AvroSchema() string {
+	return orderEventAvro
+}
+
+func (e *OrderEvent) MarshalAvro(schema avro.Schema) ([]byte, error) {
+	record := avroOrderEvent{
+		OrderID:         int64(e.OrderID),
+		UserID:          int64(e.UserID),
+		Status:          e.Status,
+		PreviousStatus:  e.PreviousStatus,
+		TotalAmount:     e.TotalAmount.Float64(),
+		Items:           make([]avroOrderEventItem, 0, len(e.Items)),
+		CreatedAt:       e.CreatedAt,
+		UpdatedAt:       e.UpdatedAt,
+		Currency:        e.Currency,
+		Subtotal:        e.Subtotal.Float64(),
+		TaxAmount:       e.TaxAmount.Float64(),
+		TaxMode:         e.TaxMode,
+		TaxLines:        make([]avroOrderEventTaxLine, 0, len(e.TaxLines)),
+		DiscountAmount:  e.DiscountAmount.Float64(),
+		CouponCode:      e.CouponCode,
+		ShippingMethod:  e.ShippingMethod,
+		ShippingCost:    e.ShippingCost.Float64(),
+		ShippingAddress: (*avroOrderEventAddress)(e.ShippingAddress),
+		BillingAddress:  (*avroOrderEventAddress)(e.BillingAddress),
+		TrackingNumber:  e.TrackingNumber,
+		Carrier:         e.Carrier,
+	}
+	for _, line := range e.TaxLines {
+		record.TaxLines = append(record.TaxLines, avroOrderEventTaxLine{
+			Name:          line.Name,
+			Region:        line.Region,
+			Category:      line.Category,
+			Rate:          line.Rate.String(),
+			TaxableAmount: line.TaxableAmount.Float64(),
+			TaxAmount:     line.TaxAmount.Float64(),
+		})
+	}
+	for _, item := range e.Items {
+		record.Items = append(record.Items, avroOrderEventItem{
+			ProductID:      int64(item.ProductID),
+			Quantity:       int32(item.Quantity),
+			UnitPrice:      item.UnitPrice.Float64(),
+			DiscountAmount: item.DiscountAmount.Float64(),
+		})
+	}
+	return avro.Marshal(schema, record)
+}
+
+// UnmarshalAvro resolves data written with writerSchema against the current schema.
+func (e *OrderEvent) UnmarshalAvro(writerSchema avro.Schema, data []byte) error {
+	readerSchema, err := avro.Parse(orderEventAvro)
+	if err != nil {
+		return err
+	}
+	resolved, err := avro.NewSchemaCompatibility().Resolve(readerSchema, writerSchema)
+	if err != nil {
+		return err
+	}
+	var record avroOrderEvent
+	if err := avro.Unmarshal(resolved, data, &record); err != nil {
+		return err
+	}
+	totalAmount, err := money.FromFloat(record.TotalAmount)
+	if err != nil {
+		return err
+	}
+	subtotal, err := money.FromFloat(record.Subtotal)
+	if err != nil {
+		return err
+	}
+	taxAmount, err := money.FromFloat(record.TaxAmount)
+	if err != nil {
+		return err
+	}
+	discountAmount, err := money.FromFloat(record.DiscountAmount)
+	if err != nil {
+		return err
+	}
+	shippingCost, err := money.FromFloat(record.ShippingCost)
+	if err != nil {
+		return err
+	}
+	*e = OrderEvent{
+		OrderID:         uint(record.OrderID),
+		UserID:          uint(record.UserID),
+		Status:          record.Status,
+		PreviousStatus:  record.PreviousStatus,
+		TotalAmount:     totalAmount,
+		Items:           make([]OrderEventItem, 0, len(record.Items)),
+		CreatedAt:       record.CreatedAt.UTC(),
+		UpdatedAt:       record.UpdatedAt.UTC(),
+		Currency:        record.Currency,
+		Subtotal:        subtotal,
+		TaxAmount:       taxAmount,
+		TaxMode:         record.TaxMode,
+		DiscountAmount:  discountAmount,
+		CouponCode:      record.CouponCode,
+		ShippingMethod:  record.ShippingMethod,
+		ShippingCost:    shippingCost,
+		ShippingAddress: (*OrderEventAddress)(record.ShippingAddress),
+		BillingAddress:  (*OrderEventAddress)(record.BillingAddress),
+		TrackingNumber:  record.TrackingNumber,
+		Carrier:         record.Carrier,
+	}
+	for _, line := range record.TaxLines {
+		rate, err := money.ParseRate(line.Rate)
+		if err != nil {
+			return err
+		}
+		taxableAmount, err := money.FromFloat(line.TaxableAmount)
+		if err != nil {
+			return err
+		}
+		lineTax, err := money.FromFloat(line.TaxAmount)
+		if err != nil {
+			return err
+		}
+		e.TaxLines = append(e.TaxLines, OrderEventTaxLine{
+			Name:          line.Name,
+			Region:        line.Region,
+			Category:      line.Category,
+			Rate:          rate,
+			TaxableAmount: taxableAmount,
+			TaxAmount:     lineTax,
+		})
+	}
+	for _, item := range record.Items {
+		unitPrice, err := money.FromFloat(item.UnitPrice)
+		if err != nil {
+			return err
+		}
+		itemDiscount, err := money.FromFloat(item.DiscountAmount)
+		if err != nil {
+			return err
+		}
+		e.Items = append(e.Items, OrderEventItem{
+			ProductID:      uint(item.ProductID),
+			Quantity:       int(item.Quantity),
+			UnitPrice:      unitPrice,
+			DiscountAmount: itemDiscount,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/money"
+)
+
+// Order event types published in the CloudEvents envelope of every order event.
+const (
+	EventOrderCreated       = "order.created"
+	EventOrderStatusChanged = "order.status_changed"
+	EventOrderDeleted       = "order.deleted"
+)
+
+// OrderEventSchemaVersion versions OrderEvent. Bump the major version for breaking changes.
+const OrderEventSchemaVersion = "1.4"
+
+// OrderEvent is the data of every order event. It is deliberately decoupled from the database
+// model so schema changes do not leak to consumers.
+type OrderEvent struct {
+	OrderID        uint             `json:"order_id"`
+	UserID         uint             `json:"user_id"`
+	Status         string           `json:"status"`
+	PreviousStatus string           `json:"previous_status,omitempty"`
+	TotalAmount    money.Money      `json:"total_amount"`
+	Currency       string           `json:"currency,omitempty"`
+	Items          []OrderEventItem `json:"items"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	// Subtotal is TotalAmount before tax.
+	Subtotal  money.Money         `json:"subtotal"`
+	TaxAmount money.Money         `json:"tax_amount"`
+	TaxMode   string              `json:"tax_mode,omitempty"`
+	TaxLines  []OrderEventTaxLine `json:"tax_lines,omitempty"`
+	// DiscountAmount is the discount of the redeemed coupon, already taken off Subtotal.
+	DiscountAmount money.Money `json:"discount_amount"`
+	CouponCode     string      `json:"coupon_code,omitempty"`
+	// ShippingCost is included in TotalAmount. The addresses are nil for orders placed without shipping.
+	ShippingMethod  string             `json:"shipping_method,omitempty"`
+	ShippingCost    money.Money        `json:"shipping_cost"`
+	ShippingAddress *OrderEventAddress `json:"shipping_address,omitempty"`
+	BillingAddress  *OrderEventAddress `json:"billing_address,omitempty"`
+	TrackingNumber  string             `json:"tracking_number,omitempty"`
+	Carrier         string             `json:"carrier,omitempty"`
+}
+
+type OrderEventItem struct {
+	ProductID uint        `json:"product_id"`
+	Quantity  int         `json:"quantity"`
+	UnitPrice money.Money `json:"unit_price"`
+	// DiscountAmount is taken off UnitPrice times Quantity.
+	DiscountAmount money.Money `json:"discount_amount"`
+}
+
+type OrderEventTaxLine struct {
+	Name          string      `json:"name"`
+	Region        string      `json:"region"`
+	Category      string      `json:"category"`
+	Rate          money.Rate  `json:"rate"`
+	TaxableAmount money.Money `json:"taxable_amount"`
+	TaxAmount     money.Money `json:"tax_amount"`
+}
+
+type OrderEventAddress struct {
+	Name       string `json:"name"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+}
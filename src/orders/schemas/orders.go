@@ -1,26 +1,141 @@
 package schemas
 
+import "github.com/svadikari/golang_fiber_orders/src/money"
+
+// OrderSchema creates an order. Currency defaults to the currency of the order lines that name one,
+// then to DEFAULT_CURRENCY; every line must share it. TaxRegion defaults to TAX_DEFAULT_REGION.
+// CouponCode redeems a promotion. ShippingMethod and ShippingAddress go together; BillingAddress defaults
+// to ShippingAddress.
 type OrderSchema struct {
 	UserId     uint              `json:"user_id" validate:"required,min=1" message:"user_id is required and must be min 1"`
-	Status     OrderStatus       `json:"status" validate:"required,oneof=NEW SHIPPED DELIVERED CANCELLED"  message:"status is required and must be oneof NEW/SHIPPED/DELIVERED/CANCELLED"`
+	Status     OrderStatus       `json:"status" validate:"omitempty,oneof=NEW"  message:"status must be NEW when creating an order"`
+	Currency   string            `json:"currency" validate:"omitempty,iso4217" message:"currency must be an ISO 4217 code"`
+	TaxRegion  string            `json:"tax_region" validate:"omitempty,iso3166_1_alpha2|iso3166_2" message:"tax_region must be an ISO 3166-1 country or ISO 3166-2 subdivision code"`
+	CouponCode string            `json:"coupon_code" validate:"omitempty,max=50,alphanum" message:"coupon_code must be at most 50 letters or digits"`
 	OrderItems []OrderItemSchema `json:"order_items" validate:"required,dive" message:"order_items is required"`
+	ShippingSchema
+}
+
+// ShippingSchema is where and how an order is shipped.
+type ShippingSchema struct {
+	ShippingMethod  string         `json:"shipping_method" validate:"required_with=ShippingAddress,max=50" message:"shipping_method is required with shipping_address and must be at most 50 characters"`
+	ShippingAddress *AddressSchema `json:"shipping_address" validate:"required_with=ShippingMethod BillingAddress,omitempty" message:"shipping_address is required with shipping_method or billing_address"`
+	BillingAddress  *AddressSchema `json:"billing_address" validate:"omitempty"`
+}
+
+type AddressSchema struct {
+	Name       string `json:"name" validate:"required,max=200" message:"address name is required and must be at most 200 characters"`
+	Line1      string `json:"line1" validate:"required,max=200" message:"address line1 is required and must be at most 200 characters"`
+	Line2      string `json:"line2" validate:"max=200" message:"address line2 must be at most 200 characters"`
+	City       string `json:"city" validate:"required,max=100" message:"address city is required and must be at most 100 characters"`
+	Region     string `json:"region" validate:"max=100" message:"address region must be at most 100 characters"`
+	PostalCode string `json:"postal_code" validate:"max=20,postal_code=Country" message:"address postal_code must be a valid postal code of the address country"`
+	Country    string `json:"country" validate:"required,iso3166_1_alpha2" message:"address country must be an ISO 3166-1 alpha-2 code"`
 }
 
 type OrderItemSchema struct {
-	ProductID uint    `json:"product_id" validate:"required,min=1" message:"product_id is required and must be min 1"`
-	Quantity  int     `json:"quantity" validate:"required,min=1" message:"quantity is required and must be min 1"`
-	UnitPrice float64 `json:"unit_price" validate:"required,gt=0" message:"unit_price is required and must be greater than 0"`
+	ProductID uint   `json:"product_id" validate:"required,min=1" message:"product_id is required and must be min 1"`
+	Quantity  int    `json:"quantity" validate:"required,min=1" message:"quantity is required and must be min 1"`
+	Currency  string `json:"currency" validate:"omitempty,iso4217" message:"order_items currency must be an ISO 4217 code"`
 }
 
+// OrderUpdateSchema changes the status of an order. TrackingNumber and Carrier are required when the
+// order moves to SHIPPED, which ships every line not shipped yet in one shipment.
 type OrderUpdateSchema struct {
-	Status OrderStatus `json:"status" validate:"required,oneof=NEW SHIPPED DELIVERED CANCELLED"  message:"status is required and must be oneof NEW/SHIPPED/DELIVERED/CANCELLED"`
+	Status         OrderStatus `json:"status" validate:"required,oneof=NEW CONFIRMED SHIPPED DELIVERED CANCELLED RETURNED"  message:"status is required and must be oneof NEW/CONFIRMED/SHIPPED/DELIVERED/CANCELLED/RETURNED"`
+	ChangedBy      string      `json:"changed_by" validate:"max=100" message:"changed_by must be at most 100 characters"`
+	Reason         string      `json:"reason" validate:"max=500" message:"reason must be at most 500 characters"`
+	TrackingNumber string      `json:"tracking_number" validate:"required_if=Status SHIPPED,max=100" message:"tracking_number is required when shipping and must be at most 100 characters"`
+	Carrier        string      `json:"carrier" validate:"required_if=Status SHIPPED,max=50" message:"carrier is required when shipping and must be at most 50 characters"`
 }
 
 type OrderStatus string
 
 const (
 	StatusNew       OrderStatus = "NEW"
+	StatusConfirmed OrderStatus = "CONFIRMED"
 	StatusShipped   OrderStatus = "SHIPPED"
 	StatusDelivered OrderStatus = "DELIVERED"
 	StatusCancelled OrderStatus = "CANCELLED"
+	StatusReturned  OrderStatus = "RETURNED"
+	// StatusPartiallyShipped and StatusPartiallyDelivered are derived from the fulfillment of the order
+	// lines; they cannot be set directly.
+	StatusPartiallyShipped   OrderStatus = "PARTIALLY_SHIPPED"
+	StatusPartiallyDelivered OrderStatus = "PARTIALLY_DELIVERED"
 )
+
+// orderTransitions lists the statuses an order may move to from each status.
+// Statuses without an entry are terminal.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	StatusNew:                {StatusConfirmed, StatusCancelled},
+	StatusConfirmed:          {StatusPartiallyShipped, StatusShipped, StatusCancelled},
+	StatusPartiallyShipped:   {StatusShipped, StatusPartiallyDelivered},
+	StatusShipped:            {StatusPartiallyDelivered, StatusDelivered, StatusReturned},
+	StatusPartiallyDelivered: {StatusDelivered, StatusReturned},
+	StatusDelivered:          {StatusReturned},
+}
+
+// CanTransitionTo reports whether the order lifecycle allows moving from s to next.
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether no further transitions are allowed from s.
+func (s OrderStatus) IsTerminal() bool {
+	return len(orderTransitions[s]) == 0
+}
+
+// OrderFilters holds the order filters shared by GET /orders and GET /orders/summary. Dates are RFC 3339
+// timestamps; ranges include both bounds.
+type OrderFilters struct {
+	UserId      uint         `query:"user_id"`
+	Status      string       `query:"status" validate:"omitempty,oneof=NEW CONFIRMED PARTIALLY_SHIPPED SHIPPED PARTIALLY_DELIVERED DELIVERED CANCELLED RETURNED" message:"status must be oneof NEW/CONFIRMED/PARTIALLY_SHIPPED/SHIPPED/PARTIALLY_DELIVERED/DELIVERED/CANCELLED/RETURNED"`
+	Currency    string       `query:"currency" validate:"omitempty,iso4217" message:"currency must be an ISO 4217 code"`
+	ProductId   uint         `query:"product_id"`
+	CreatedFrom string       `query:"created_from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00" message:"created_from must be an RFC 3339 timestamp"`
+	CreatedTo   string       `query:"created_to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00" message:"created_to must be an RFC 3339 timestamp"`
+	UpdatedFrom string       `query:"updated_from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00" message:"updated_from must be an RFC 3339 timestamp"`
+	UpdatedTo   string       `query:"updated_to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00" message:"updated_to must be an RFC 3339 timestamp"`
+	MinTotal    *money.Money `query:"min_total" validate:"omitempty,min=0" message:"min_total must be min 0"`
+	MaxTotal    *money.Money `query:"max_total" validate:"omitempty,min=0" message:"max_total must be min 0"`
+}
+
+// OrderQuerySchema holds the filters, sorting and pagination of GET /orders.
+type OrderQuerySchema struct {
+	OrderFilters
+	Sort   string `query:"sort" validate:"omitempty,oneof=id -id created_at -created_at updated_at -updated_at total_amount -total_amount" message:"sort must be oneof id/created_at/updated_at/total_amount with an optional - prefix for descending order"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100" message:"limit must be between 1 and 100"`
+	Offset int    `query:"offset" validate:"omitempty,min=0" message:"offset must be min 0"`
+	Cursor string `query:"cursor"`
+}
+
+// DefaultOrderSort lists the most recent orders first.
+const DefaultOrderSort = "-created_at"
+
+// OrderSummaryQuerySchema holds the filters of GET /orders/summary and the currency totals are reported in,
+// which defaults to DEFAULT_CURRENCY.
+type OrderSummaryQuerySchema struct {
+	OrderFilters
+	ReportCurrency string `query:"report_currency" validate:"omitempty,iso4217" message:"report_currency must be an ISO 4217 code"`
+}
+
+// OrderSummary totals the matching orders, converted into ReportCurrency with the stored exchange rates.
+type OrderSummary struct {
+	ReportCurrency string          `json:"report_currency"`
+	OrderCount     int64           `json:"order_count"`
+	TotalAmount    money.Money     `json:"total_amount"`
+	ByCurrency     []CurrencyTotal `json:"by_currency"`
+}
+
+// CurrencyTotal totals the matching orders placed in one currency.
+type CurrencyTotal struct {
+	Currency        string      `json:"currency"`
+	OrderCount      int64       `json:"order_count"`
+	TotalAmount     money.Money `json:"total_amount"`
+	ConvertedAmount money.Money `json:"converted_amount"`
+}
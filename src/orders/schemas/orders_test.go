@@ -0,0 +1,43 @@
+package schemas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderStatusTransitions(t *testing.T) {
+
+	t.Run("Lifecycle transitions are allowed", func(t *testing.T) {
+		assert.True(t, StatusNew.CanTransitionTo(StatusConfirmed))
+		assert.True(t, StatusConfirmed.CanTransitionTo(StatusShipped))
+		assert.True(t, StatusShipped.CanTransitionTo(StatusDelivered))
+		assert.True(t, StatusNew.CanTransitionTo(StatusCancelled))
+		assert.True(t, StatusConfirmed.CanTransitionTo(StatusCancelled))
+		assert.True(t, StatusDelivered.CanTransitionTo(StatusReturned))
+	})
+
+	t.Run("Partial shipments and deliveries lead to full ones", func(t *testing.T) {
+		assert.True(t, StatusConfirmed.CanTransitionTo(StatusPartiallyShipped))
+		assert.True(t, StatusPartiallyShipped.CanTransitionTo(StatusShipped))
+		assert.True(t, StatusPartiallyShipped.CanTransitionTo(StatusPartiallyDelivered))
+		assert.True(t, StatusShipped.CanTransitionTo(StatusPartiallyDelivered))
+		assert.True(t, StatusPartiallyDelivered.CanTransitionTo(StatusDelivered))
+		assert.False(t, StatusPartiallyShipped.CanTransitionTo(StatusCancelled))
+		assert.False(t, StatusPartiallyShipped.CanTransitionTo(StatusDelivered))
+	})
+
+	t.Run("Illegal transitions are rejected", func(t *testing.T) {
+		assert.False(t, StatusDelivered.CanTransitionTo(StatusNew))
+		assert.False(t, StatusCancelled.CanTransitionTo(StatusShipped))
+		assert.False(t, StatusNew.CanTransitionTo(StatusShipped))
+		assert.False(t, StatusShipped.CanTransitionTo(StatusCancelled))
+		assert.False(t, StatusNew.CanTransitionTo(StatusNew))
+	})
+
+	t.Run("Cancelled and returned orders are terminal", func(t *testing.T) {
+		assert.True(t, StatusCancelled.IsTerminal())
+		assert.True(t, StatusReturned.IsTerminal())
+		assert.False(t, StatusShipped.IsTerminal())
+	})
+}
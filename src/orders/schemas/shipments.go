@@ -0,0 +1,32 @@
+package schemas
+
+// ShipmentSchema ships some quantity of one or more lines of an order.
+type ShipmentSchema struct {
+	TrackingNumber string               `json:"tracking_number" validate:"required,max=100" message:"tracking_number is required and must be at most 100 characters"`
+	Carrier        string               `json:"carrier" validate:"required,max=50" message:"carrier is required and must be at most 50 characters"`
+	Items          []ShipmentItemSchema `json:"items" validate:"required,min=1,dive" message:"items is required"`
+}
+
+type ShipmentItemSchema struct {
+	OrderItemID uint `json:"order_item_id" validate:"required,min=1" message:"order_item_id is required and must be min 1"`
+	Quantity    int  `json:"quantity" validate:"required,min=1" message:"quantity is required and must be min 1"`
+}
+
+// FulfillmentStatus tells how much of an order line has shipped and been delivered.
+type FulfillmentStatus string
+
+const (
+	FulfillmentUnfulfilled        FulfillmentStatus = "UNFULFILLED"
+	FulfillmentPartiallyShipped   FulfillmentStatus = "PARTIALLY_SHIPPED"
+	FulfillmentShipped            FulfillmentStatus = "SHIPPED"
+	FulfillmentPartiallyDelivered FulfillmentStatus = "PARTIALLY_DELIVERED"
+	FulfillmentDelivered          FulfillmentStatus = "DELIVERED"
+)
+
+// ShipmentStatus is the status of a shipment: SHIPPED until it is delivered.
+type ShipmentStatus string
+
+const (
+	ShipmentShipped   ShipmentStatus = "SHIPPED"
+	ShipmentDelivered ShipmentStatus = "DELIVERED"
+)
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/money"
+	"github.com/svadikari/golang_fiber_orders/src/orders/models"
+	"github.com/svadikari/golang_fiber_orders/src/orders/repository"
+	productModels "github.com/svadikari/golang_fiber_orders/src/products/models"
+	promotionModels "github.com/svadikari/golang_fiber_orders/src/promotions/models"
+)
+
+// CouponError rejects an order whose coupon code is unknown or whose promotion rules the order does not meet.
+type CouponError struct {
+	Code   string
+	Reason string
+}
+
+func (e *CouponError) Error() string {
+	return fmt.Sprintf("coupon %s %s", e.Code, e.Reason)
+}
+
+// applyCoupon redeems the promotion of a coupon code on the priced order lines: it checks the rules of the
+// promotion and records the discount of every line it targets. It returns the redeemed promotion, or nil
+// without a coupon code.
+func applyCoupon(ctx context.Context, tx repository.OrderRepository, order *models.Order,
+	products map[uint]productModels.Product, couponCode string, now time.Time) (*promotionModels.Promotion, error) {
+	code := strings.ToUpper(strings.TrimSpace(couponCode))
+	if code == "" {
+		return nil, nil
+	}
+	promotion, err := tx.LockPromotion(ctx, code)
+	if errors.Is(err, repository.ErrPromotionNotFound) {
+		return nil, &CouponError{Code: code, Reason: "does not exist"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lineTotals := make([]money.Money, len(order.OrderItems))
+	eligible := make([]bool, len(order.OrderItems))
+	var orderTotal money.Money
+	anyEligible := false
+	for i, item := range order.OrderItems {
+		lineTotals[i] = item.UnitPrice.Mul(item.Quantity)
+		orderTotal += lineTotals[i]
+		eligible[i] = promotion.Targets(item.ProductID, products[item.ProductID].Category)
+		anyEligible = anyEligible || eligible[i]
+	}
+
+	if reason := checkPromotion(promotion, order.Currency, orderTotal, now); reason != "" {
+		return nil, &CouponError{Code: code, Reason: reason}
+	}
+	if promotion.Type == promotionModels.TypeFreeShipping && order.ShippingMethod == "" {
+		return nil, &CouponError{Code: code, Reason: "requires a shipping method"}
+	}
+	if !anyEligible {
+		return nil, &CouponError{Code: code, Reason: "does not apply to any product in the order"}
+	}
+	if promotion.UsageLimitPerUser > 0 {
+		used, err := tx.CountRedemptions(ctx, promotion.ID, order.UserId)
+		if err != nil {
+			return nil, err
+		}
+		if used >= int64(promotion.UsageLimitPerUser) {
+			return nil, &CouponError{Code: code, Reason: "has reached its usage limit for this user"}
+		}
+	}
+
+	order.CouponCode = code
+	order.PromotionID = &promotion.ID
+	order.DiscountAmount = 0
+	for i, discount := range lineDiscounts(promotion, lineTotals, eligible) {
+		order.OrderItems[i].DiscountAmount = discount
+		order.DiscountAmount += discount
+	}
+	return &promotion, nil
+}
+
+// checkPromotion returns why an order in orderCurrency whose lines total orderTotal cannot redeem
+// the promotion at now, or "" when it can.
+func checkPromotion(promotion promotionModels.Promotion, orderCurrency string, orderTotal money.Money, now time.Time) string {
+	switch {
+	case !promotion.Active:
+		return "is not active"
+	case promotion.StartsAt != nil && now.Before(*promotion.StartsAt):
+		return "is not valid yet"
+	case promotion.EndsAt != nil && !now.Before(*promotion.EndsAt):
+		return "has expired"
+	case (promotion.Amount > 0 || promotion.MinOrderTotal > 0) && promotion.Currency != orderCurrency:
+		return "only applies to orders in " + promotion.Currency
+	case orderTotal < promotion.MinOrderTotal:
+		return fmt.Sprintf("requires an order total of at least %s %s", promotion.MinOrderTotal, promotion.Currency)
+	}
+	return ""
+}
+
+// lineDiscounts works out the discount of every order line. Percentage discounts are rounded per line;
+// a fixed amount, capped at the total of the eligible lines, is allocated across them by line total.
+// Free shipping discounts no line.
+func lineDiscounts(promotion promotionModels.Promotion, lineTotals []money.Money, eligible []bool) []money.Money {
+	discounts := make([]money.Money, len(lineTotals))
+	switch promotion.Type {
+	case promotionModels.TypePercentage:
+		for i, total := range lineTotals {
+			if eligible[i] {
+				discounts[i] = min(total.MulRate(promotion.Percentage), total)
+			}
+		}
+	case promotionModels.TypeFixedAmount:
+		weights := make([]money.Money, len(lineTotals))
+		var eligibleTotal money.Money
+		for i, total := range lineTotals {
+			if eligible[i] {
+				weights[i] = total
+				eligibleTotal += total
+			}
+		}
+		discounts = min(promotion.Amount, eligibleTotal).Allocate(weights)
+	}
+	return discounts
+}
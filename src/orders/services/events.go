@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"os"
+	"strconv"
+
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"github.com/svadikari/golang_fiber_orders/src/middleware"
+	"github.com/svadikari/golang_fiber_orders/src/orders/models"
+	"github.com/svadikari/golang_fiber_orders/src/orders/repository"
+	"github.com/svadikari/golang_fiber_orders/src/orders/schemas"
+)
+
+// eventSource identifies this service as the CloudEvents source of order events.
+func eventSource() string {
+	if source := os.Getenv("EVENT_SOURCE"); source != "" {
+		return source
+	}
+	return "/golang_fiber_orders/orders"
+}
+
+// enqueueOrderEvent writes an order event to the outbox within tx; the outbox relay publishes it.
+func (s *orderService) enqueueOrderEvent(ctx context.Context, tx repository.OrderRepository, eventType string, order *models.Order, previousStatus string) error {
+	orderId := strconv.FormatUint(uint64(order.ID), 10)
+	topic := middleware.OrdersTopic()
+	event := events.NewCloudEvent(ctx, eventType, eventSource(), "orders/"+orderId, schemas.OrderEventSchemaVersion)
+	orderEvent := toOrderEvent(order, previousStatus)
+	value, headers, err := s.serializer.Serialize(topic, event, &orderEvent)
+	if err != nil {
+		return err
+	}
+	return tx.EnqueueEvent(ctx, order.ID, topic, event, value, headers)
+}
+
+func toOrderEvent(order *models.Order, previousStatus string) schemas.OrderEvent {
+	orderEvent := schemas.OrderEvent{
+		OrderID:         order.ID,
+		UserID:          order.UserId,
+		Status:          order.Status,
+		PreviousStatus:  previousStatus,
+		TotalAmount:     order.TotalAmount,
+		Currency:        order.Currency,
+		Items:           make([]schemas.OrderEventItem, 0, len(order.OrderItems)),
+		CreatedAt:       order.CreatedAt,
+		UpdatedAt:       order.UpdatedAt,
+		Subtotal:        order.Subtotal,
+		TaxAmount:       order.TaxAmount,
+		TaxMode:         order.TaxMode,
+		DiscountAmount:  order.DiscountAmount,
+		CouponCode:      order.CouponCode,
+		ShippingMethod:  order.ShippingMethod,
+		ShippingCost:    order.ShippingCost,
+		ShippingAddress: toEventAddress(order.ShippingAddress),
+		BillingAddress:  toEventAddress(order.BillingAddress),
+		TrackingNumber:  order.TrackingNumber,
+		Carrier:         order.Carrier,
+	}
+	for _, item := range order.OrderItems {
+		orderEvent.Items = append(orderEvent.Items, schemas.OrderEventItem{
+			ProductID:      item.ProductID,
+			Quantity:       item.Quantity,
+			UnitPrice:      item.UnitPrice,
+			DiscountAmount: item.DiscountAmount,
+		})
+	}
+	for _, line := range order.TaxLines {
+		orderEvent.TaxLines = append(orderEvent.TaxLines, schemas.OrderEventTaxLine{
+			Name:          line.Name,
+			Region:        line.Region,
+			Category:      line.Category,
+			Rate:          line.Rate,
+			TaxableAmount: line.TaxableAmount,
+			TaxAmount:     line.TaxAmount,
+		})
+	}
+	return orderEvent
+}
+
+// toEventAddress returns nil for the empty addresses of orders placed without shipping.
+func toEventAddress(address models.Address) *schemas.OrderEventAddress {
+	if address == (models.Address{}) {
+		return nil
+	}
+	eventAddress := schemas.OrderEventAddress(address)
+	return &eventAddress
+}
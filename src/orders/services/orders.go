@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/currency"
+	"github.com/svadikari/golang_fiber_orders/src/middleware"
+	"github.com/svadikari/golang_fiber_orders/src/orders/models"
+	"github.com/svadikari/golang_fiber_orders/src/orders/repository"
+	"github.com/svadikari/golang_fiber_orders/src/orders/schemas"
+	"github.com/svadikari/golang_fiber_orders/src/pagination"
+	promotionModels "github.com/svadikari/golang_fiber_orders/src/promotions/models"
+	"github.com/svadikari/golang_fiber_orders/src/serialization"
+	"github.com/svadikari/golang_fiber_orders/src/shipping"
+	"github.com/svadikari/golang_fiber_orders/src/tax"
+)
+
+// systemActor is recorded in the status history when no caller identity is supplied.
+const systemActor = "system"
+
+var ErrOrderNotFound = repository.ErrOrderNotFound
+
+// TransitionError rejects a status change the order lifecycle does not allow.
+type TransitionError struct {
+	From schemas.OrderStatus
+	To   schemas.OrderStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("Order status cannot change from %s to %s", e.From, e.To)
+}
+
+// StockError rejects an order whose lines reference unknown products or exceed the available stock.
+// Lines holds one message per offending order line.
+type StockError struct {
+	ProductNotFound bool
+	Lines           []string
+}
+
+func (e *StockError) Error() string {
+	return strings.Join(e.Lines, ",")
+}
+
+// CurrencyError rejects an order whose lines name different currencies or reference products without
+// a price in the order currency. Lines holds one message per offending order line.
+type CurrencyError struct {
+	Lines []string
+}
+
+func (e *CurrencyError) Error() string {
+	return strings.Join(e.Lines, ",")
+}
+
+type OrderResponse struct {
+	Order models.Order `json:"order"`
+	User  any          `json:"user"`
+}
+
+type OrderService interface {
+	GetOrders(ctx context.Context, query schemas.OrderQuerySchema) (pagination.Page[models.Order], error)
+	GetOrder(ctx context.Context, id uint) (OrderResponse, error)
+	GetOrderHistory(ctx context.Context, id uint) ([]models.OrderStatusHistory, error)
+	GetOrderSummary(ctx context.Context, query schemas.OrderSummaryQuerySchema) (schemas.OrderSummary, error)
+	CreateOrder(ctx context.Context, orderPayload schemas.OrderSchema) (models.Order, error)
+	// CreateOrderTx is CreateOrder within the transaction of tx, which commits or rolls back the order.
+	CreateOrderTx(ctx context.Context, tx repository.OrderRepository, orderPayload schemas.OrderSchema) (models.Order, error)
+	UpdateOrderStatus(ctx context.Context, id uint, update schemas.OrderUpdateSchema) (models.Order, error)
+	DeleteOrder(ctx context.Context, id uint) error
+	GetShipments(ctx context.Context, orderId uint) ([]models.Shipment, error)
+	CreateShipment(ctx context.Context, orderId uint, payload schemas.ShipmentSchema) (models.Shipment, error)
+	DeliverShipment(ctx context.Context, orderId, shipmentId uint) (models.Shipment, error)
+	OrderWorkflow
+}
+
+type orderService struct {
+	Logger          *slog.Logger
+	orderRepository repository.OrderRepository
+	serializer      *serialization.EventSerializer
+	payments        middleware.PaymentService
+	users           middleware.UserService
+	rates           currency.Converter
+	taxes           tax.Calculator
+	shipping        shipping.Catalog
+}
+
+func NewOrderService(orderRepository repository.OrderRepository, serializer *serialization.EventSerializer,
+	payments middleware.PaymentService, users middleware.UserService, rates currency.Converter, taxes tax.Calculator, shippingMethods shipping.Catalog, logger *slog.Logger) OrderService {
+	logger = logger.With("service", "OrderService")
+	return &orderService{Logger: logger, orderRepository: orderRepository, serializer: serializer, payments: payments, users: users, rates: rates, taxes: taxes, shipping: shippingMethods}
+}
+
+func (s *orderService) GetOrders(ctx context.Context, query schemas.OrderQuerySchema) (pagination.Page[models.Order], error) {
+	query.Limit = pagination.Limit(query.Limit)
+	if query.Sort == "" {
+		query.Sort = schemas.DefaultOrderSort
+	}
+	return s.orderRepository.Find(ctx, query)
+}
+
+func (s *orderService) GetOrder(ctx context.Context, id uint) (OrderResponse, error) {
+	order, err := s.orderRepository.FindByID(ctx, id)
+	if err != nil {
+		return OrderResponse{}, err
+	}
+	return OrderResponse{Order: order, User: s.users.GetUser(order.UserId)}, nil
+}
+
+func (s *orderService) GetOrderHistory(ctx context.Context, id uint) ([]models.OrderStatusHistory, error) {
+	if _, err := s.orderRepository.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.orderRepository.History(ctx, id)
+}
+
+// GetOrderSummary totals the orders matching the filters per currency and converts the totals into
+// the report currency.
+func (s *orderService) GetOrderSummary(ctx context.Context, query schemas.OrderSummaryQuerySchema) (schemas.OrderSummary, error) {
+	if query.ReportCurrency == "" {
+		query.ReportCurrency = currency.Default()
+	}
+	totals, err := s.orderRepository.TotalsByCurrency(ctx, query.OrderFilters)
+	if err != nil {
+		return schemas.OrderSummary{}, err
+	}
+	summary := schemas.OrderSummary{ReportCurrency: query.ReportCurrency, ByCurrency: totals}
+	for i := range summary.ByCurrency {
+		total := &summary.ByCurrency[i]
+		total.ConvertedAmount, err = s.rates.Convert(ctx, total.TotalAmount, total.Currency, query.ReportCurrency)
+		if err != nil {
+			return schemas.OrderSummary{}, err
+		}
+		summary.OrderCount += total.OrderCount
+		summary.TotalAmount += total.ConvertedAmount
+	}
+	return summary, nil
+}
+
+// CreateOrder reserves stock for every line, prices the lines from the catalog in the order currency,
+// redeems the coupon, taxes the discounted lines in the order's tax region, adds the shipping cost and saves
+// the order together with its first history entry and its order.created event.
+func (s *orderService) CreateOrder(ctx context.Context, orderPayload schemas.OrderSchema) (models.Order, error) {
+	var order models.Order
+	err := s.orderRepository.Transaction(ctx, func(tx repository.OrderRepository) error {
+		var err error
+		order, err = s.CreateOrderTx(ctx, tx, orderPayload)
+		return err
+	})
+	if err != nil {
+		return models.Order{}, err
+	}
+	s.Logger.Info("Created order", "orderId", order.ID, "totalAmount", order.TotalAmount, "taxAmount", order.TaxAmount,
+		"discountAmount", order.DiscountAmount, "currency", order.Currency)
+	return order, nil
+}
+
+func (s *orderService) CreateOrderTx(ctx context.Context, tx repository.OrderRepository, orderPayload schemas.OrderSchema) (models.Order, error) {
+	code, err := orderCurrency(orderPayload)
+	if err != nil {
+		return models.Order{}, err
+	}
+	order := models.Order{UserId: orderPayload.UserId, Currency: code, TaxRegion: orderPayload.TaxRegion, Status: string(schemas.StatusNew),
+		ShippingMethod: strings.ToLower(orderPayload.ShippingMethod)}
+	if order.TaxRegion == "" {
+		order.TaxRegion = tax.DefaultRegion()
+	}
+	for _, item := range orderPayload.OrderItems {
+		order.OrderItems = append(order.OrderItems, models.OrderItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	products, err := reserveStock(ctx, tx, order.OrderItems)
+	if err != nil {
+		return models.Order{}, err
+	}
+	if err := applyCatalogPrices(&order, products); err != nil {
+		return models.Order{}, err
+	}
+	promotion, err := applyCoupon(ctx, tx, &order, products, orderPayload.CouponCode, time.Now())
+	if err != nil {
+		return models.Order{}, err
+	}
+	if err := s.applyTaxes(ctx, &order); err != nil {
+		return models.Order{}, err
+	}
+	if err := s.applyShipping(ctx, &order, orderPayload.ShippingSchema, promotion); err != nil {
+		return models.Order{}, err
+	}
+	if err := tx.Create(ctx, &order); err != nil {
+		return models.Order{}, err
+	}
+	if promotion != nil {
+		err = tx.AddRedemption(ctx, &promotionModels.PromotionRedemption{PromotionID: promotion.ID, UserID: order.UserId, OrderID: order.ID})
+		if err != nil {
+			return models.Order{}, err
+		}
+	}
+	err = tx.AddHistory(ctx, &models.OrderStatusHistory{
+		OrderID:   order.ID,
+		ToStatus:  order.Status,
+		ChangedBy: systemActor,
+		Reason:    "Order created",
+	})
+	if err != nil {
+		return models.Order{}, err
+	}
+	if err := s.enqueueOrderEvent(ctx, tx, schemas.EventOrderCreated, &order, ""); err != nil {
+		return models.Order{}, err
+	}
+	return order, nil
+}
+
+// UpdateOrderStatus moves an order along its lifecycle, rejecting transitions it does not allow. Shipping
+// an order ships every line not shipped yet and delivering it delivers every shipment; the order then moves
+// to the status derived from its lines.
+func (s *orderService) UpdateOrderStatus(ctx context.Context, id uint, update schemas.OrderUpdateSchema) (models.Order, error) {
+	var order models.Order
+	err := s.orderRepository.Transaction(ctx, func(tx repository.OrderRepository) error {
+		var err error
+		order, err = tx.LockByID(ctx, id, false)
+		if err != nil {
+			return err
+		}
+		currentStatus := schemas.OrderStatus(order.Status)
+		if !currentStatus.CanTransitionTo(update.Status) {
+			return &TransitionError{From: currentStatus, To: update.Status}
+		}
+		changedBy := update.ChangedBy
+		if changedBy == "" {
+			changedBy = systemActor
+		}
+		switch update.Status {
+		case schemas.StatusShipped:
+			return s.shipRemaining(ctx, tx, &order, update, changedBy)
+		case schemas.StatusDelivered:
+			return s.deliverAll(ctx, tx, &order, update, changedBy)
+		}
+		return s.transitionOrder(ctx, tx, &order, update.Status, changedBy, update.Reason)
+	})
+	if err != nil {
+		return models.Order{}, err
+	}
+	s.Logger.Info("Updated order status", "orderId", order.ID, "status", order.Status)
+	return order, nil
+}
+
+// DeleteOrder soft-deletes an order, giving back the stock it still holds in the same transaction.
+func (s *orderService) DeleteOrder(ctx context.Context, id uint) error {
+	return s.orderRepository.Transaction(ctx, func(tx repository.OrderRepository) error {
+		order, err := tx.LockByID(ctx, id, false)
+		if err != nil {
+			return err
+		}
+		if holdsStock(schemas.OrderStatus(order.Status)) {
+			if err := releaseOrderStock(ctx, tx, &order); err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(ctx, &order); err != nil {
+			return err
+		}
+		return s.enqueueOrderEvent(ctx, tx, schemas.EventOrderDeleted, &order, "")
+	})
+}
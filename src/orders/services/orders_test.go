@@ -0,0 +1,1156 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/svadikari/golang_fiber_orders/src/currency"
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"github.com/svadikari/golang_fiber_orders/src/middleware"
+	"github.com/svadikari/golang_fiber_orders/src/money"
+	"github.com/svadikari/golang_fiber_orders/src/orders/models"
+	"github.com/svadikari/golang_fiber_orders/src/orders/repository"
+	"github.com/svadikari/golang_fiber_orders/src/orders/schemas"
+	"github.com/svadikari/golang_fiber_orders/src/pagination"
+	productModels "github.com/svadikari/golang_fiber_orders/src/products/models"
+	promotionModels "github.com/svadikari/golang_fiber_orders/src/promotions/models"
+	"github.com/svadikari/golang_fiber_orders/src/serialization"
+	"github.com/svadikari/golang_fiber_orders/src/shipping"
+	"github.com/svadikari/golang_fiber_orders/src/tax"
+)
+
+type mockOrderRepository struct {
+	mock.Mock
+}
+
+// Transaction runs fn against the mock itself, so expectations cover the transactional calls too.
+func (m *mockOrderRepository) Transaction(ctx context.Context, fn func(tx repository.OrderRepository) error) error {
+	return fn(m)
+}
+
+func (m *mockOrderRepository) Find(ctx context.Context, query schemas.OrderQuerySchema) (pagination.Page[models.Order], error) {
+	args := m.Called(query)
+	return args.Get(0).(pagination.Page[models.Order]), args.Error(1)
+}
+
+func (m *mockOrderRepository) TotalsByCurrency(ctx context.Context, filters schemas.OrderFilters) ([]schemas.CurrencyTotal, error) {
+	args := m.Called(filters)
+	return args.Get(0).([]schemas.CurrencyTotal), args.Error(1)
+}
+
+func (m *mockOrderRepository) FindByID(ctx context.Context, id uint) (models.Order, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.Order), args.Error(1)
+}
+
+func (m *mockOrderRepository) LockByID(ctx context.Context, id uint, includeDeleted bool) (models.Order, error) {
+	args := m.Called(id, includeDeleted)
+	return args.Get(0).(models.Order), args.Error(1)
+}
+
+func (m *mockOrderRepository) History(ctx context.Context, orderId uint) ([]models.OrderStatusHistory, error) {
+	args := m.Called(orderId)
+	return args.Get(0).([]models.OrderStatusHistory), args.Error(1)
+}
+
+func (m *mockOrderRepository) Create(ctx context.Context, order *models.Order) error {
+	order.ID = 1
+	return m.Called(order).Error(0)
+}
+
+func (m *mockOrderRepository) Save(ctx context.Context, order *models.Order) error {
+	return m.Called(order).Error(0)
+}
+
+func (m *mockOrderRepository) Delete(ctx context.Context, order *models.Order) error {
+	return m.Called(order).Error(0)
+}
+
+func (m *mockOrderRepository) AddHistory(ctx context.Context, entry *models.OrderStatusHistory) error {
+	return m.Called(entry).Error(0)
+}
+
+func (m *mockOrderRepository) SaveItem(ctx context.Context, item *models.OrderItem) error {
+	return m.Called(item).Error(0)
+}
+
+func (m *mockOrderRepository) Shipments(ctx context.Context, orderId uint) ([]models.Shipment, error) {
+	args := m.Called(orderId)
+	return args.Get(0).([]models.Shipment), args.Error(1)
+}
+
+func (m *mockOrderRepository) FindShipment(ctx context.Context, orderId, shipmentId uint) (models.Shipment, error) {
+	args := m.Called(orderId, shipmentId)
+	return args.Get(0).(models.Shipment), args.Error(1)
+}
+
+func (m *mockOrderRepository) CreateShipment(ctx context.Context, shipment *models.Shipment) error {
+	shipment.ID = 5
+	return m.Called(shipment).Error(0)
+}
+
+func (m *mockOrderRepository) SaveShipment(ctx context.Context, shipment *models.Shipment) error {
+	return m.Called(shipment).Error(0)
+}
+
+func (m *mockOrderRepository) LockProducts(ctx context.Context, ids []uint) (map[uint]productModels.Product, error) {
+	args := m.Called(ids)
+	return args.Get(0).(map[uint]productModels.Product), args.Error(1)
+}
+
+func (m *mockOrderRepository) AdjustStock(ctx context.Context, productId uint, delta int) error {
+	return m.Called(productId, delta).Error(0)
+}
+
+func (m *mockOrderRepository) MarkStockReleased(ctx context.Context, order *models.Order) error {
+	return m.Called(order).Error(0)
+}
+
+func (m *mockOrderRepository) LockPromotion(ctx context.Context, code string) (promotionModels.Promotion, error) {
+	args := m.Called(code)
+	return args.Get(0).(promotionModels.Promotion), args.Error(1)
+}
+
+func (m *mockOrderRepository) CountRedemptions(ctx context.Context, promotionId, userId uint) (int64, error) {
+	args := m.Called(promotionId, userId)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockOrderRepository) AddRedemption(ctx context.Context, redemption *promotionModels.PromotionRedemption) error {
+	return m.Called(redemption).Error(0)
+}
+
+func (m *mockOrderRepository) EnqueueEvent(ctx context.Context, orderId uint, topic string, event events.CloudEvent, value []byte, headers map[string]string) error {
+	return m.Called(orderId, event.Type, value).Error(0)
+}
+
+func (m *mockOrderRepository) MarkProcessed(ctx context.Context, consumer string, msg events.Message) (bool, error) {
+	args := m.Called(consumer)
+	return args.Bool(0), args.Error(1)
+}
+
+type mockPaymentService struct {
+	mock.Mock
+}
+
+func (m *mockPaymentService) ConfirmPayment(orderId uint, userId uint, amount money.Money) (bool, error) {
+	args := m.Called(orderId, userId, amount)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockPaymentService) RefundPayment(orderId uint) error {
+	return m.Called(orderId).Error(0)
+}
+
+type mockUserService struct {
+	mock.Mock
+}
+
+func (m *mockUserService) GetUser(userId uint) middleware.User {
+	return m.Called(userId).Get(0).(middleware.User)
+}
+
+type mockConverter struct {
+	mock.Mock
+}
+
+func (m *mockConverter) Convert(ctx context.Context, amount money.Money, from, to string) (money.Money, error) {
+	args := m.Called(amount, from, to)
+	return args.Get(0).(money.Money), args.Error(1)
+}
+
+// fixedTaxes taxes order lines with a fixed set of rates.
+type fixedTaxes struct {
+	rates []tax.TaxRate
+	mode  tax.Mode
+}
+
+func (f fixedTaxes) Calculate(ctx context.Context, region string, lines []tax.Line) (tax.Breakdown, error) {
+	return tax.Calculate(f.rates, region, f.mode, lines), nil
+}
+
+// fixedShipping offers a fixed set of shipping methods.
+type fixedShipping []shipping.Method
+
+func (f fixedShipping) Find(ctx context.Context, code string) (shipping.Method, error) {
+	for _, method := range f {
+		if method.Code == code {
+			return method, nil
+		}
+	}
+	return shipping.Method{}, shipping.ErrMethodNotFound
+}
+
+// testDependencies are the collaborators of an order service under test that are not mocks.
+type testDependencies struct {
+	taxes    tax.Calculator
+	shipping fixedShipping
+}
+
+type testOption func(*testDependencies)
+
+func withTaxes(taxes tax.Calculator) testOption {
+	return func(deps *testDependencies) { deps.taxes = taxes }
+}
+
+func withShipping(methods fixedShipping) testOption {
+	return func(deps *testDependencies) { deps.shipping = methods }
+}
+
+// newTestOrderService builds an order service on mocks with exclusive taxes without rates and no shipping
+// methods, unless options replace them.
+func newTestOrderService(options ...testOption) (OrderService, *mockOrderRepository, *mockPaymentService, *mockUserService, *mockConverter) {
+	deps := testDependencies{taxes: fixedTaxes{mode: tax.ModeExclusive}}
+	for _, option := range options {
+		option(&deps)
+	}
+	mockRepo := new(mockOrderRepository)
+	payments := new(mockPaymentService)
+	users := new(mockUserService)
+	rates := new(mockConverter)
+	serializer, _ := serialization.NewEventSerializer(serialization.FormatJSON, nil)
+	return NewOrderService(mockRepo, serializer, payments, users, rates, deps.taxes, deps.shipping, slog.Default()), mockRepo, payments, users, rates
+}
+
+// eventData decodes the order event carried by a structured-mode CloudEvent.
+func eventData(t *testing.T, value []byte) schemas.OrderEvent {
+	var event events.CloudEvent
+	var orderEvent schemas.OrderEvent
+	assert.NoError(t, json.Unmarshal(value, &event))
+	assert.NoError(t, json.Unmarshal(event.Data, &orderEvent))
+	return orderEvent
+}
+
+func TestCreateOrder(t *testing.T) {
+
+	payload := schemas.OrderSchema{UserId: 7, OrderItems: []schemas.OrderItemSchema{
+		{ProductID: 2, Quantity: 3},
+		{ProductID: 1, Quantity: 1},
+	}}
+
+	t.Run("Lines are priced from the catalog and stock is reserved", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		products := map[uint]productModels.Product{
+			1: {Price: money.MustParse("5.50"), Currency: "USD", Stock: 10},
+			2: {Price: money.MustParse("2.25"), Currency: "USD", Stock: 3},
+		}
+		mockRepo.On("LockProducts", []uint{1, 2}).Return(products, nil).Once()
+		mockRepo.On("AdjustStock", uint(1), -1).Return(nil).Once()
+		mockRepo.On("AdjustStock", uint(2), -3).Return(nil).Once()
+		mockRepo.On("Create", mock.Anything).Return(nil).Once()
+		mockRepo.On("AddHistory", mock.MatchedBy(func(entry *models.OrderStatusHistory) bool {
+			return entry.ToStatus == string(schemas.StatusNew) && entry.ChangedBy == systemActor
+		})).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(1), schemas.EventOrderCreated, mock.Anything).Return(nil).Once()
+
+		order, err := service.CreateOrder(context.Background(), payload)
+		assert.NoError(t, err)
+		assert.Equal(t, money.MustParse("2.25"), order.OrderItems[0].UnitPrice)
+		assert.Equal(t, money.MustParse("5.50"), order.OrderItems[1].UnitPrice)
+		assert.Equal(t, money.MustParse("12.25"), order.TotalAmount)
+		assert.Equal(t, money.MustParse("12.25"), order.Subtotal)
+		assert.Zero(t, order.TaxAmount)
+		assert.Equal(t, "USD", order.Currency)
+		assert.Equal(t, string(schemas.StatusNew), order.Status)
+		mockRepo.AssertExpectations(t)
+
+		orderEvent := eventData(t, mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(2).([]byte))
+		assert.Equal(t, uint(7), orderEvent.UserID)
+		assert.Equal(t, money.MustParse("12.25"), orderEvent.TotalAmount)
+		assert.Equal(t, "USD", orderEvent.Currency)
+	})
+
+	t.Run("Lines are priced in the order currency", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		products := map[uint]productModels.Product{
+			1: {Price: money.MustParse("5.50"), Currency: "USD", Stock: 10,
+				Prices: []productModels.ProductPrice{{Currency: "EUR", Amount: money.MustParse("5")}}},
+			2: {Price: money.MustParse("2"), Currency: "EUR", Stock: 3},
+		}
+		mockRepo.On("LockProducts", []uint{1, 2}).Return(products, nil).Once()
+		mockRepo.On("AdjustStock", mock.Anything, mock.Anything).Return(nil).Twice()
+		mockRepo.On("Create", mock.Anything).Return(nil).Once()
+		mockRepo.On("AddHistory", mock.Anything).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(1), schemas.EventOrderCreated, mock.Anything).Return(nil).Once()
+
+		order, err := service.CreateOrder(context.Background(), schemas.OrderSchema{UserId: 7, OrderItems: []schemas.OrderItemSchema{
+			{ProductID: 2, Quantity: 3, Currency: "EUR"},
+			{ProductID: 1, Quantity: 1},
+		}})
+		assert.NoError(t, err)
+		assert.Equal(t, "EUR", order.Currency)
+		assert.Equal(t, money.MustParse("11"), order.TotalAmount)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Lines are taxed in the order's tax region", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService(withTaxes(fixedTaxes{mode: tax.ModeExclusive, rates: []tax.TaxRate{
+			{Region: "US", Category: "standard", Name: "Federal", Rate: money.MustParseRate("0")},
+			{Region: "US-CA", Category: "standard", Name: "CA sales tax", Rate: money.MustParseRate("0.0725")},
+			{Region: "US-CA", Category: "food", Name: "CA food", Rate: money.MustParseRate("0")},
+		}}))
+		products := map[uint]productModels.Product{
+			1: {Price: money.MustParse("5.50"), Currency: "USD", TaxCategory: "standard", Stock: 10},
+			2: {Price: money.MustParse("2.25"), Currency: "USD", TaxCategory: "food", Stock: 3},
+		}
+		mockRepo.On("LockProducts", []uint{1, 2}).Return(products, nil).Once()
+		mockRepo.On("AdjustStock", mock.Anything, mock.Anything).Return(nil).Twice()
+		mockRepo.On("Create", mock.Anything).Return(nil).Once()
+		mockRepo.On("AddHistory", mock.Anything).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(1), schemas.EventOrderCreated, mock.Anything).Return(nil).Once()
+
+		order, err := service.CreateOrder(context.Background(), schemas.OrderSchema{UserId: 7, TaxRegion: "US-CA", OrderItems: payload.OrderItems})
+		assert.NoError(t, err)
+		assert.Equal(t, "US-CA", order.TaxRegion)
+		assert.Equal(t, string(tax.ModeExclusive), order.TaxMode)
+		assert.Equal(t, money.MustParse("12.25"), order.Subtotal)
+		assert.Equal(t, money.MustParse("0.40"), order.TaxAmount)
+		assert.Equal(t, money.MustParse("12.65"), order.TotalAmount)
+		assert.Equal(t, "food", order.OrderItems[0].TaxCategory)
+		assert.Zero(t, order.OrderItems[0].TaxAmount)
+		assert.Equal(t, money.MustParse("0.40"), order.OrderItems[1].TaxAmount)
+		assert.Len(t, order.TaxLines, 3)
+
+		orderEvent := eventData(t, mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(2).([]byte))
+		assert.Equal(t, money.MustParse("12.25"), orderEvent.Subtotal)
+		assert.Equal(t, money.MustParse("0.40"), orderEvent.TaxAmount)
+		assert.Equal(t, money.MustParse("12.65"), orderEvent.TotalAmount)
+		assert.Equal(t, "CA sales tax", orderEvent.TaxLines[2].Name)
+	})
+
+	t.Run("Lines in different currencies are rejected", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+
+		_, err := service.CreateOrder(context.Background(), schemas.OrderSchema{UserId: 7, Currency: "USD", OrderItems: []schemas.OrderItemSchema{
+			{ProductID: 2, Quantity: 3, Currency: "EUR"},
+			{ProductID: 1, Quantity: 1, Currency: "USD"},
+		}})
+		var currencyErr *CurrencyError
+		assert.ErrorAs(t, err, &currencyErr)
+		assert.Equal(t, []string{"order_items[0]: currency EUR differs from the order currency USD"}, currencyErr.Lines)
+		mockRepo.AssertNotCalled(t, "LockProducts", mock.Anything)
+	})
+
+	t.Run("Products without a price in the order currency are rejected", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		products := map[uint]productModels.Product{
+			1: {Price: money.MustParse("5.50"), Currency: "USD", Stock: 10},
+			2: {Price: money.MustParse("2.25"), Currency: "USD", Stock: 3},
+		}
+		mockRepo.On("LockProducts", []uint{1, 2}).Return(products, nil).Once()
+		mockRepo.On("AdjustStock", mock.Anything, mock.Anything).Return(nil).Twice()
+
+		_, err := service.CreateOrder(context.Background(), schemas.OrderSchema{UserId: 7, Currency: "GBP", OrderItems: payload.OrderItems})
+		var currencyErr *CurrencyError
+		assert.ErrorAs(t, err, &currencyErr)
+		assert.Equal(t, []string{
+			"order_items[0]: product 2 has no price in GBP",
+			"order_items[1]: product 1 has no price in GBP",
+		}, currencyErr.Lines)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Every short or unknown line is reported", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		products := map[uint]productModels.Product{2: {Price: money.MustParse("2.25"), Stock: 1}}
+		mockRepo.On("LockProducts", []uint{1, 2}).Return(products, nil).Once()
+
+		_, err := service.CreateOrder(context.Background(), payload)
+		var stockErr *StockError
+		assert.ErrorAs(t, err, &stockErr)
+		assert.True(t, stockErr.ProductNotFound)
+		assert.Equal(t, []string{
+			"order_items[0]: insufficient stock for product 2 (requested 3 available 1)",
+			"order_items[1]: product 1 not found",
+		}, stockErr.Lines)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestUpdateOrderStatus(t *testing.T) {
+
+	t.Run("Illegal transitions are rejected", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		order := models.Order{Status: string(schemas.StatusDelivered)}
+		order.ID = 3
+		mockRepo.On("LockByID", uint(3), false).Return(order, nil).Once()
+
+		_, err := service.UpdateOrderStatus(context.Background(), 3, schemas.OrderUpdateSchema{Status: schemas.StatusNew})
+		var transitionErr *TransitionError
+		assert.ErrorAs(t, err, &transitionErr)
+		assert.Equal(t, "Order status cannot change from DELIVERED to NEW", err.Error())
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+	})
+
+	t.Run("Allowed transitions are recorded and published", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		order := models.Order{Status: string(schemas.StatusConfirmed)}
+		order.ID = 3
+		mockRepo.On("LockByID", uint(3), false).Return(order, nil).Once()
+		mockRepo.On("Save", mock.Anything).Return(nil).Once()
+		mockRepo.On("AddHistory", mock.MatchedBy(func(entry *models.OrderStatusHistory) bool {
+			return entry.FromStatus == "CONFIRMED" && entry.ToStatus == "SHIPPED" && entry.ChangedBy == "warehouse"
+		})).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(3), schemas.EventOrderStatusChanged, mock.Anything).Return(nil).Once()
+
+		updated, err := service.UpdateOrderStatus(context.Background(), 3, schemas.OrderUpdateSchema{Status: schemas.StatusShipped, ChangedBy: "warehouse"})
+		assert.NoError(t, err)
+		assert.Equal(t, string(schemas.StatusShipped), updated.Status)
+		mockRepo.AssertExpectations(t)
+
+		orderEvent := eventData(t, mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(2).([]byte))
+		assert.Equal(t, "CONFIRMED", orderEvent.PreviousStatus)
+	})
+
+	t.Run("Shipped orders record their tracking details", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		order := models.Order{Status: string(schemas.StatusConfirmed)}
+		order.ID = 3
+		mockRepo.On("LockByID", uint(3), false).Return(order, nil).Once()
+		mockRepo.On("Save", mock.Anything).Return(nil).Once()
+		mockRepo.On("AddHistory", mock.Anything).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(3), schemas.EventOrderStatusChanged, mock.Anything).Return(nil).Once()
+
+		updated, err := service.UpdateOrderStatus(context.Background(), 3, schemas.OrderUpdateSchema{
+			Status: schemas.StatusShipped, TrackingNumber: "1Z999AA10123456784", Carrier: "UPS",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "1Z999AA10123456784", updated.TrackingNumber)
+		assert.Equal(t, "UPS", updated.Carrier)
+		assert.NotNil(t, updated.ShippedAt)
+
+		orderEvent := eventData(t, mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(2).([]byte))
+		assert.Equal(t, "1Z999AA10123456784", orderEvent.TrackingNumber)
+		assert.Equal(t, "UPS", orderEvent.Carrier)
+	})
+
+	t.Run("Unknown orders are reported", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		mockRepo.On("LockByID", uint(9), false).Return(models.Order{}, ErrOrderNotFound).Once()
+		_, err := service.UpdateOrderStatus(context.Background(), 9, schemas.OrderUpdateSchema{Status: schemas.StatusShipped})
+		assert.ErrorIs(t, err, ErrOrderNotFound)
+	})
+}
+
+func TestGetOrder(t *testing.T) {
+
+	t.Run("Order is returned with its user", func(t *testing.T) {
+		service, mockRepo, _, users, _ := newTestOrderService()
+		order := models.Order{UserId: 7, Status: string(schemas.StatusNew)}
+		order.ID = 1
+		user := middleware.User{ID: 7, Name: "Jane"}
+		mockRepo.On("FindByID", uint(1)).Return(order, nil).Once()
+		users.On("GetUser", uint(7)).Return(user).Once()
+
+		response, err := service.GetOrder(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, order, response.Order)
+		assert.Equal(t, user, response.User)
+	})
+}
+
+func TestConfirmPayment(t *testing.T) {
+
+	msg := events.Message{Headers: map[string]string{events.HeaderEventID: "event-1"}}
+	newOrder := func() models.Order {
+		order := models.Order{UserId: 7, TotalAmount: money.MustParse("12.25"), Status: string(schemas.StatusNew)}
+		order.ID = 1
+		return order
+	}
+
+	t.Run("Orders already processed are not charged again", func(t *testing.T) {
+		service, mockRepo, payments, _, _ := newTestOrderService()
+		order := newOrder()
+		order.Status = string(schemas.StatusConfirmed)
+		mockRepo.On("FindByID", uint(1)).Return(order, nil).Once()
+
+		assert.NoError(t, service.ConfirmPayment(context.Background(), msg, 1))
+		mockRepo.AssertNotCalled(t, "LockByID", mock.Anything, mock.Anything)
+		payments.AssertNotCalled(t, "ConfirmPayment", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Redelivered events are skipped", func(t *testing.T) {
+		service, mockRepo, payments, _, _ := newTestOrderService()
+		mockRepo.On("FindByID", uint(1)).Return(newOrder(), nil).Once()
+		payments.On("ConfirmPayment", uint(1), uint(7), money.MustParse("12.25")).Return(true, nil).Once()
+		mockRepo.On("MarkProcessed", confirmPaymentConsumer).Return(false, nil).Once()
+
+		assert.NoError(t, service.ConfirmPayment(context.Background(), msg, 1))
+		mockRepo.AssertNotCalled(t, "LockByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("The payment is made before the order is locked", func(t *testing.T) {
+		service, mockRepo, payments, _, _ := newTestOrderService()
+		locked := false
+		mockRepo.On("FindByID", uint(1)).Return(newOrder(), nil).Once()
+		payments.On("ConfirmPayment", uint(1), uint(7), money.MustParse("12.25")).Run(func(mock.Arguments) {
+			assert.False(t, locked, "payment called while the order is locked")
+		}).Return(true, nil).Once()
+		mockRepo.On("MarkProcessed", confirmPaymentConsumer).Return(true, nil).Once()
+		mockRepo.On("LockByID", uint(1), false).Run(func(mock.Arguments) { locked = true }).Return(newOrder(), nil).Once()
+		mockRepo.On("Save", mock.MatchedBy(func(order *models.Order) bool {
+			return order.Status == string(schemas.StatusConfirmed)
+		})).Return(nil).Once()
+		mockRepo.On("AddHistory", mock.Anything).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(1), schemas.EventOrderStatusChanged, mock.Anything).Return(nil).Once()
+
+		assert.NoError(t, service.ConfirmPayment(context.Background(), msg, 1))
+		mockRepo.AssertExpectations(t)
+		payments.AssertExpectations(t)
+	})
+
+	t.Run("Orders cancelled during the payment are refunded", func(t *testing.T) {
+		service, mockRepo, payments, _, _ := newTestOrderService()
+		cancelled := newOrder()
+		cancelled.Status = string(schemas.StatusCancelled)
+		mockRepo.On("FindByID", uint(1)).Return(newOrder(), nil).Once()
+		charge := payments.On("ConfirmPayment", uint(1), uint(7), money.MustParse("12.25")).Return(true, nil).Once()
+		mockRepo.On("MarkProcessed", confirmPaymentConsumer).Return(true, nil).Once()
+		mockRepo.On("LockByID", uint(1), false).Return(cancelled, nil).Once()
+		payments.On("RefundPayment", uint(1)).Return(nil).Once().NotBefore(charge)
+
+		assert.NoError(t, service.ConfirmPayment(context.Background(), msg, 1))
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+		payments.AssertExpectations(t)
+	})
+
+	t.Run("Orders confirmed during the payment keep the charge", func(t *testing.T) {
+		service, mockRepo, payments, _, _ := newTestOrderService()
+		confirmed := newOrder()
+		confirmed.Status = string(schemas.StatusConfirmed)
+		mockRepo.On("FindByID", uint(1)).Return(newOrder(), nil).Once()
+		payments.On("ConfirmPayment", uint(1), uint(7), money.MustParse("12.25")).Return(true, nil).Once()
+		mockRepo.On("MarkProcessed", confirmPaymentConsumer).Return(true, nil).Once()
+		mockRepo.On("LockByID", uint(1), false).Return(confirmed, nil).Once()
+
+		assert.NoError(t, service.ConfirmPayment(context.Background(), msg, 1))
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything)
+		payments.AssertNotCalled(t, "RefundPayment", mock.Anything)
+	})
+
+	t.Run("A failed refund of a deleted order is retried on redelivery", func(t *testing.T) {
+		service, mockRepo, payments, _, _ := newTestOrderService()
+		mockRepo.On("FindByID", uint(1)).Return(newOrder(), nil).Once()
+		payments.On("ConfirmPayment", uint(1), uint(7), money.MustParse("12.25")).Return(true, nil).Once()
+		mockRepo.On("MarkProcessed", confirmPaymentConsumer).Return(true, nil).Once()
+		mockRepo.On("LockByID", uint(1), false).Return(models.Order{}, ErrOrderNotFound).Once()
+		payments.On("RefundPayment", uint(1)).Return(errors.New("payment service unavailable")).Once()
+
+		assert.EqualError(t, service.ConfirmPayment(context.Background(), msg, 1), "payment service unavailable")
+
+		mockRepo.On("FindByID", uint(1)).Return(models.Order{}, ErrOrderNotFound).Once()
+		payments.On("RefundPayment", uint(1)).Return(nil).Once()
+
+		assert.NoError(t, service.ConfirmPayment(context.Background(), msg, 1))
+		payments.AssertExpectations(t)
+		payments.AssertNumberOfCalls(t, "ConfirmPayment", 1)
+	})
+
+	t.Run("Declined payments cancel the order and release its stock", func(t *testing.T) {
+		service, mockRepo, payments, _, _ := newTestOrderService()
+		order := newOrder()
+		order.OrderItems = []models.OrderItem{{ProductID: 2, Quantity: 3}}
+		mockRepo.On("FindByID", uint(1)).Return(order, nil).Once()
+		payments.On("ConfirmPayment", uint(1), uint(7), money.MustParse("12.25")).Return(false, nil).Once()
+		mockRepo.On("MarkProcessed", confirmPaymentConsumer).Return(true, nil).Once()
+		mockRepo.On("LockByID", uint(1), false).Return(order, nil).Once()
+		mockRepo.On("AdjustStock", uint(2), 3).Return(nil).Once()
+		mockRepo.On("MarkStockReleased", mock.Anything).Return(nil).Once()
+		mockRepo.On("Save", mock.MatchedBy(func(order *models.Order) bool {
+			return order.Status == string(schemas.StatusCancelled)
+		})).Return(nil).Once()
+		mockRepo.On("AddHistory", mock.Anything).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(1), schemas.EventOrderStatusChanged, mock.Anything).Return(nil).Once()
+
+		assert.NoError(t, service.ConfirmPayment(context.Background(), msg, 1))
+		mockRepo.AssertExpectations(t)
+		payments.AssertExpectations(t)
+	})
+}
+
+func TestReleaseStock(t *testing.T) {
+
+	t.Run("Cancelled orders give their stock back", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		order := models.Order{Status: string(schemas.StatusConfirmed), OrderItems: []models.OrderItem{{ProductID: 2, Quantity: 3}}}
+		order.ID = 3
+		mockRepo.On("LockByID", uint(3), false).Return(order, nil).Once()
+		mockRepo.On("AdjustStock", uint(2), 3).Return(nil).Once()
+		mockRepo.On("MarkStockReleased", mock.Anything).Return(nil).Once()
+		mockRepo.On("Save", mock.Anything).Return(nil).Once()
+		mockRepo.On("AddHistory", mock.Anything).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(3), schemas.EventOrderStatusChanged, mock.Anything).Return(nil).Once()
+
+		_, err := service.UpdateOrderStatus(context.Background(), 3, schemas.OrderUpdateSchema{Status: schemas.StatusCancelled})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Deleted orders give back the stock they hold", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		order := models.Order{Status: string(schemas.StatusNew), OrderItems: []models.OrderItem{{ProductID: 2, Quantity: 3}}}
+		order.ID = 3
+		mockRepo.On("LockByID", uint(3), false).Return(order, nil).Once()
+		mockRepo.On("AdjustStock", uint(2), 3).Return(nil).Once()
+		mockRepo.On("MarkStockReleased", mock.Anything).Return(nil).Once()
+		mockRepo.On("Delete", mock.Anything).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(3), schemas.EventOrderDeleted, mock.Anything).Return(nil).Once()
+
+		assert.NoError(t, service.DeleteOrder(context.Background(), 3))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Deleted partially shipped orders give back their unshipped units", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		order := models.Order{Status: string(schemas.StatusPartiallyShipped), OrderItems: []models.OrderItem{
+			{ProductID: 2, Quantity: 3, ShippedQuantity: 1},
+			{ProductID: 1, Quantity: 2, ShippedQuantity: 2},
+		}}
+		order.ID = 3
+		mockRepo.On("LockByID", uint(3), false).Return(order, nil).Once()
+		mockRepo.On("AdjustStock", uint(2), 2).Return(nil).Once()
+		mockRepo.On("MarkStockReleased", mock.Anything).Return(nil).Once()
+		mockRepo.On("Delete", mock.Anything).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(3), schemas.EventOrderDeleted, mock.Anything).Return(nil).Once()
+
+		assert.NoError(t, service.DeleteOrder(context.Background(), 3))
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "AdjustStock", uint(1), mock.Anything)
+	})
+
+	t.Run("Deleted shipped orders keep the catalog stock unchanged", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		order := models.Order{Status: string(schemas.StatusShipped), OrderItems: []models.OrderItem{{ProductID: 2, Quantity: 3, ShippedQuantity: 3}}}
+		order.ID = 3
+		mockRepo.On("LockByID", uint(3), false).Return(order, nil).Once()
+		mockRepo.On("Delete", mock.Anything).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(3), schemas.EventOrderDeleted, mock.Anything).Return(nil).Once()
+
+		assert.NoError(t, service.DeleteOrder(context.Background(), 3))
+		mockRepo.AssertNotCalled(t, "AdjustStock", mock.Anything, mock.Anything)
+	})
+}
+
+func TestReserveStock(t *testing.T) {
+
+	t.Run("Every unknown or short line is reported and no stock is taken", func(t *testing.T) {
+		mockRepo := new(mockOrderRepository)
+		mockRepo.On("LockProducts", []uint{1, 2, 9}).Return(map[uint]productModels.Product{
+			1: {Stock: 5},
+			2: {Stock: 1},
+		}, nil).Once()
+
+		_, err := reserveStock(context.Background(), mockRepo, []models.OrderItem{
+			{ProductID: 2, Quantity: 2},
+			{ProductID: 9, Quantity: 1},
+			{ProductID: 1, Quantity: 3},
+			{ProductID: 1, Quantity: 3},
+		})
+		var stockErr *StockError
+		require.ErrorAs(t, err, &stockErr)
+		assert.True(t, stockErr.ProductNotFound)
+		assert.Equal(t, []string{
+			"order_items[0]: insufficient stock for product 2 (requested 2 available 1)",
+			"order_items[1]: product 9 not found",
+			"order_items[2]: insufficient stock for product 1 (requested 6 available 5)",
+			"order_items[3]: insufficient stock for product 1 (requested 6 available 5)",
+		}, stockErr.Lines)
+		mockRepo.AssertNotCalled(t, "AdjustStock", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Stock is taken once per product in product order", func(t *testing.T) {
+		mockRepo := new(mockOrderRepository)
+		products := map[uint]productModels.Product{1: {Stock: 5}, 2: {Stock: 1}}
+		mockRepo.On("LockProducts", []uint{1, 2}).Return(products, nil).Once()
+		first := mockRepo.On("AdjustStock", uint(1), -4).Return(nil).Once()
+		mockRepo.On("AdjustStock", uint(2), -1).Return(nil).Once().NotBefore(first)
+
+		locked, err := reserveStock(context.Background(), mockRepo, []models.OrderItem{
+			{ProductID: 2, Quantity: 1},
+			{ProductID: 1, Quantity: 3},
+			{ProductID: 1, Quantity: 1},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, products, locked)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestReleaseOrderStock(t *testing.T) {
+
+	t.Run("Stock is released only once", func(t *testing.T) {
+		mockRepo := new(mockOrderRepository)
+		order := &models.Order{OrderItems: []models.OrderItem{
+			{ProductID: 2, Quantity: 3},
+			{ProductID: 1, Quantity: 1},
+			{ProductID: 2, Quantity: 1},
+		}}
+		mockRepo.On("AdjustStock", uint(1), 1).Return(nil).Once()
+		mockRepo.On("AdjustStock", uint(2), 4).Return(nil).Once()
+		mockRepo.On("MarkStockReleased", order).Run(func(args mock.Arguments) {
+			args.Get(0).(*models.Order).StockReleased = true
+		}).Return(nil).Once()
+
+		assert.NoError(t, releaseOrderStock(context.Background(), mockRepo, order))
+		assert.True(t, order.StockReleased)
+		assert.NoError(t, releaseOrderStock(context.Background(), mockRepo, order))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Orders whose stock was released are not restocked", func(t *testing.T) {
+		mockRepo := new(mockOrderRepository)
+		order := &models.Order{StockReleased: true, OrderItems: []models.OrderItem{{ProductID: 2, Quantity: 3}}}
+
+		assert.NoError(t, releaseOrderStock(context.Background(), mockRepo, order))
+		mockRepo.AssertNotCalled(t, "AdjustStock", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "MarkStockReleased", mock.Anything)
+	})
+}
+
+func TestCreateOrderWithCoupon(t *testing.T) {
+
+	payload := schemas.OrderSchema{UserId: 7, CouponCode: "save10", OrderItems: []schemas.OrderItemSchema{
+		{ProductID: 2, Quantity: 3},
+		{ProductID: 1, Quantity: 1},
+	}}
+	products := map[uint]productModels.Product{
+		1: {Price: money.MustParse("5.50"), Currency: "USD", Category: "books", Stock: 10},
+		2: {Price: money.MustParse("2.25"), Currency: "USD", Category: "toys", Stock: 3},
+	}
+	yesterday, tomorrow := time.Now().Add(-24*time.Hour), time.Now().Add(24*time.Hour)
+
+	// pricedOrder expects an order to be priced and a coupon to be looked up.
+	pricedOrder := func(mockRepo *mockOrderRepository, promotion promotionModels.Promotion) {
+		promotion.ID = 4
+		mockRepo.On("LockProducts", []uint{1, 2}).Return(products, nil).Once()
+		mockRepo.On("AdjustStock", mock.Anything, mock.Anything).Return(nil).Twice()
+		mockRepo.On("LockPromotion", "SAVE10").Return(promotion, nil).Once()
+	}
+	// placedOrder expects a priced order to be saved with its redemption.
+	placedOrder := func(mockRepo *mockOrderRepository) {
+		mockRepo.On("Create", mock.Anything).Return(nil).Once()
+		mockRepo.On("AddRedemption", &promotionModels.PromotionRedemption{PromotionID: 4, UserID: 7, OrderID: 1}).Return(nil).Once()
+		mockRepo.On("AddHistory", mock.Anything).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(1), schemas.EventOrderCreated, mock.Anything).Return(nil).Once()
+	}
+
+	t.Run("Percentage discounts apply to the targeted lines before tax", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService(withTaxes(fixedTaxes{mode: tax.ModeExclusive, rates: []tax.TaxRate{
+			{Region: "DE", Category: "standard", Name: "VAT", Rate: money.MustParseRate("0.19")},
+		}}))
+		pricedOrder(mockRepo, promotionModels.Promotion{Code: "SAVE10", Type: promotionModels.TypePercentage,
+			Percentage: money.MustParseRate("0.10"), Active: true, StartsAt: &yesterday, EndsAt: &tomorrow,
+			Categories: []promotionModels.PromotionCategory{{Category: "Books"}}})
+		placedOrder(mockRepo)
+
+		withRegion := payload
+		withRegion.TaxRegion = "DE"
+		order, err := service.CreateOrder(context.Background(), withRegion)
+		assert.NoError(t, err)
+		assert.Zero(t, order.OrderItems[0].DiscountAmount)
+		assert.Equal(t, money.MustParse("0.55"), order.OrderItems[1].DiscountAmount)
+		assert.Equal(t, money.MustParse("0.55"), order.DiscountAmount)
+		assert.Equal(t, "SAVE10", order.CouponCode)
+		assert.Equal(t, uint(4), *order.PromotionID)
+		assert.Equal(t, money.MustParse("11.70"), order.Subtotal)
+		assert.Equal(t, money.MustParse("2.22"), order.TaxAmount)
+		assert.Equal(t, money.MustParse("13.92"), order.TotalAmount)
+		mockRepo.AssertExpectations(t)
+
+		orderEvent := eventData(t, mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(2).([]byte))
+		assert.Equal(t, "SAVE10", orderEvent.CouponCode)
+		assert.Equal(t, money.MustParse("0.55"), orderEvent.DiscountAmount)
+		assert.Equal(t, money.MustParse("0.55"), orderEvent.Items[1].DiscountAmount)
+	})
+
+	t.Run("Fixed amounts are allocated across the lines by line total", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		pricedOrder(mockRepo, promotionModels.Promotion{Code: "SAVE10", Type: promotionModels.TypeFixedAmount,
+			Amount: money.MustParse("5"), Currency: "USD", MinOrderTotal: money.MustParse("10"), UsageLimitPerUser: 1, Active: true})
+		mockRepo.On("CountRedemptions", uint(4), uint(7)).Return(int64(0), nil).Once()
+		placedOrder(mockRepo)
+
+		order, err := service.CreateOrder(context.Background(), payload)
+		assert.NoError(t, err)
+		assert.Equal(t, money.MustParse("2.76"), order.OrderItems[0].DiscountAmount)
+		assert.Equal(t, money.MustParse("2.24"), order.OrderItems[1].DiscountAmount)
+		assert.Equal(t, money.MustParse("7.25"), order.TotalAmount)
+		mockRepo.AssertExpectations(t)
+	})
+
+	rejections := []struct {
+		name      string
+		promotion promotionModels.Promotion
+		used      int64
+		message   string
+	}{
+		{"Inactive promotions are rejected",
+			promotionModels.Promotion{Type: promotionModels.TypePercentage, Percentage: money.MustParseRate("0.1")},
+			0, "coupon SAVE10 is not active"},
+		{"Promotions are rejected before their validity window",
+			promotionModels.Promotion{Type: promotionModels.TypePercentage, Active: true, StartsAt: &tomorrow},
+			0, "coupon SAVE10 is not valid yet"},
+		{"Expired promotions are rejected",
+			promotionModels.Promotion{Type: promotionModels.TypePercentage, Active: true, EndsAt: &yesterday},
+			0, "coupon SAVE10 has expired"},
+		{"Orders below the minimum total are rejected",
+			promotionModels.Promotion{Type: promotionModels.TypePercentage, Active: true, Currency: "USD", MinOrderTotal: money.MustParse("20")},
+			0, "coupon SAVE10 requires an order total of at least 20.00 USD"},
+		{"Orders in another currency are rejected",
+			promotionModels.Promotion{Type: promotionModels.TypeFixedAmount, Active: true, Currency: "EUR", Amount: money.MustParse("5")},
+			0, "coupon SAVE10 only applies to orders in EUR"},
+		{"Promotions targeting none of the lines are rejected",
+			promotionModels.Promotion{Type: promotionModels.TypePercentage, Active: true,
+				Products: []promotionModels.PromotionProduct{{ProductID: 9}}},
+			0, "coupon SAVE10 does not apply to any product in the order"},
+		{"Free shipping without a shipping method is rejected",
+			promotionModels.Promotion{Type: promotionModels.TypeFreeShipping, Active: true},
+			0, "coupon SAVE10 requires a shipping method"},
+		{"Users past the usage limit are rejected",
+			promotionModels.Promotion{Type: promotionModels.TypePercentage, Active: true, UsageLimitPerUser: 2},
+			2, "coupon SAVE10 has reached its usage limit for this user"},
+	}
+	for _, rejection := range rejections {
+		t.Run(rejection.name, func(t *testing.T) {
+			service, mockRepo, _, _, _ := newTestOrderService()
+			pricedOrder(mockRepo, rejection.promotion)
+			mockRepo.On("CountRedemptions", uint(4), uint(7)).Return(rejection.used, nil).Maybe()
+
+			_, err := service.CreateOrder(context.Background(), payload)
+			var couponErr *CouponError
+			assert.ErrorAs(t, err, &couponErr)
+			assert.Equal(t, rejection.message, err.Error())
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+		})
+	}
+
+	t.Run("Unknown coupon codes are rejected", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		mockRepo.On("LockProducts", []uint{1, 2}).Return(products, nil).Once()
+		mockRepo.On("AdjustStock", mock.Anything, mock.Anything).Return(nil).Twice()
+		mockRepo.On("LockPromotion", "SAVE10").Return(promotionModels.Promotion{}, repository.ErrPromotionNotFound).Once()
+
+		_, err := service.CreateOrder(context.Background(), payload)
+		assert.EqualError(t, err, "coupon SAVE10 does not exist")
+	})
+}
+
+func TestGetOrderSummary(t *testing.T) {
+
+	t.Run("Totals are converted into the report currency", func(t *testing.T) {
+		service, mockRepo, _, _, rates := newTestOrderService()
+		filters := schemas.OrderFilters{Status: "DELIVERED"}
+		mockRepo.On("TotalsByCurrency", filters).Return([]schemas.CurrencyTotal{
+			{Currency: "EUR", OrderCount: 2, TotalAmount: money.MustParse("92")},
+			{Currency: "USD", OrderCount: 1, TotalAmount: money.MustParse("10.50")},
+		}, nil).Once()
+		rates.On("Convert", money.MustParse("92"), "EUR", "USD").Return(money.MustParse("100"), nil).Once()
+		rates.On("Convert", money.MustParse("10.50"), "USD", "USD").Return(money.MustParse("10.50"), nil).Once()
+
+		summary, err := service.GetOrderSummary(context.Background(), schemas.OrderSummaryQuerySchema{OrderFilters: filters, ReportCurrency: "USD"})
+		assert.NoError(t, err)
+		assert.Equal(t, "USD", summary.ReportCurrency)
+		assert.Equal(t, int64(3), summary.OrderCount)
+		assert.Equal(t, money.MustParse("110.50"), summary.TotalAmount)
+		assert.Equal(t, money.MustParse("100"), summary.ByCurrency[0].ConvertedAmount)
+		rates.AssertExpectations(t)
+	})
+
+	t.Run("Missing exchange rates fail the summary", func(t *testing.T) {
+		service, mockRepo, _, _, rates := newTestOrderService()
+		mockRepo.On("TotalsByCurrency", schemas.OrderFilters{}).Return([]schemas.CurrencyTotal{
+			{Currency: "EUR", OrderCount: 1, TotalAmount: money.MustParse("5")},
+		}, nil).Once()
+		rates.On("Convert", money.MustParse("5"), "EUR", "GBP").Return(money.Money(0), currency.ErrNoRate).Once()
+
+		_, err := service.GetOrderSummary(context.Background(), schemas.OrderSummaryQuerySchema{ReportCurrency: "GBP"})
+		assert.ErrorIs(t, err, currency.ErrNoRate)
+	})
+}
+
+func TestCreateOrderWithShipping(t *testing.T) {
+
+	address := &schemas.AddressSchema{Name: "Ada Lovelace", Line1: "1 Market St", City: "San Francisco",
+		Region: "CA", PostalCode: "94105", Country: "US"}
+	payload := schemas.OrderSchema{UserId: 7, Currency: "EUR",
+		OrderItems:     []schemas.OrderItemSchema{{ProductID: 1, Quantity: 2}},
+		ShippingSchema: schemas.ShippingSchema{ShippingMethod: "Express", ShippingAddress: address},
+	}
+	products := map[uint]productModels.Product{
+		1: {Price: money.MustParse("10"), Currency: "EUR", Stock: 5},
+	}
+	methods := fixedShipping{
+		{Code: "express", Name: "Express", Cost: money.MustParse("15"), Currency: "USD", Active: true},
+		{Code: "freight", Name: "Freight", Cost: money.MustParse("90"), Currency: "EUR"},
+	}
+	pricedOrder := func(mockRepo *mockOrderRepository) {
+		mockRepo.On("LockProducts", []uint{1}).Return(products, nil).Once()
+		mockRepo.On("AdjustStock", uint(1), -2).Return(nil).Once()
+	}
+
+	t.Run("The shipping cost is converted into the order currency and added to the total", func(t *testing.T) {
+		service, mockRepo, _, _, rates := newTestOrderService(withShipping(methods))
+		pricedOrder(mockRepo)
+		rates.On("Convert", money.MustParse("15"), "USD", "EUR").Return(money.MustParse("13.80"), nil).Once()
+		mockRepo.On("Create", mock.Anything).Return(nil).Once()
+		mockRepo.On("AddHistory", mock.Anything).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(1), schemas.EventOrderCreated, mock.Anything).Return(nil).Once()
+
+		order, err := service.CreateOrder(context.Background(), payload)
+		assert.NoError(t, err)
+		assert.Equal(t, "express", order.ShippingMethod)
+		assert.Equal(t, money.MustParse("13.80"), order.ShippingCost)
+		assert.Equal(t, money.MustParse("20"), order.Subtotal)
+		assert.Equal(t, money.MustParse("33.80"), order.TotalAmount)
+		assert.Equal(t, "94105", order.ShippingAddress.PostalCode)
+		assert.Equal(t, order.ShippingAddress, order.BillingAddress, "billing defaults to the shipping address")
+
+		orderEvent := eventData(t, mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(2).([]byte))
+		assert.Equal(t, money.MustParse("13.80"), orderEvent.ShippingCost)
+		assert.Equal(t, "US", orderEvent.ShippingAddress.Country)
+	})
+
+	t.Run("Free shipping promotions waive the shipping cost", func(t *testing.T) {
+		service, mockRepo, _, _, rates := newTestOrderService(withShipping(methods))
+		pricedOrder(mockRepo)
+		promotion := promotionModels.Promotion{Code: "SHIPFREE", Type: promotionModels.TypeFreeShipping, Active: true}
+		promotion.ID = 4
+		mockRepo.On("LockPromotion", "SHIPFREE").Return(promotion, nil).Once()
+		rates.On("Convert", money.MustParse("15"), "USD", "EUR").Return(money.MustParse("13.80"), nil).Once()
+		mockRepo.On("Create", mock.Anything).Return(nil).Once()
+		mockRepo.On("AddRedemption", mock.Anything).Return(nil).Once()
+		mockRepo.On("AddHistory", mock.Anything).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(1), schemas.EventOrderCreated, mock.Anything).Return(nil).Once()
+
+		withCoupon := payload
+		withCoupon.CouponCode = "shipfree"
+		order, err := service.CreateOrder(context.Background(), withCoupon)
+		assert.NoError(t, err)
+		assert.Zero(t, order.ShippingCost)
+		assert.Zero(t, order.DiscountAmount, "free shipping discounts no line")
+		assert.Equal(t, "SHIPFREE", order.CouponCode)
+		assert.Equal(t, money.MustParse("20"), order.TotalAmount)
+	})
+
+	t.Run("Unknown and inactive shipping methods are rejected", func(t *testing.T) {
+		for _, method := range []string{"drone", "freight"} {
+			service, mockRepo, _, _, _ := newTestOrderService(withShipping(methods))
+			pricedOrder(mockRepo)
+
+			withMethod := payload
+			withMethod.ShippingMethod = method
+			_, err := service.CreateOrder(context.Background(), withMethod)
+			var shippingErr *ShippingError
+			assert.ErrorAs(t, err, &shippingErr)
+			assert.Equal(t, "shipping method "+method+" is not available", err.Error())
+			mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+		}
+	})
+
+	t.Run("Shipping methods without an exchange rate are rejected", func(t *testing.T) {
+		service, mockRepo, _, _, rates := newTestOrderService(withShipping(methods))
+		pricedOrder(mockRepo)
+		rates.On("Convert", money.MustParse("15"), "USD", "EUR").Return(money.Money(0), currency.ErrNoRate).Once()
+
+		_, err := service.CreateOrder(context.Background(), payload)
+		assert.EqualError(t, err, "shipping method express has no cost in EUR")
+	})
+}
+
+func TestShipments(t *testing.T) {
+
+	// shippableOrder is a confirmed order of 3 units of item 21 and 1 unit of item 22.
+	shippableOrder := func() models.Order {
+		order := models.Order{Status: string(schemas.StatusConfirmed), OrderItems: []models.OrderItem{
+			{ID: 21, ProductID: 2, Quantity: 3},
+			{ID: 22, ProductID: 1, Quantity: 1},
+		}}
+		order.ID = 3
+		return order
+	}
+	shipmentOf := func(items ...schemas.ShipmentItemSchema) schemas.ShipmentSchema {
+		return schemas.ShipmentSchema{TrackingNumber: "1Z999AA10123456784", Carrier: "UPS", Items: items}
+	}
+
+	t.Run("Shipping some lines partially ships the order", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		mockRepo.On("LockByID", uint(3), false).Return(shippableOrder(), nil).Once()
+		mockRepo.On("CreateShipment", mock.MatchedBy(func(shipment *models.Shipment) bool {
+			return shipment.OrderID == 3 && shipment.Status == string(schemas.ShipmentShipped) && len(shipment.Items) == 1
+		})).Return(nil).Once()
+		mockRepo.On("SaveItem", mock.MatchedBy(func(item *models.OrderItem) bool {
+			return item.ID == 21 && item.ShippedQuantity == 2 && item.FulfillmentStatus == string(schemas.FulfillmentPartiallyShipped)
+		})).Return(nil).Once()
+		mockRepo.On("SaveItem", mock.MatchedBy(func(item *models.OrderItem) bool {
+			return item.ID == 22 && item.FulfillmentStatus == string(schemas.FulfillmentUnfulfilled)
+		})).Return(nil).Once()
+		mockRepo.On("Save", mock.MatchedBy(func(order *models.Order) bool {
+			return order.Status == string(schemas.StatusPartiallyShipped) && order.ShippedAt == nil
+		})).Return(nil).Once()
+		mockRepo.On("AddHistory", mock.MatchedBy(func(entry *models.OrderStatusHistory) bool {
+			return entry.ToStatus == "PARTIALLY_SHIPPED" && entry.Reason == "Shipment 5 shipped"
+		})).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(3), schemas.EventOrderStatusChanged, mock.Anything).Return(nil).Once()
+
+		shipment, err := service.CreateShipment(context.Background(), 3, shipmentOf(schemas.ShipmentItemSchema{OrderItemID: 21, Quantity: 2}))
+		assert.NoError(t, err)
+		assert.Equal(t, uint(5), shipment.ID)
+		mockRepo.AssertExpectations(t)
+
+		orderEvent := eventData(t, mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(2).([]byte))
+		assert.Equal(t, "PARTIALLY_SHIPPED", orderEvent.Status)
+		assert.Equal(t, "UPS", orderEvent.Carrier)
+	})
+
+	t.Run("Shipping the remaining units ships the order", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		order := shippableOrder()
+		order.Status = string(schemas.StatusPartiallyShipped)
+		order.OrderItems[0].ShippedQuantity = 2
+		mockRepo.On("LockByID", uint(3), false).Return(order, nil).Once()
+		mockRepo.On("CreateShipment", mock.Anything).Return(nil).Once()
+		mockRepo.On("SaveItem", mock.Anything).Return(nil).Twice()
+		mockRepo.On("Save", mock.MatchedBy(func(order *models.Order) bool {
+			return order.Status == string(schemas.StatusShipped) && order.ShippedAt != nil
+		})).Return(nil).Once()
+		mockRepo.On("AddHistory", mock.Anything).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(3), schemas.EventOrderStatusChanged, mock.Anything).Return(nil).Once()
+
+		_, err := service.CreateShipment(context.Background(), 3, shipmentOf(
+			schemas.ShipmentItemSchema{OrderItemID: 21, Quantity: 1},
+			schemas.ShipmentItemSchema{OrderItemID: 22, Quantity: 1},
+		))
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown lines and quantities left to ship are checked", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		mockRepo.On("LockByID", uint(3), false).Return(shippableOrder(), nil).Once()
+
+		_, err := service.CreateShipment(context.Background(), 3, shipmentOf(
+			schemas.ShipmentItemSchema{OrderItemID: 21, Quantity: 4},
+			schemas.ShipmentItemSchema{OrderItemID: 99, Quantity: 1},
+		))
+		var shipmentErr *ShipmentError
+		assert.ErrorAs(t, err, &shipmentErr)
+		assert.Equal(t, []string{
+			"items[0]: only 3 of order item 21 left to ship",
+			"items[1]: order item 99 is not part of order 3",
+		}, shipmentErr.Lines)
+		mockRepo.AssertNotCalled(t, "CreateShipment", mock.Anything)
+	})
+
+	t.Run("Orders not confirmed yet cannot ship", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		order := shippableOrder()
+		order.Status = string(schemas.StatusNew)
+		mockRepo.On("LockByID", uint(3), false).Return(order, nil).Once()
+
+		_, err := service.CreateShipment(context.Background(), 3, shipmentOf(schemas.ShipmentItemSchema{OrderItemID: 22, Quantity: 1}))
+		var transitionErr *TransitionError
+		assert.ErrorAs(t, err, &transitionErr)
+		assert.Equal(t, "Order status cannot change from NEW to PARTIALLY_SHIPPED", err.Error())
+		mockRepo.AssertNotCalled(t, "CreateShipment", mock.Anything)
+	})
+
+	t.Run("Delivering one of the shipments partially delivers the order", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		order := shippableOrder()
+		order.Status = string(schemas.StatusShipped)
+		order.OrderItems[0].ShippedQuantity, order.OrderItems[1].ShippedQuantity = 3, 1
+		shipment := models.Shipment{ID: 5, OrderID: 3, Status: string(schemas.ShipmentShipped), Items: []models.ShipmentItem{{OrderItemID: 22, Quantity: 1}}}
+		mockRepo.On("LockByID", uint(3), false).Return(order, nil).Once()
+		mockRepo.On("FindShipment", uint(3), uint(5)).Return(shipment, nil).Once()
+		mockRepo.On("SaveShipment", mock.MatchedBy(func(shipment *models.Shipment) bool {
+			return shipment.Status == string(schemas.ShipmentDelivered) && shipment.DeliveredAt != nil
+		})).Return(nil).Once()
+		mockRepo.On("SaveItem", mock.MatchedBy(func(item *models.OrderItem) bool {
+			return item.ID == 21 && item.FulfillmentStatus == string(schemas.FulfillmentShipped)
+		})).Return(nil).Once()
+		mockRepo.On("SaveItem", mock.MatchedBy(func(item *models.OrderItem) bool {
+			return item.ID == 22 && item.DeliveredQuantity == 1 && item.FulfillmentStatus == string(schemas.FulfillmentDelivered)
+		})).Return(nil).Once()
+		mockRepo.On("Save", mock.MatchedBy(func(order *models.Order) bool {
+			return order.Status == string(schemas.StatusPartiallyDelivered)
+		})).Return(nil).Once()
+		mockRepo.On("AddHistory", mock.Anything).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(3), schemas.EventOrderStatusChanged, mock.Anything).Return(nil).Once()
+
+		delivered, err := service.DeliverShipment(context.Background(), 3, 5)
+		assert.NoError(t, err)
+		assert.Equal(t, string(schemas.ShipmentDelivered), delivered.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Shipments are delivered once", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		mockRepo.On("LockByID", uint(3), false).Return(shippableOrder(), nil).Once()
+		mockRepo.On("FindShipment", uint(3), uint(5)).Return(models.Shipment{ID: 5, Status: string(schemas.ShipmentDelivered)}, nil).Once()
+
+		_, err := service.DeliverShipment(context.Background(), 3, 5)
+		var shipmentErr *ShipmentError
+		assert.ErrorAs(t, err, &shipmentErr)
+		assert.Equal(t, "shipment 5 is already delivered", err.Error())
+	})
+
+	t.Run("Shipping an order ships every line left in one shipment", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		order := shippableOrder()
+		order.Status = string(schemas.StatusPartiallyShipped)
+		order.OrderItems[0].ShippedQuantity = 2
+		mockRepo.On("LockByID", uint(3), false).Return(order, nil).Once()
+		mockRepo.On("CreateShipment", mock.MatchedBy(func(shipment *models.Shipment) bool {
+			return len(shipment.Items) == 2 && shipment.Items[0] == models.ShipmentItem{OrderItemID: 21, Quantity: 1} &&
+				shipment.Items[1] == models.ShipmentItem{OrderItemID: 22, Quantity: 1}
+		})).Return(nil).Once()
+		mockRepo.On("SaveItem", mock.Anything).Return(nil).Twice()
+		mockRepo.On("Save", mock.Anything).Return(nil).Once()
+		mockRepo.On("AddHistory", mock.MatchedBy(func(entry *models.OrderStatusHistory) bool {
+			return entry.FromStatus == "PARTIALLY_SHIPPED" && entry.ToStatus == "SHIPPED" && entry.Reason == "Rest of the order"
+		})).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(3), schemas.EventOrderStatusChanged, mock.Anything).Return(nil).Once()
+
+		updated, err := service.UpdateOrderStatus(context.Background(), 3, schemas.OrderUpdateSchema{
+			Status: schemas.StatusShipped, Reason: "Rest of the order", TrackingNumber: "1Z999AA10123456785", Carrier: "UPS",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "1Z999AA10123456785", updated.TrackingNumber)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Delivering an order delivers every shipment", func(t *testing.T) {
+		service, mockRepo, _, _, _ := newTestOrderService()
+		order := shippableOrder()
+		order.Status = string(schemas.StatusShipped)
+		order.OrderItems[0].ShippedQuantity, order.OrderItems[1].ShippedQuantity = 3, 1
+		shipments := []models.Shipment{
+			{ID: 5, Status: string(schemas.ShipmentDelivered)},
+			{ID: 6, Status: string(schemas.ShipmentShipped)},
+		}
+		mockRepo.On("LockByID", uint(3), false).Return(order, nil).Once()
+		mockRepo.On("Shipments", uint(3)).Return(shipments, nil).Once()
+		mockRepo.On("SaveShipment", mock.MatchedBy(func(shipment *models.Shipment) bool {
+			return shipment.ID == 6 && shipment.Status == string(schemas.ShipmentDelivered)
+		})).Return(nil).Once()
+		mockRepo.On("SaveItem", mock.MatchedBy(func(item *models.OrderItem) bool {
+			return item.DeliveredQuantity == item.Quantity && item.FulfillmentStatus == string(schemas.FulfillmentDelivered)
+		})).Return(nil).Twice()
+		mockRepo.On("Save", mock.Anything).Return(nil).Once()
+		mockRepo.On("AddHistory", mock.Anything).Return(nil).Once()
+		mockRepo.On("EnqueueEvent", uint(3), schemas.EventOrderStatusChanged, mock.Anything).Return(nil).Once()
+
+		updated, err := service.UpdateOrderStatus(context.Background(), 3, schemas.OrderUpdateSchema{Status: schemas.StatusDelivered})
+		assert.NoError(t, err)
+		assert.Equal(t, string(schemas.StatusDelivered), updated.Status)
+		mockRepo.AssertExpectations(t)
+	})
+}
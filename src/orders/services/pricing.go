@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/svadikari/golang_fiber_orders/src/currency"
+	"github.com/svadikari/golang_fiber_orders/src/money"
+	"github.com/svadikari/golang_fiber_orders/src/orders/models"
+	"github.com/svadikari/golang_fiber_orders/src/orders/schemas"
+	productModels "github.com/svadikari/golang_fiber_orders/src/products/models"
+	"github.com/svadikari/golang_fiber_orders/src/tax"
+)
+
+// orderCurrency resolves the currency of a new order: the one requested, else the first one named by
+// a line, else the default currency. Every line naming a currency must name that one.
+func orderCurrency(orderPayload schemas.OrderSchema) (string, error) {
+	code := orderPayload.Currency
+	for _, item := range orderPayload.OrderItems {
+		if code == "" {
+			code = item.Currency
+		}
+	}
+	if code == "" {
+		code = currency.Default()
+	}
+
+	currencyErr := &CurrencyError{}
+	for i, item := range orderPayload.OrderItems {
+		if item.Currency != "" && item.Currency != code {
+			currencyErr.Lines = append(currencyErr.Lines, fmt.Sprintf("order_items[%d]: currency %s differs from the order currency %s", i, item.Currency, code))
+		}
+	}
+	if len(currencyErr.Lines) > 0 {
+		return "", currencyErr
+	}
+	return code, nil
+}
+
+// applyCatalogPrices snapshots the current catalog price in the order currency and the tax category into
+// every order line and sets the order total to the sum of the line totals. Every product must have a price
+// in that currency.
+func applyCatalogPrices(order *models.Order, products map[uint]productModels.Product) error {
+	currencyErr := &CurrencyError{}
+	var totalAmount money.Money
+	for i := range order.OrderItems {
+		item := &order.OrderItems[i]
+		price, ok := products[item.ProductID].PriceIn(order.Currency)
+		if !ok {
+			currencyErr.Lines = append(currencyErr.Lines, fmt.Sprintf("order_items[%d]: product %d has no price in %s", i, item.ProductID, order.Currency))
+			continue
+		}
+		item.UnitPrice = price
+		item.TaxCategory = products[item.ProductID].TaxCategory
+		totalAmount += item.UnitPrice.Mul(item.Quantity)
+	}
+	if len(currencyErr.Lines) > 0 {
+		return currencyErr
+	}
+	order.TotalAmount = totalAmount
+	return nil
+}
+
+// applyTaxes taxes the priced and discounted order lines in the tax region of the order and records the
+// breakdown: the tax of every line, a tax line per rate, the subtotal and the grand total.
+func (s *orderService) applyTaxes(ctx context.Context, order *models.Order) error {
+	lines := make([]tax.Line, 0, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		lines = append(lines, tax.Line{Category: item.TaxCategory, Amount: item.UnitPrice.Mul(item.Quantity) - item.DiscountAmount})
+	}
+	breakdown, err := s.taxes.Calculate(ctx, order.TaxRegion, lines)
+	if err != nil {
+		return err
+	}
+	for i := range order.OrderItems {
+		order.OrderItems[i].TaxAmount = breakdown.LineTaxes[i]
+	}
+	order.TaxLines = make([]models.OrderTaxLine, 0, len(breakdown.TaxLines))
+	for _, line := range breakdown.TaxLines {
+		order.TaxLines = append(order.TaxLines, models.OrderTaxLine{
+			Name:          line.Name,
+			Region:        line.Region,
+			Category:      line.Category,
+			Rate:          line.Rate,
+			TaxableAmount: line.TaxableAmount,
+			TaxAmount:     line.TaxAmount,
+		})
+	}
+	order.TaxMode = string(breakdown.Mode)
+	order.Subtotal = breakdown.Subtotal
+	order.TaxAmount = breakdown.TaxAmount
+	order.TotalAmount = breakdown.Total
+	return nil
+}
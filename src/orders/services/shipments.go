@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/orders/models"
+	"github.com/svadikari/golang_fiber_orders/src/orders/repository"
+	"github.com/svadikari/golang_fiber_orders/src/orders/schemas"
+)
+
+var ErrShipmentNotFound = repository.ErrShipmentNotFound
+
+// ShipmentError rejects a shipment of lines that are not part of the order or exceed the quantity left to
+// ship, and the delivery of a shipment already delivered. Lines holds one message per offending line.
+type ShipmentError struct {
+	Lines []string
+}
+
+func (e *ShipmentError) Error() string {
+	return strings.Join(e.Lines, ",")
+}
+
+func (s *orderService) GetShipments(ctx context.Context, orderId uint) ([]models.Shipment, error) {
+	if _, err := s.orderRepository.FindByID(ctx, orderId); err != nil {
+		return nil, err
+	}
+	return s.orderRepository.Shipments(ctx, orderId)
+}
+
+// CreateShipment ships quantities of the order lines and moves the order to the status derived from them.
+func (s *orderService) CreateShipment(ctx context.Context, orderId uint, payload schemas.ShipmentSchema) (models.Shipment, error) {
+	var shipment models.Shipment
+	err := s.orderRepository.Transaction(ctx, func(tx repository.OrderRepository) error {
+		order, err := tx.LockByID(ctx, orderId, false)
+		if err != nil {
+			return err
+		}
+		shipment = models.Shipment{OrderID: order.ID, TrackingNumber: payload.TrackingNumber, Carrier: payload.Carrier}
+		for _, item := range payload.Items {
+			shipment.Items = append(shipment.Items, models.ShipmentItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity})
+		}
+		return s.ship(ctx, tx, &order, &shipment, schemas.OrderStatus(order.Status), systemActor, "")
+	})
+	if err != nil {
+		return models.Shipment{}, err
+	}
+	s.Logger.Info("Created shipment", "orderId", orderId, "shipmentId", shipment.ID, "carrier", shipment.Carrier)
+	return shipment, nil
+}
+
+// DeliverShipment marks a shipment delivered and moves its order to the status derived from its lines.
+func (s *orderService) DeliverShipment(ctx context.Context, orderId, shipmentId uint) (models.Shipment, error) {
+	var shipment models.Shipment
+	err := s.orderRepository.Transaction(ctx, func(tx repository.OrderRepository) error {
+		order, err := tx.LockByID(ctx, orderId, false)
+		if err != nil {
+			return err
+		}
+		shipment, err = tx.FindShipment(ctx, orderId, shipmentId)
+		if err != nil {
+			return err
+		}
+		if shipment.Status == string(schemas.ShipmentDelivered) {
+			return &ShipmentError{Lines: []string{fmt.Sprintf("shipment %d is already delivered", shipment.ID)}}
+		}
+		items := orderItemsByID(&order)
+		for _, line := range shipment.Items {
+			if item, ok := items[line.OrderItemID]; ok {
+				item.DeliveredQuantity += line.Quantity
+			}
+		}
+		next, err := nextStatus(&order, schemas.OrderStatus(order.Status))
+		if err != nil {
+			return err
+		}
+		markDelivered(&shipment, time.Now())
+		if err := tx.SaveShipment(ctx, &shipment); err != nil {
+			return err
+		}
+		return s.saveFulfillment(ctx, tx, &order, next, systemActor, fmt.Sprintf("Shipment %d delivered", shipment.ID))
+	})
+	if err != nil {
+		return models.Shipment{}, err
+	}
+	s.Logger.Info("Delivered shipment", "orderId", orderId, "shipmentId", shipment.ID)
+	return shipment, nil
+}
+
+// shipRemaining ships every unit not shipped yet in one shipment. Orders without lines only record the
+// tracking details.
+func (s *orderService) shipRemaining(ctx context.Context, tx repository.OrderRepository, order *models.Order, update schemas.OrderUpdateSchema, changedBy string) error {
+	shipment := models.Shipment{OrderID: order.ID, TrackingNumber: update.TrackingNumber, Carrier: update.Carrier}
+	for _, item := range order.OrderItems {
+		if remaining := item.Quantity - item.ShippedQuantity; remaining > 0 {
+			shipment.Items = append(shipment.Items, models.ShipmentItem{OrderItemID: item.ID, Quantity: remaining})
+		}
+	}
+	return s.ship(ctx, tx, order, &shipment, schemas.StatusShipped, changedBy, update.Reason)
+}
+
+// deliverAll delivers every shipment of the order, and every unit shipped before shipments were recorded.
+func (s *orderService) deliverAll(ctx context.Context, tx repository.OrderRepository, order *models.Order, update schemas.OrderUpdateSchema, changedBy string) error {
+	for i := range order.OrderItems {
+		order.OrderItems[i].DeliveredQuantity = order.OrderItems[i].ShippedQuantity
+	}
+	next, err := nextStatus(order, schemas.StatusDelivered)
+	if err != nil {
+		return err
+	}
+	shipments, err := tx.Shipments(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	deliveredAt := time.Now()
+	for i := range shipments {
+		if shipments[i].Status == string(schemas.ShipmentDelivered) {
+			continue
+		}
+		markDelivered(&shipments[i], deliveredAt)
+		if err := tx.SaveShipment(ctx, &shipments[i]); err != nil {
+			return err
+		}
+	}
+	return s.saveFulfillment(ctx, tx, order, next, changedBy, update.Reason)
+}
+
+// ship adds the quantities of shipment to the order lines, saves it and moves the order to the status derived
+// from its lines, or to fallback when none has shipped. Every line is checked before failing.
+func (s *orderService) ship(ctx context.Context, tx repository.OrderRepository, order *models.Order, shipment *models.Shipment,
+	fallback schemas.OrderStatus, changedBy, reason string) error {
+	items := orderItemsByID(order)
+	shipmentErr := &ShipmentError{}
+	for i, line := range shipment.Items {
+		item, ok := items[line.OrderItemID]
+		if !ok {
+			shipmentErr.Lines = append(shipmentErr.Lines, fmt.Sprintf("items[%d]: order item %d is not part of order %d", i, line.OrderItemID, order.ID))
+			continue
+		}
+		if remaining := item.Quantity - item.ShippedQuantity; line.Quantity > remaining {
+			shipmentErr.Lines = append(shipmentErr.Lines, fmt.Sprintf("items[%d]: only %d of order item %d left to ship", i, remaining, item.ID))
+			continue
+		}
+		item.ShippedQuantity += line.Quantity
+	}
+	if len(shipmentErr.Lines) > 0 {
+		return shipmentErr
+	}
+	next, err := nextStatus(order, fallback)
+	if err != nil {
+		return err
+	}
+
+	if len(shipment.Items) > 0 {
+		shipment.Status = string(schemas.ShipmentShipped)
+		shipment.ShippedAt = time.Now()
+		if err := tx.CreateShipment(ctx, shipment); err != nil {
+			return err
+		}
+		if reason == "" {
+			reason = fmt.Sprintf("Shipment %d shipped", shipment.ID)
+		}
+	}
+	order.TrackingNumber, order.Carrier = shipment.TrackingNumber, shipment.Carrier
+	return s.saveFulfillment(ctx, tx, order, next, changedBy, reason)
+}
+
+// saveFulfillment saves the order lines with their fulfillment status and the order, moving it to next.
+// ShippedAt is set once every line has shipped.
+func (s *orderService) saveFulfillment(ctx context.Context, tx repository.OrderRepository, order *models.Order,
+	next schemas.OrderStatus, changedBy, reason string) error {
+	for i := range order.OrderItems {
+		item := &order.OrderItems[i]
+		item.FulfillmentStatus = string(fulfillmentStatus(*item))
+		if err := tx.SaveItem(ctx, item); err != nil {
+			return err
+		}
+	}
+	if order.ShippedAt == nil && (next == schemas.StatusShipped || next == schemas.StatusPartiallyDelivered || next == schemas.StatusDelivered) {
+		shippedAt := time.Now()
+		order.ShippedAt = &shippedAt
+	}
+	if next == schemas.OrderStatus(order.Status) {
+		return tx.Save(ctx, order)
+	}
+	return s.transitionOrder(ctx, tx, order, next, changedBy, reason)
+}
+
+// nextStatus returns the status derived from the order lines, or fallback while none has shipped, and
+// rejects it when the order lifecycle does not allow moving to it.
+func nextStatus(order *models.Order, fallback schemas.OrderStatus) (schemas.OrderStatus, error) {
+	next, ok := derivedStatus(order.OrderItems)
+	if !ok {
+		next = fallback
+	}
+	current := schemas.OrderStatus(order.Status)
+	if next != current && !current.CanTransitionTo(next) {
+		return "", &TransitionError{From: current, To: next}
+	}
+	return next, nil
+}
+
+// derivedStatus derives the status of an order from the fulfillment of its lines. It reports false while
+// no line has shipped.
+func derivedStatus(items []models.OrderItem) (schemas.OrderStatus, bool) {
+	shipped, delivered := false, false
+	allShipped, allDelivered := true, true
+	for _, item := range items {
+		shipped = shipped || item.ShippedQuantity > 0
+		delivered = delivered || item.DeliveredQuantity > 0
+		allShipped = allShipped && item.ShippedQuantity >= item.Quantity
+		allDelivered = allDelivered && item.DeliveredQuantity >= item.Quantity
+	}
+	switch {
+	case !shipped:
+		return "", false
+	case allDelivered:
+		return schemas.StatusDelivered, true
+	case allShipped && delivered:
+		return schemas.StatusPartiallyDelivered, true
+	case allShipped:
+		return schemas.StatusShipped, true
+	default:
+		return schemas.StatusPartiallyShipped, true
+	}
+}
+
+// fulfillmentStatus derives the fulfillment status of an order line from its shipped and delivered quantities.
+func fulfillmentStatus(item models.OrderItem) schemas.FulfillmentStatus {
+	switch {
+	case item.DeliveredQuantity >= item.Quantity:
+		return schemas.FulfillmentDelivered
+	case item.DeliveredQuantity > 0:
+		return schemas.FulfillmentPartiallyDelivered
+	case item.ShippedQuantity >= item.Quantity:
+		return schemas.FulfillmentShipped
+	case item.ShippedQuantity > 0:
+		return schemas.FulfillmentPartiallyShipped
+	default:
+		return schemas.FulfillmentUnfulfilled
+	}
+}
+
+func orderItemsByID(order *models.Order) map[uint]*models.OrderItem {
+	items := make(map[uint]*models.OrderItem, len(order.OrderItems))
+	for i := range order.OrderItems {
+		items[order.OrderItems[i].ID] = &order.OrderItems[i]
+	}
+	return items
+}
+
+func markDelivered(shipment *models.Shipment, deliveredAt time.Time) {
+	shipment.Status = string(schemas.ShipmentDelivered)
+	shipment.DeliveredAt = &deliveredAt
+}
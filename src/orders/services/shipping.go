@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/svadikari/golang_fiber_orders/src/currency"
+	"github.com/svadikari/golang_fiber_orders/src/orders/models"
+	"github.com/svadikari/golang_fiber_orders/src/orders/schemas"
+	promotionModels "github.com/svadikari/golang_fiber_orders/src/promotions/models"
+	"github.com/svadikari/golang_fiber_orders/src/shipping"
+)
+
+// ShippingError rejects an order whose shipping method is unknown or cannot be priced in the order currency.
+type ShippingError struct {
+	Method string
+	Reason string
+}
+
+func (e *ShippingError) Error() string {
+	return fmt.Sprintf("shipping method %s %s", e.Method, e.Reason)
+}
+
+// applyShipping records the destination of the order and adds the cost of its shipping method, converted
+// into the order currency, to the total. Free shipping promotions waive the cost.
+func (s *orderService) applyShipping(ctx context.Context, order *models.Order, payload schemas.ShippingSchema,
+	promotion *promotionModels.Promotion) error {
+	if order.ShippingMethod == "" {
+		return nil
+	}
+	order.ShippingAddress = toAddress(payload.ShippingAddress)
+	order.BillingAddress = order.ShippingAddress
+	if payload.BillingAddress != nil {
+		order.BillingAddress = toAddress(payload.BillingAddress)
+	}
+
+	method, err := s.shipping.Find(ctx, order.ShippingMethod)
+	if errors.Is(err, shipping.ErrMethodNotFound) || err == nil && !method.Active {
+		return &ShippingError{Method: order.ShippingMethod, Reason: "is not available"}
+	}
+	if err != nil {
+		return err
+	}
+	cost := method.Cost
+	if method.Currency != order.Currency {
+		cost, err = s.rates.Convert(ctx, method.Cost, method.Currency, order.Currency)
+		if errors.Is(err, currency.ErrNoRate) {
+			return &ShippingError{Method: order.ShippingMethod, Reason: "has no cost in " + order.Currency}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if promotion != nil && promotion.Type == promotionModels.TypeFreeShipping {
+		cost = 0
+	}
+	order.ShippingCost = cost
+	order.TotalAmount += cost
+	return nil
+}
+
+func toAddress(address *schemas.AddressSchema) models.Address {
+	return models.Address{
+		Name:       address.Name,
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		Region:     address.Region,
+		PostalCode: strings.ToUpper(address.PostalCode),
+		Country:    address.Country,
+	}
+}
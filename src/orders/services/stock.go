@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/svadikari/golang_fiber_orders/src/orders/models"
+	"github.com/svadikari/golang_fiber_orders/src/orders/repository"
+	"github.com/svadikari/golang_fiber_orders/src/orders/schemas"
+	productModels "github.com/svadikari/golang_fiber_orders/src/products/models"
+)
+
+// reserveStock locks every product referenced by the order lines and decrements its stock.
+// All lines are checked before failing so the caller receives every problem at once.
+// It must run inside the transaction that saves the order and returns the locked products
+// so the caller can price the lines from the same snapshot.
+func reserveStock(ctx context.Context, tx repository.OrderRepository, items []models.OrderItem) (map[uint]productModels.Product, error) {
+	quantities := quantitiesByProduct(items)
+	products, err := tx.LockProducts(ctx, sortedProductIds(quantities))
+	if err != nil {
+		return nil, err
+	}
+
+	stockErr := &StockError{}
+	for i, item := range items {
+		product, ok := products[item.ProductID]
+		if !ok {
+			stockErr.ProductNotFound = true
+			stockErr.Lines = append(stockErr.Lines, fmt.Sprintf("order_items[%d]: product %d not found", i, item.ProductID))
+			continue
+		}
+		if product.Stock < quantities[item.ProductID] {
+			stockErr.Lines = append(stockErr.Lines, fmt.Sprintf("order_items[%d]: insufficient stock for product %d (requested %d available %d)",
+				i, item.ProductID, quantities[item.ProductID], product.Stock))
+		}
+	}
+	if len(stockErr.Lines) > 0 {
+		return nil, stockErr
+	}
+
+	for _, productId := range sortedProductIds(quantities) {
+		if err := tx.AdjustStock(ctx, productId, -quantities[productId]); err != nil {
+			return nil, err
+		}
+	}
+	return products, nil
+}
+
+// releaseOrderStock returns the quantities of the order lines that have not shipped to the product
+// catalog. The order is flagged so it cannot be restocked twice.
+func releaseOrderStock(ctx context.Context, tx repository.OrderRepository, order *models.Order) error {
+	if order.StockReleased {
+		return nil
+	}
+	quantities := make(map[uint]int, len(order.OrderItems))
+	for _, item := range order.OrderItems {
+		if unshipped := item.Quantity - item.ShippedQuantity; unshipped > 0 {
+			quantities[item.ProductID] += unshipped
+		}
+	}
+	for _, productId := range sortedProductIds(quantities) {
+		if err := tx.AdjustStock(ctx, productId, quantities[productId]); err != nil {
+			return err
+		}
+	}
+	return tx.MarkStockReleased(ctx, order)
+}
+
+// holdsStock reports whether an order in the given status still has stock reserved.
+// Once every line ships the goods have left the warehouse, and a cancelled order
+// has already given its stock back.
+func holdsStock(status schemas.OrderStatus) bool {
+	return status == schemas.StatusNew || status == schemas.StatusConfirmed || status == schemas.StatusPartiallyShipped
+}
+
+func quantitiesByProduct(items []models.OrderItem) map[uint]int {
+	quantities := make(map[uint]int, len(items))
+	for _, item := range items {
+		quantities[item.ProductID] += item.Quantity
+	}
+	return quantities
+}
+
+// sortedProductIds gives a stable order to stock updates so concurrent orders cannot deadlock.
+func sortedProductIds(quantities map[uint]int) []uint {
+	ids := make([]uint, 0, len(quantities))
+	for id := range quantities {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
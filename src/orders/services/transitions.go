@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+
+	"github.com/svadikari/golang_fiber_orders/src/orders/models"
+	"github.com/svadikari/golang_fiber_orders/src/orders/repository"
+	"github.com/svadikari/golang_fiber_orders/src/orders/schemas"
+)
+
+// transitionOrder moves order to status to, recording the history entry and the outbox
+// event in tx. Cancelled orders give their stock back in the same transaction. Callers must
+// have checked that the transition is allowed.
+func (s *orderService) transitionOrder(ctx context.Context, tx repository.OrderRepository, order *models.Order, to schemas.OrderStatus, changedBy, reason string) error {
+	from := order.Status
+	if to == schemas.StatusCancelled {
+		if err := releaseOrderStock(ctx, tx, order); err != nil {
+			return err
+		}
+	}
+	order.Status = string(to)
+	if err := tx.Save(ctx, order); err != nil {
+		return err
+	}
+	err := tx.AddHistory(ctx, &models.OrderStatusHistory{
+		OrderID:    order.ID,
+		FromStatus: from,
+		ToStatus:   order.Status,
+		ChangedBy:  changedBy,
+		Reason:     reason,
+	})
+	if err != nil {
+		return err
+	}
+	return s.enqueueOrderEvent(ctx, tx, schemas.EventOrderStatusChanged, order, from)
+}
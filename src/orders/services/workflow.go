@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"github.com/svadikari/golang_fiber_orders/src/orders/repository"
+	"github.com/svadikari/golang_fiber_orders/src/orders/schemas"
+)
+
+// consumerActor is recorded in the status history for changes made by event handlers.
+const consumerActor = "order-consumer"
+
+// confirmPaymentConsumer is the name under which the payment step records the events it processed.
+const confirmPaymentConsumer = "orders.confirm-payment"
+
+// OrderWorkflow holds the steps run in reaction to order events: those with effects outside the order
+// database. msg is the consumed event; each step applies its effects at most once per event, however often
+// it is redelivered.
+type OrderWorkflow interface {
+	// ConfirmPayment charges a NEW order and confirms it, or cancels it when the payment is declined.
+	ConfirmPayment(ctx context.Context, msg events.Message, orderId uint) error
+}
+
+// ConfirmPayment calls the payment service outside any transaction, so a slow payment does not hold the
+// order lock, and then checks under the lock that the order is still NEW before moving it on. The charge is
+// keyed by order, so a redelivery after a failed transaction does not charge twice, and it is refunded when
+// the order was cancelled or deleted in the meantime.
+func (s *orderService) ConfirmPayment(ctx context.Context, msg events.Message, orderId uint) error {
+	order, err := s.orderRepository.FindByID(ctx, orderId)
+	if errors.Is(err, ErrOrderNotFound) {
+		// An earlier delivery may have charged the order before it was deleted and failed to refund it.
+		s.Logger.Warn("Order no longer exists, skipping payment", "orderId", orderId)
+		return s.payments.RefundPayment(orderId)
+	}
+	if err != nil {
+		return err
+	}
+	if schemas.OrderStatus(order.Status) == schemas.StatusCancelled {
+		s.Logger.Info("Order cancelled, skipping payment", "orderId", orderId)
+		return s.payments.RefundPayment(orderId)
+	}
+	if schemas.OrderStatus(order.Status) != schemas.StatusNew {
+		s.Logger.Info("Order already processed, skipping payment", "orderId", orderId, "status", order.Status)
+		return nil
+	}
+
+	approved, err := s.payments.ConfirmPayment(order.ID, order.UserId, order.TotalAmount)
+	if err != nil {
+		return err
+	}
+
+	refund := false
+	err = s.orderRepository.Transaction(ctx, func(tx repository.OrderRepository) error {
+		if first, err := tx.MarkProcessed(ctx, confirmPaymentConsumer, msg); err != nil || !first {
+			return err
+		}
+		order, err := tx.LockByID(ctx, orderId, false)
+		if errors.Is(err, ErrOrderNotFound) {
+			s.Logger.Warn("Order deleted during payment", "orderId", orderId)
+			refund = approved
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if schemas.OrderStatus(order.Status) != schemas.StatusNew {
+			s.Logger.Info("Order changed during payment", "orderId", orderId, "status", order.Status)
+			refund = approved && schemas.OrderStatus(order.Status) == schemas.StatusCancelled
+			return nil
+		}
+		if !approved {
+			s.Logger.Warn("Payment declined, cancelling order", "orderId", orderId)
+			return s.transitionOrder(ctx, tx, &order, schemas.StatusCancelled, consumerActor, "Payment declined")
+		}
+		s.Logger.Info("Payment confirmed", "orderId", orderId)
+		return s.transitionOrder(ctx, tx, &order, schemas.StatusConfirmed, consumerActor, "Payment confirmed")
+	})
+	if err != nil || !refund {
+		return err
+	}
+	// A failed refund is returned so the event is redelivered, and the redelivery refunds the order again.
+	s.Logger.Warn("Refunding the payment of an order that will not be fulfilled", "orderId", orderId)
+	return s.payments.RefundPayment(orderId)
+}
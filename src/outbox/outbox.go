@@ -0,0 +1,70 @@
+package outbox
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"gorm.io/gorm"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "PENDING"
+	StatusSent    Status = "SENT"
+)
+
+// Message is an event waiting to be relayed to the message broker. It is written in
+// the same transaction as the state change it describes, so an event exists if and
+// only if that change was committed.
+type Message struct {
+	ID            uint       `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	EventID       string     `json:"event_id" gorm:"column:event_id;not null;size:36;uniqueIndex:idx_outbox_event_id"`
+	AggregateType string     `json:"aggregate_type" gorm:"column:aggregate_type;not null;size:100"`
+	AggregateID   string     `json:"aggregate_id" gorm:"column:aggregate_id;not null;size:100"`
+	EventType     string     `json:"event_type" gorm:"column:event_type;not null;size:100"`
+	Topic         string     `json:"topic" gorm:"column:topic;not null;size:255"`
+	Key           string     `json:"key" gorm:"column:message_key;size:255"`
+	Headers       []byte     `json:"-" gorm:"column:headers;type:jsonb"`
+	Payload       []byte     `json:"-" gorm:"column:payload;type:bytea;not null"`
+	Status        Status     `json:"status" gorm:"column:status;not null;size:20;default:'PENDING';index:idx_outbox_pending,priority:1"`
+	Attempts      int        `json:"attempts" gorm:"column:attempts;not null;default:0"`
+	LastError     string     `json:"last_error" gorm:"column:last_error;size:1000"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"column:next_attempt_at;not null;index:idx_outbox_pending,priority:2"`
+	CreatedAt     time.Time  `json:"created_at" gorm:"column:created_at;not null"`
+	SentAt        *time.Time `json:"sent_at" gorm:"column:sent_at"`
+}
+
+func (Message) TableName() string {
+	return "outbox"
+}
+
+// Enqueue stores msg as pending. tx must be the transaction that persists the
+// change the message describes.
+func Enqueue(tx *gorm.DB, msg *Message) error {
+	msg.Status = StatusPending
+	if msg.NextAttemptAt.IsZero() {
+		msg.NextAttemptAt = time.Now()
+	}
+	return tx.Create(msg).Error
+}
+
+// EnqueueEvent stores a serialized event as a pending message for topic, keeping the headers produced
+// by its serializer so the relay publishes the message exactly as it was encoded.
+func EnqueueEvent(tx *gorm.DB, topic, aggregateType, aggregateId string, event events.CloudEvent, value []byte, headers map[string]string) error {
+	encodedHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+	return Enqueue(tx, &Message{
+		EventID:       event.ID,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateId,
+		EventType:     event.Type,
+		Topic:         topic,
+		Key:           aggregateId,
+		Headers:       encodedHeaders,
+		Payload:       value,
+	})
+}
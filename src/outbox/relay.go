@@ -0,0 +1,193 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"gorm.io/gorm"
+)
+
+// Relay polls the outbox table and publishes pending messages, giving at-least-once delivery.
+type Relay struct {
+	store        Store
+	publisher    events.EventPublisher
+	logger       *slog.Logger
+	pollInterval time.Duration
+	batchSize    int
+	lease        time.Duration
+	alertAfter   int
+	baseBackoff  time.Duration
+	maxBackoff   time.Duration
+}
+
+func NewRelay(db *gorm.DB, publisher events.EventPublisher, logger *slog.Logger) *Relay {
+	return &Relay{
+		store:        NewStore(db),
+		publisher:    publisher,
+		logger:       logger.With("component", "OutboxRelay"),
+		pollInterval: envDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		batchSize:    envInt("OUTBOX_BATCH_SIZE", 100),
+		lease:        envDuration("OUTBOX_LEASE", time.Minute),
+		alertAfter:   envInt("OUTBOX_ALERT_ATTEMPTS", 10),
+		baseBackoff:  envDuration("OUTBOX_RETRY_BACKOFF", 2*time.Second),
+		maxBackoff:   5 * time.Minute,
+	}
+}
+
+// Start relays pending messages until ctx is cancelled.
+func (r *Relay) Start(ctx context.Context) {
+	r.logger.Info("Outbox relay started", "pollInterval", r.pollInterval, "batchSize", r.batchSize)
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Outbox relay stopped")
+			return
+		case <-ticker.C:
+			// Drain the backlog before waiting for the next tick.
+			for {
+				sent, err := r.relayBatch(ctx)
+				if err != nil {
+					r.logger.Error("Failed to relay outbox messages", "error", err)
+					break
+				}
+				// Batches hold one message per key, so keep going until nothing is due rather than
+				// until a batch comes back short.
+				if sent == 0 || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// relayBatch publishes one batch of due messages and returns how many were picked up.
+// Rows are claimed with SKIP LOCKED so several instances can relay concurrently, and no transaction is open
+// while the broker is called: a slow or unavailable broker must not hold row locks.
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	messages, err := r.claim(ctx)
+	if err != nil || len(messages) == 0 {
+		return 0, err
+	}
+	// Due holds back every message behind an unsent one with the same key, so a batch has at most one
+	// message per key and a failing message blocks its key until it is sent.
+	for i := range messages {
+		msg := &messages[i]
+		if err := r.publishSafely(ctx, msg); err != nil {
+			r.markFailedAttempt(msg, err)
+		} else {
+			now := time.Now()
+			msg.Status = StatusSent
+			msg.SentAt = &now
+			msg.LastError = ""
+		}
+	}
+	err = r.store.Transaction(ctx, func(tx Store) error {
+		for i := range messages {
+			if err := tx.Save(ctx, &messages[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return len(messages), err
+}
+
+// claim leases a batch of due messages by moving their next attempt past the lease, so other relays skip
+// them while they are published. A relay that dies before recording the outcome leaves its messages to be
+// picked up again once the lease expires.
+func (r *Relay) claim(ctx context.Context) ([]Message, error) {
+	var messages []Message
+	err := r.store.Transaction(ctx, func(tx Store) error {
+		now := time.Now()
+		var err error
+		messages, err = tx.Due(ctx, now, r.batchSize)
+		if err != nil {
+			return err
+		}
+		for i := range messages {
+			messages[i].NextAttemptAt = now.Add(r.lease)
+			if err := tx.Save(ctx, &messages[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return messages, err
+}
+
+// publishSafely turns a panicking publisher into an error so one bad message cannot crash the process.
+func (r *Relay) publishSafely(ctx context.Context, msg *Message) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("publish panicked: %v", rec)
+		}
+	}()
+	headers := map[string]string{}
+	if len(msg.Headers) > 0 {
+		if err := json.Unmarshal(msg.Headers, &headers); err != nil {
+			return fmt.Errorf("invalid outbox headers: %w", err)
+		}
+	}
+	headers[events.EventTypeHeader] = msg.EventType
+	headers[events.HeaderEventID] = msg.EventID
+	headers["aggregate_type"] = msg.AggregateType
+	headers["aggregate_id"] = msg.AggregateID
+	return r.publisher.Publish(ctx, events.Message{
+		Topic:   msg.Topic,
+		Key:     []byte(msg.Key),
+		Value:   msg.Payload,
+		Headers: headers,
+	})
+}
+
+// markFailedAttempt schedules the next attempt of msg. A message is never given up: the later messages of
+// its key wait behind it, so it is retried with the capped backoff and reported as an error once it failed
+// alertAfter times.
+func (r *Relay) markFailedAttempt(msg *Message, err error) {
+	msg.Attempts++
+	msg.LastError = truncate(err.Error(), 1000)
+	msg.NextAttemptAt = time.Now().Add(r.backoff(msg.Attempts))
+	if msg.Attempts >= r.alertAfter {
+		r.logger.Error("Outbox message keeps failing, later messages of its key are held back", "id", msg.ID, "eventType", msg.EventType, "key", msg.Key, "attempts", msg.Attempts, "nextAttemptAt", msg.NextAttemptAt, "error", err)
+		return
+	}
+	r.logger.Warn("Failed to publish outbox message, will retry", "id", msg.ID, "eventType", msg.EventType, "attempts", msg.Attempts, "nextAttemptAt", msg.NextAttemptAt, "error", err)
+}
+
+// backoff doubles the wait after every failed attempt, capped at maxBackoff.
+func (r *Relay) backoff(attempts int) time.Duration {
+	wait := r.baseBackoff
+	for i := 1; i < attempts && wait < r.maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, r.maxBackoff)
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
+
+func envInt(name string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,245 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/svadikari/golang_fiber_orders/src/events"
+)
+
+// memoryStore keeps the outbox in memory, ordered by ID, and selects due messages like the SQL store.
+type memoryStore struct {
+	messages      []Message
+	inTransaction bool
+}
+
+func (s *memoryStore) Transaction(ctx context.Context, fn func(tx Store) error) error {
+	s.inTransaction = true
+	defer func() { s.inTransaction = false }()
+	return fn(s)
+}
+
+func (s *memoryStore) Due(ctx context.Context, now time.Time, limit int) ([]Message, error) {
+	unsent := map[string]bool{}
+	var due []Message
+	for _, msg := range s.messages {
+		if msg.Status == StatusPending && !msg.NextAttemptAt.After(now) && !unsent[msg.Key] && len(due) < limit {
+			due = append(due, msg)
+		}
+		if msg.Status != StatusSent {
+			unsent[msg.Key] = true
+		}
+	}
+	return due, nil
+}
+
+func (s *memoryStore) Save(ctx context.Context, msg *Message) error {
+	for i := range s.messages {
+		if s.messages[i].ID == msg.ID {
+			s.messages[i] = *msg
+		}
+	}
+	return nil
+}
+
+// makeDue moves the next attempt of every pending message into the past.
+func (s *memoryStore) makeDue() {
+	for i := range s.messages {
+		s.messages[i].NextAttemptAt = time.Now().Add(-time.Millisecond)
+	}
+}
+
+// flakyPublisher fails to publish the events listed in failing and records the others on its bus.
+type flakyPublisher struct {
+	*events.MemoryBus
+	failing map[string]bool
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, msg events.Message) error {
+	if p.failing[msg.Headers[events.HeaderEventID]] {
+		return errors.New("broker unavailable")
+	}
+	return p.MemoryBus.Publish(ctx, msg)
+}
+
+// publisherFunc publishes with a function, letting tests observe the store while a message is published.
+type publisherFunc func(ctx context.Context, msg events.Message) error
+
+func (f publisherFunc) Publish(ctx context.Context, msg events.Message) error {
+	return f(ctx, msg)
+}
+
+func (f publisherFunc) Stats() events.PublisherStats {
+	return events.PublisherStats{}
+}
+
+func (f publisherFunc) Close() {}
+
+func newTestRelay(store Store, publisher events.EventPublisher) *Relay {
+	return &Relay{
+		store:       store,
+		publisher:   publisher,
+		logger:      slog.Default(),
+		batchSize:   10,
+		lease:       time.Minute,
+		alertAfter:  3,
+		baseBackoff: time.Second,
+		maxBackoff:  3 * time.Second,
+	}
+}
+
+func pending(id uint, key string) Message {
+	return Message{
+		ID:            id,
+		EventID:       fmt.Sprintf("event-%d", id),
+		AggregateType: "Order",
+		AggregateID:   key,
+		EventType:     "order.status_changed",
+		Topic:         "orders",
+		Key:           key,
+		Payload:       []byte("{}"),
+		Status:        StatusPending,
+		NextAttemptAt: time.Now().Add(-time.Second),
+	}
+}
+
+func publishedEventIDs(bus *events.MemoryBus) []string {
+	var ids []string
+	for _, msg := range bus.Published("orders") {
+		ids = append(ids, msg.Headers[events.HeaderEventID])
+	}
+	return ids
+}
+
+func TestRelayBatch(t *testing.T) {
+
+	t.Run("Published messages are marked sent", func(t *testing.T) {
+		msg := pending(1, "7")
+		msg.Headers = []byte(`{"content-type":"application/cloudevents+json"}`)
+		msg.LastError = "broker unavailable"
+		store := &memoryStore{messages: []Message{msg}}
+		publisher := &flakyPublisher{MemoryBus: events.NewMemoryBus()}
+
+		picked, err := newTestRelay(store, publisher).relayBatch(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, picked)
+
+		published := publisher.Published("orders")
+		require.Len(t, published, 1)
+		assert.Equal(t, "7", string(published[0].Key))
+		assert.Equal(t, "application/cloudevents+json", published[0].Headers["content-type"])
+		assert.Equal(t, "order.status_changed", published[0].Headers[events.EventTypeHeader])
+		assert.Equal(t, "event-1", published[0].Headers[events.HeaderEventID])
+		assert.Equal(t, StatusSent, store.messages[0].Status)
+		assert.NotNil(t, store.messages[0].SentAt)
+		assert.Empty(t, store.messages[0].LastError)
+	})
+
+	t.Run("A failed message holds back the later messages of its key across batches", func(t *testing.T) {
+		store := &memoryStore{messages: []Message{pending(1, "7"), pending(2, "7"), pending(3, "8")}}
+		publisher := &flakyPublisher{MemoryBus: events.NewMemoryBus(), failing: map[string]bool{"event-1": true}}
+		relay := newTestRelay(store, publisher)
+
+		picked, err := relay.relayBatch(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, picked, "the second message of key 7 waits behind the first")
+		assert.Equal(t, []string{"event-3"}, publishedEventIDs(publisher.MemoryBus))
+		assert.Equal(t, StatusPending, store.messages[0].Status)
+		assert.True(t, store.messages[0].NextAttemptAt.After(time.Now()))
+
+		picked, err = relay.relayBatch(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, picked, "nothing of key 7 is due while its first message backs off")
+
+		delete(publisher.failing, "event-1")
+		store.makeDue()
+		for _, want := range []int{1, 1, 0} {
+			picked, err = relay.relayBatch(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, want, picked)
+		}
+		assert.Equal(t, []string{"event-3", "event-1", "event-2"}, publishedEventIDs(publisher.MemoryBus))
+	})
+
+	t.Run("Failed attempts back off exponentially up to the cap", func(t *testing.T) {
+		store := &memoryStore{messages: []Message{pending(1, "7")}}
+		publisher := &flakyPublisher{MemoryBus: events.NewMemoryBus(), failing: map[string]bool{"event-1": true}}
+		relay := newTestRelay(store, publisher)
+
+		for attempt, backoff := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second} {
+			before := time.Now()
+			_, err := relay.relayBatch(context.Background())
+			require.NoError(t, err)
+			failed := store.messages[0]
+			assert.Equal(t, attempt+1, failed.Attempts)
+			assert.Equal(t, StatusPending, failed.Status)
+			assert.Equal(t, "broker unavailable", failed.LastError)
+			assert.WithinDuration(t, before.Add(backoff), failed.NextAttemptAt, 100*time.Millisecond)
+			store.makeDue()
+		}
+	})
+
+	t.Run("A key stuck behind a repeatedly failing message is released once it is sent", func(t *testing.T) {
+		store := &memoryStore{messages: []Message{pending(1, "7"), pending(2, "7")}}
+		publisher := &flakyPublisher{MemoryBus: events.NewMemoryBus(), failing: map[string]bool{"event-1": true}}
+		relay := newTestRelay(store, publisher)
+
+		for range 5 {
+			picked, err := relay.relayBatch(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 1, picked, "only the failing message of key 7 is due")
+			store.makeDue()
+		}
+		assert.Equal(t, 5, store.messages[0].Attempts)
+		assert.Empty(t, publisher.Published("orders"))
+
+		delete(publisher.failing, "event-1")
+		for _, want := range []int{1, 1, 0} {
+			picked, err := relay.relayBatch(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, want, picked)
+		}
+		assert.Equal(t, []string{"event-1", "event-2"}, publishedEventIDs(publisher.MemoryBus))
+	})
+	t.Run("Messages are published outside a transaction while they are leased", func(t *testing.T) {
+		store := &memoryStore{messages: []Message{pending(1, "7")}}
+		published := 0
+		relay := newTestRelay(store, publisherFunc(func(ctx context.Context, msg events.Message) error {
+			published++
+			assert.False(t, store.inTransaction, "no row locks are held while the broker is called")
+			due, err := store.Due(ctx, time.Now(), 10)
+			require.NoError(t, err)
+			assert.Empty(t, due, "other relays skip a leased message")
+			return nil
+		}))
+
+		picked, err := relay.relayBatch(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, picked)
+		assert.Equal(t, 1, published)
+		assert.Equal(t, StatusSent, store.messages[0].Status)
+	})
+
+	t.Run("Claimed messages are picked up again once their lease expires", func(t *testing.T) {
+		store := &memoryStore{messages: []Message{pending(1, "7")}}
+		relay := newTestRelay(store, &flakyPublisher{MemoryBus: events.NewMemoryBus()})
+
+		claimed, err := relay.claim(context.Background())
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+
+		due, err := store.Due(context.Background(), time.Now(), 10)
+		require.NoError(t, err)
+		assert.Empty(t, due)
+		due, err = store.Due(context.Background(), time.Now().Add(relay.lease+time.Second), 10)
+		require.NoError(t, err)
+		assert.Len(t, due, 1, "a relay that died after claiming does not lose the message")
+		assert.Equal(t, StatusPending, due[0].Status)
+	})
+}
@@ -0,0 +1,47 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Store is the outbox table as the relay sees it.
+type Store interface {
+	// Transaction runs fn with a store bound to a single database transaction.
+	Transaction(ctx context.Context, fn func(tx Store) error) error
+	// Due locks up to limit pending messages that are due at now, oldest first. A message is left out while
+	// an older message with the same key is unsent, so messages of one key are published in order even
+	// when the older one waits for a retry. Rows locked by another relay are skipped.
+	Due(ctx context.Context, now time.Time, limit int) ([]Message, error)
+	Save(ctx context.Context, msg *Message) error
+}
+
+type gormStore struct {
+	Db *gorm.DB
+}
+
+func NewStore(db *gorm.DB) Store {
+	return &gormStore{Db: db}
+}
+
+func (s *gormStore) Transaction(ctx context.Context, fn func(tx Store) error) error {
+	return s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{Db: tx})
+	})
+}
+
+func (s *gormStore) Due(ctx context.Context, now time.Time, limit int) ([]Message, error) {
+	var messages []Message
+	err := s.Db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND next_attempt_at <= ?", StatusPending, now).
+		Where("NOT EXISTS (SELECT 1 FROM outbox AS older WHERE older.message_key = outbox.message_key AND older.id < outbox.id AND older.status <> ?)", StatusSent).
+		Order("id").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+func (s *gormStore) Save(ctx context.Context, msg *Message) error {
+	return s.Db.WithContext(ctx).Save(msg).Error
+}
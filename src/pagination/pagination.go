@@ -0,0 +1,77 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page is the response envelope of paginated list endpoints. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Cursor is the position after the last item of a page: its sort key and its ID as a tie-breaker.
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+// Encode returns the cursor as an opaque URL-safe token.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(token string) (Cursor, error) {
+	var cursor Cursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 {
+		return cursor, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// Sort is a sort key and direction parsed from a sort parameter such as "-created_at".
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+func ParseSort(value string) Sort {
+	if field, desc := strings.CutPrefix(value, "-"); desc {
+		return Sort{Field: field, Desc: true}
+	}
+	return Sort{Field: value}
+}
+
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// Limit clamps a requested page size to [1, MaxLimit], defaulting to DefaultLimit.
+func Limit(requested int) int {
+	if requested <= 0 {
+		return DefaultLimit
+	}
+	return min(requested, MaxLimit)
+}
@@ -0,0 +1,36 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPagination(t *testing.T) {
+
+	t.Run("Cursors round trip", func(t *testing.T) {
+		cursor := Cursor{Sort: "-created_at", Value: "2024-05-01T10:30:00Z", ID: 42}
+		decoded, err := DecodeCursor(cursor.Encode())
+		assert.NoError(t, err)
+		assert.Equal(t, cursor, decoded)
+	})
+
+	t.Run("Malformed cursors are rejected", func(t *testing.T) {
+		_, err := DecodeCursor("not a cursor")
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+		_, err = DecodeCursor(Cursor{Sort: "id"}.Encode())
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("Sort direction is read from the prefix", func(t *testing.T) {
+		assert.Equal(t, Sort{Field: "total_amount", Desc: true}, ParseSort("-total_amount"))
+		assert.Equal(t, Sort{Field: "id"}, ParseSort("id"))
+		assert.Equal(t, "-total_amount", ParseSort("-total_amount").String())
+	})
+
+	t.Run("Limits are clamped", func(t *testing.T) {
+		assert.Equal(t, DefaultLimit, Limit(0))
+		assert.Equal(t, 5, Limit(5))
+		assert.Equal(t, MaxLimit, Limit(1000))
+	})
+}
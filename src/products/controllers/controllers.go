@@ -1,16 +1,22 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/svadikari/golang_fiber_orders/src/middleware"
+	"github.com/svadikari/golang_fiber_orders/src/pagination"
+	"github.com/svadikari/golang_fiber_orders/src/products/models"
 	"github.com/svadikari/golang_fiber_orders/src/products/schemas"
 	"github.com/svadikari/golang_fiber_orders/src/products/services"
 )
 
 type ProductController interface {
 	GetProducts(c *fiber.Ctx) error
+	SearchProducts(c *fiber.Ctx) error
 	CreateProduct(c *fiber.Ctx) error
 	UpdateProduct(c *fiber.Ctx) error
 	GetProduct(c *fiber.Ctx) error
@@ -25,17 +31,81 @@ func NewProductController(productService services.ProductService) ProductControl
 	return &productController{productService: productService}
 }
 
-// Get all products
+// Get products
 //
-//	@Summary		Get all products
-//	@Description	Retrieve a list of all products
+//	@Summary		Get products
+//	@Description	Retrieve a page of products matching the search and filters
 //	@Tags			Products
 //	@Produce		json
-//	@Success		200	{array}	schemas.Product
+//	@Param			q			query		string	false	"Text searched in name and description"
+//	@Param			min_price	query		number	false	"Minimum price"
+//	@Param			max_price	query		number	false	"Maximum price"
+//	@Param			in_stock	query		bool	false	"Only products in (true) or out of (false) stock"
+//	@Param			category	query		string	false	"Only products in this category"
+//	@Param			sort		query		string	false	"Sort key, prefixed with - for descending order"	Enums(name, -name, price, -price, stock, -stock, created_at, -created_at)	default(name)
+//	@Param			limit		query		int		false	"Page size"											minimum(1)																	maximum(100)	default(20)
+//	@Param			offset		query		int		false	"Number of products to skip"
+//	@Success		200			{object}	pagination.Page[models.Product]
 //	@Router			/products [get]
 func (pc *productController) GetProducts(c *fiber.Ctx) error {
-	products, _ := pc.productService.GetAllProducts()
-	return c.Status(fiber.StatusOK).JSON(products)
+	var query schemas.ProductQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"code":    fiber.StatusBadRequest,
+			"details": fmt.Sprintf("Invalid query parameters: %v", err.Error()),
+		})
+	}
+	if validationErrs := middleware.NewStructValidator().Validate(query); len(validationErrs) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"code":    fiber.StatusBadRequest,
+			"details": strings.Join(validationErrs, ", "),
+		})
+	}
+	var page pagination.Page[models.Product]
+	page, err := pc.productService.GetProducts(query)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"code":    fiber.StatusInternalServerError,
+			"details": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(page)
+}
+
+// Search products
+//
+//	@Summary		Search products
+//	@Description	Full-text search over product names and descriptions, ranked by relevance with the matched words highlighted.
+//	@Description	When nothing matches, products with a similar name or description are returned instead (match=similar)
+//	@Tags			Products
+//	@Produce		json
+//	@Param			q		query		string	true	"Search text; supports quoted phrases, OR and -excluded words"
+//	@Param			limit	query		int		false	"Page size"	minimum(1)	maximum(100)	default(20)
+//	@Param			offset	query		int		false	"Number of results to skip"
+//	@Success		200		{object}	pagination.Page[schemas.ProductSearchResult]
+//	@Router			/products/search [get]
+func (pc *productController) SearchProducts(c *fiber.Ctx) error {
+	var query schemas.ProductSearchQuery
+	if err := c.QueryParser(&query); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"code":    fiber.StatusBadRequest,
+			"details": fmt.Sprintf("Invalid query parameters: %v", err.Error()),
+		})
+	}
+	if validationErrs := middleware.NewStructValidator().Validate(query); len(validationErrs) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"code":    fiber.StatusBadRequest,
+			"details": strings.Join(validationErrs, ", "),
+		})
+	}
+	page, err := pc.productService.SearchProducts(query)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"code":    fiber.StatusInternalServerError,
+			"details": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusOK).JSON(page)
 }
 
 // Create product
@@ -62,7 +132,19 @@ func (pc *productController) CreateProduct(c *fiber.Ctx) error {
 			"details": err.Error(),
 		})
 	}
+	if validationErrs := middleware.NewStructValidator().Validate(productPayload.ProductPrices); len(validationErrs) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"code":    fiber.StatusBadRequest,
+			"details": strings.Join(validationErrs, ", "),
+		})
+	}
 	product, err := pc.productService.CreateProduct(productPayload)
+	if errors.Is(err, services.ErrDuplicateCurrency) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"code":    fiber.StatusBadRequest,
+			"details": err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"code":    fiber.StatusInternalServerError,
@@ -141,8 +223,20 @@ func (pc *productController) UpdateProduct(c *fiber.Ctx) error {
 			"details": err.Error(),
 		})
 	}
+	if validationErrs := middleware.NewStructValidator().Validate(productPayload.ProductPrices); len(validationErrs) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"code":    fiber.StatusBadRequest,
+			"details": strings.Join(validationErrs, ", "),
+		})
+	}
 	product, err := pc.productService.UpdateProduct(uint(id), productPayload)
 
+	if errors.Is(err, services.ErrDuplicateCurrency) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"code":    fiber.StatusBadRequest,
+			"details": err.Error(),
+		})
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"code":    fiber.StatusInternalServerError,
@@ -1,12 +1,44 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"github.com/svadikari/golang_fiber_orders/src/money"
+	"gorm.io/gorm"
+)
 
 type Product struct {
 	gorm.Model
-	Name        string  `json:"name" gorm:"not null;column:name;index:idx_name;size:200"`
-	Description string  `json:"description" gorm:"column:description;not null;size:1000"`
-	Price       float64 `json:"price" gorm:"column:price;not null;check:price >= 0.1"`
-	Stock       int     `json:"stock" gorm:"column:stock"`
-	ImageURL    string  `json:"image_url" gorm:"column:image_url"`
+	Name        string      `json:"name" gorm:"not null;column:name;index:idx_name;size:200"`
+	Description string      `json:"description" gorm:"column:description;not null;size:1000"`
+	Price       money.Money `json:"price" gorm:"column:price;type:numeric(12,2);not null;check:price >= 0.1"`
+	// Currency is the ISO 4217 currency of Price.
+	Currency string `json:"currency" gorm:"column:currency;type:char(3);not null;default:'USD'"`
+	// Prices holds the prices in currencies other than Currency.
+	Prices []ProductPrice `json:"prices" gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	// Category groups products for promotions and listing, such as "books".
+	Category string `json:"category" gorm:"column:category;size:100;not null;default:'';index:idx_products_category"`
+	// TaxCategory picks the tax rates of the product, such as "standard", "food" or "books".
+	TaxCategory string `json:"tax_category" gorm:"column:tax_category;size:50;not null;default:'standard'"`
+	Stock       int    `json:"stock" gorm:"column:stock"`
+	ImageURL    string `json:"image_url" gorm:"column:image_url"`
+}
+
+// ProductPrice is the price of a product in one currency.
+type ProductPrice struct {
+	ID        uint        `json:"-" gorm:"primaryKey;autoIncrement;column:id"`
+	ProductID uint        `json:"-" gorm:"not null;column:product_id;uniqueIndex:uq_product_prices_currency,priority:1"`
+	Currency  string      `json:"currency" gorm:"column:currency;type:char(3);not null;uniqueIndex:uq_product_prices_currency,priority:2"`
+	Amount    money.Money `json:"amount" gorm:"column:amount;type:numeric(12,2);not null;check:amount > 0"`
+}
+
+// PriceIn returns the price of the product in currency and whether it has one.
+func (p Product) PriceIn(currency string) (money.Money, bool) {
+	if currency == p.Currency {
+		return p.Price, true
+	}
+	for _, price := range p.Prices {
+		if price.Currency == currency {
+			return price.Amount, true
+		}
+	}
+	return 0, false
 }
@@ -2,9 +2,13 @@ package repository
 
 import (
 	"errors"
+	"fmt"
 	"log"
+	"strings"
 
+	"github.com/svadikari/golang_fiber_orders/src/pagination"
 	"github.com/svadikari/golang_fiber_orders/src/products/models"
+	"github.com/svadikari/golang_fiber_orders/src/products/schemas"
 	"gorm.io/gorm"
 )
 
@@ -16,21 +20,63 @@ func NewProductRepository(db *gorm.DB) ProductRepository {
 	return &productRepository{Db: db}
 }
 
-func (r *productRepository) Create(product *models.Product) *models.Product {
-	r.Db.Create(&product)
-	return product
+func (r *productRepository) Create(product *models.Product) error {
+	return r.Db.Create(product).Error
 }
 
-func (r *productRepository) Find() []models.Product {
-	var products []models.Product
+// Find returns the page of products matching query and the number of products matching its filters.
+// query must be normalized by the caller: Sort and Limit are set and valid.
+func (r *productRepository) Find(query schemas.ProductQuery) ([]models.Product, int64, error) {
+	products := []models.Product{}
+	var total int64
+
+	filters := func(tx *gorm.DB) *gorm.DB { return filterProducts(tx, query) }
+	if err := r.Db.Model(&models.Product{}).Scopes(filters).Count(&total).Error; err != nil {
+		return products, 0, err
+	}
+
+	sort := pagination.ParseSort(query.Sort)
+	direction := "ASC"
+	if sort.Desc {
+		direction = "DESC"
+	}
+	err := r.Db.Scopes(filters).Preload("Prices").
+		Order(fmt.Sprintf("%s %s, id %s", sort.Field, direction, direction)).
+		Limit(query.Limit).Offset(query.Offset).
+		Find(&products).Error
+	return products, total, err
+}
 
-	r.Db.Find(&products) // Replace nil with actual model slice
-	return products
+func filterProducts(tx *gorm.DB, query schemas.ProductQuery) *gorm.DB {
+	if search := strings.TrimSpace(query.Search); search != "" {
+		pattern := "%" + likeEscaper.Replace(search) + "%"
+		tx = tx.Where("name ILIKE ? OR description ILIKE ?", pattern, pattern)
+	}
+	if query.MinPrice != nil {
+		tx = tx.Where("price >= ?", *query.MinPrice)
+	}
+	if query.MaxPrice != nil {
+		tx = tx.Where("price <= ?", *query.MaxPrice)
+	}
+	if query.Category != "" {
+		tx = tx.Where("LOWER(category) = LOWER(?)", query.Category)
+	}
+	if query.InStock != nil {
+		if *query.InStock {
+			tx = tx.Where("stock > 0")
+		} else {
+			tx = tx.Where("stock <= 0")
+		}
+	}
+	return tx
 }
 
+// likeEscaper escapes the LIKE wildcards in user input so they match literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 func (r *productRepository) FindByID(id uint) models.Product {
 	var product models.Product
-	result := r.Db.First(&product, id)
+	result := r.Db.Preload("Prices").First(&product, id)
 
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return models.Product{}
@@ -38,9 +84,24 @@ func (r *productRepository) FindByID(id uint) models.Product {
 	return product
 }
 
-func (r *productRepository) Update(product *models.Product) *models.Product {
-	r.Db.Save(&product)
-	return product
+// Update saves the product and replaces its prices with product.Prices.
+func (r *productRepository) Update(product *models.Product) error {
+	return r.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Prices").Save(product).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductPrice{}).Error; err != nil {
+			return err
+		}
+		for i := range product.Prices {
+			product.Prices[i].ID = 0
+			product.Prices[i].ProductID = product.ID
+		}
+		if len(product.Prices) == 0 {
+			return nil
+		}
+		return tx.Create(&product.Prices).Error
+	})
 }
 
 func (r *productRepository) Delete(product *models.Product) {
@@ -48,10 +109,93 @@ func (r *productRepository) Delete(product *models.Product) {
 	log.Println(result.Error.Error())
 }
 
+// searchConfig is the text search configuration of the search_vector column.
+const searchConfig = "english"
+
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>"
+
+// Search ranks the products matching query.Q with full-text search, falling back to trigram similarity
+// on name and description when nothing matches, e.g. because of a typo.
+func (r *productRepository) Search(query schemas.ProductSearchQuery) ([]schemas.ProductSearchResult, int64, error) {
+	results, total, err := r.searchFullText(query)
+	if err == nil && total == 0 {
+		results, total, err = r.searchSimilar(query)
+	}
+	if err != nil || len(results) == 0 {
+		return results, total, err
+	}
+	return results, total, r.attachPrices(results)
+}
+
+// attachPrices loads the prices of search results, which are scanned from a raw select.
+func (r *productRepository) attachPrices(results []schemas.ProductSearchResult) error {
+	ids := make([]uint, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.ID)
+	}
+	var prices []models.ProductPrice
+	if err := r.Db.Where("product_id IN ?", ids).Order("currency").Find(&prices).Error; err != nil {
+		return err
+	}
+	for i := range results {
+		results[i].Prices = []models.ProductPrice{}
+		for _, price := range prices {
+			if price.ProductID == results[i].ID {
+				results[i].Prices = append(results[i].Prices, price)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *productRepository) searchFullText(query schemas.ProductSearchQuery) ([]schemas.ProductSearchResult, int64, error) {
+	results := []schemas.ProductSearchResult{}
+	var total int64
+	tsquery := gorm.Expr("websearch_to_tsquery(?, ?)", searchConfig, query.Q)
+
+	err := r.Db.Model(&models.Product{}).Where("search_vector @@ ?", tsquery).Count(&total).Error
+	if err != nil || total == 0 {
+		return results, total, err
+	}
+	err = r.Db.Model(&models.Product{}).
+		Select("products.*, ts_rank(search_vector, ?) AS rank, ? AS match, "+
+			"ts_headline(?, name, ?, ?) AS name_highlight, ts_headline(?, description, ?, ?) AS snippet",
+			tsquery, schemas.MatchFullText,
+			searchConfig, tsquery, headlineOptions+", HighlightAll=true",
+			searchConfig, tsquery, headlineOptions+", MaxFragments=2, MaxWords=20, MinWords=5").
+		Where("search_vector @@ ?", tsquery).
+		Order("rank DESC, id").
+		Limit(query.Limit).Offset(query.Offset).
+		Scan(&results).Error
+	return results, total, err
+}
+
+// searchSimilar uses the pg_trgm similarity operators, which are served by the trigram indexes.
+func (r *productRepository) searchSimilar(query schemas.ProductSearchQuery) ([]schemas.ProductSearchResult, int64, error) {
+	results := []schemas.ProductSearchResult{}
+	var total int64
+	matches := r.Db.Where("name % ? OR ? <% description", query.Q, query.Q)
+
+	err := r.Db.Model(&models.Product{}).Where(matches).Count(&total).Error
+	if err != nil || total == 0 {
+		return results, total, err
+	}
+	err = r.Db.Model(&models.Product{}).
+		Select("products.*, greatest(similarity(name, ?), word_similarity(?, description)) AS rank, ? AS match, "+
+			"name AS name_highlight, left(description, 200) AS snippet",
+			query.Q, query.Q, schemas.MatchSimilar).
+		Where(matches).
+		Order("rank DESC, id").
+		Limit(query.Limit).Offset(query.Offset).
+		Scan(&results).Error
+	return results, total, err
+}
+
 type ProductRepository interface {
-	Find() []models.Product
+	Find(schemas.ProductQuery) ([]models.Product, int64, error)
+	Search(schemas.ProductSearchQuery) ([]schemas.ProductSearchResult, int64, error)
 	FindByID(uint) models.Product
-	Create(*models.Product) *models.Product
-	Update(*models.Product) *models.Product
+	Create(*models.Product) error
+	Update(*models.Product) error
 	Delete(*models.Product)
 }
@@ -14,6 +14,7 @@ func Init(app *fiber.App, db *gorm.DB) {
 	productController := initializeFramework(db)
 	app.Route("/products", func(router fiber.Router) {
 		router.Get("/", productController.GetProducts)
+		router.Get("/search", productController.SearchProducts)
 		router.Post("/", productController.CreateProduct)
 		router.Put("/:id<min(1)>", productController.UpdateProduct)
 		router.Get("/:id<min(1)>", productController.GetProduct)
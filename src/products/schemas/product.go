@@ -1,9 +1,68 @@
 package schemas
 
+import (
+	"github.com/svadikari/golang_fiber_orders/src/money"
+	"github.com/svadikari/golang_fiber_orders/src/products/models"
+)
+
 type Product struct {
-	Name        string  `json:"name" validate:"required,min=2,max=200"`
-	Description string  `json:"description" validate:"required,min=5,max=1000"`
-	Price       float64 `json:"price" validate:"required,gt=0"`
-	Stock       int     `json:"stock"`
-	ImageURL    string  `json:"image_url"`
+	Name        string      `json:"name" validate:"required,min=2,max=200"`
+	Description string      `json:"description" validate:"required,min=5,max=1000"`
+	Price       money.Money `json:"price" validate:"required,gt=0"`
+	Stock       int         `json:"stock"`
+	ImageURL    string      `json:"image_url"`
+	Category    string      `json:"category" validate:"max=100"`
+	ProductPrices
+}
+
+// ProductPrices holds the currency and tax category of Price and the prices of the product in other
+// currencies. Currency defaults to DEFAULT_CURRENCY and TaxCategory to "standard".
+type ProductPrices struct {
+	Currency    string         `json:"currency" validate:"omitempty,iso4217" message:"currency must be an ISO 4217 code"`
+	Prices      []ProductPrice `json:"prices" validate:"omitempty,unique=Currency,dive" message:"prices must have at most one entry per currency"`
+	TaxCategory string         `json:"tax_category" validate:"omitempty,max=50" message:"tax_category must be at most 50 characters"`
+}
+
+type ProductPrice struct {
+	Currency string      `json:"currency" validate:"required,iso4217" message:"prices currency is required and must be an ISO 4217 code"`
+	Amount   money.Money `json:"amount" validate:"required,gt=0" message:"prices amount is required and must be greater than 0"`
+}
+
+// ProductQuery holds the search, filters, sorting and paging of GET /products.
+type ProductQuery struct {
+	Search   string       `query:"q" validate:"max=200" message:"q must be at most 200 characters"`
+	MinPrice *money.Money `query:"min_price" validate:"omitempty,min=0" message:"min_price must be min 0"`
+	MaxPrice *money.Money `query:"max_price" validate:"omitempty,min=0" message:"max_price must be min 0"`
+	InStock  *bool        `query:"in_stock"`
+	Category string       `query:"category" validate:"max=100" message:"category must be at most 100 characters"`
+	Sort     string       `query:"sort" validate:"omitempty,oneof=name -name price -price stock -stock created_at -created_at" message:"sort must be oneof name/price/stock/created_at with an optional - prefix for descending order"`
+	Limit    int          `query:"limit" validate:"omitempty,min=1,max=100" message:"limit must be between 1 and 100"`
+	Offset   int          `query:"offset" validate:"omitempty,min=0" message:"offset must be min 0"`
+}
+
+// DefaultProductSort lists products alphabetically.
+const DefaultProductSort = "name"
+
+// ProductSearchQuery holds the text and paging of GET /products/search.
+type ProductSearchQuery struct {
+	Q      string `query:"q" validate:"required,min=2,max=200" message:"q is required and must be between 2 and 200 characters"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100" message:"limit must be between 1 and 100"`
+	Offset int    `query:"offset" validate:"omitempty,min=0" message:"offset must be min 0"`
+}
+
+// Search match kinds: full-text matches are ranked by relevance, similar matches by trigram similarity
+// and only returned when nothing matches the full-text query.
+const (
+	MatchFullText = "fulltext"
+	MatchSimilar  = "similar"
+)
+
+// ProductSearchResult is a product matching a search. NameHighlight and Snippet wrap the matched
+// words in <mark> tags.
+type ProductSearchResult struct {
+	models.Product
+	Rank          float64 `json:"rank"`
+	Match         string  `json:"match"`
+	NameHighlight string  `json:"name_highlight"`
+	Snippet       string  `json:"snippet"`
 }
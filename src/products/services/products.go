@@ -5,15 +5,23 @@ import (
 	"fmt"
 	"log/slog"
 	"strconv"
+	"strings"
 
+	"github.com/svadikari/golang_fiber_orders/src/currency"
+	"github.com/svadikari/golang_fiber_orders/src/pagination"
 	"github.com/svadikari/golang_fiber_orders/src/products/models"
 	"github.com/svadikari/golang_fiber_orders/src/products/repository"
 	"github.com/svadikari/golang_fiber_orders/src/products/schemas"
+	"github.com/svadikari/golang_fiber_orders/src/tax"
 	"gorm.io/gorm"
 )
 
+// ErrDuplicateCurrency rejects a price in the currency the product's base price is already in.
+var ErrDuplicateCurrency = errors.New("prices cannot repeat the product currency")
+
 type ProductService interface {
-	GetAllProducts() ([]models.Product, error)
+	GetProducts(schemas.ProductQuery) (pagination.Page[models.Product], error)
+	SearchProducts(schemas.ProductSearchQuery) (pagination.Page[schemas.ProductSearchResult], error)
 	GetProductByID(uint) (models.Product, error)
 	CreateProduct(schemas.Product) (models.Product, error)
 	UpdateProduct(uint, schemas.Product) (models.Product, error)
@@ -36,19 +44,59 @@ func (s *productService) CreateProduct(productPaylod schemas.Product) (models.Pr
 		Name:        productPaylod.Name,
 		Description: productPaylod.Description,
 		Price:       productPaylod.Price,
+		Currency:    productPaylod.Currency,
+		Prices:      toProductPrices(productPaylod.Prices),
+		TaxCategory: strings.ToLower(productPaylod.TaxCategory),
+		Category:    productPaylod.Category,
 		Stock:       productPaylod.Stock,
 		ImageURL:    productPaylod.ImageURL,
 	}
-	s.productRepository.Create(&product)
+	if product.Currency == "" {
+		product.Currency = currency.Default()
+	}
+	if product.TaxCategory == "" {
+		product.TaxCategory = tax.DefaultCategory
+	}
+	if err := checkPrices(product); err != nil {
+		return models.Product{}, err
+	}
+	if err := s.productRepository.Create(&product); err != nil {
+		s.Logger.Error("Failed to create product in the database", "name", product.Name, "error", err)
+		return models.Product{}, err
+	}
 	s.Logger.Info("Created new product in the database", "product", product)
 	return product, nil
 }
 
-func (s *productService) GetAllProducts() ([]models.Product, error) {
-	s.Logger.Info("Fetching all products from the database")
-	products := s.productRepository.Find()
-	s.Logger.Info("Fetched products from the database", "products", products)
-	return products, nil
+func (s *productService) GetProducts(query schemas.ProductQuery) (pagination.Page[models.Product], error) {
+	query.Limit = pagination.Limit(query.Limit)
+	if query.Sort == "" {
+		query.Sort = schemas.DefaultProductSort
+	}
+	page := pagination.Page[models.Product]{Items: []models.Product{}, Limit: query.Limit, Offset: query.Offset}
+
+	products, total, err := s.productRepository.Find(query)
+	if err != nil {
+		s.Logger.Error("Failed to fetch products from the database", "error", err)
+		return page, err
+	}
+	s.Logger.Debug("Fetched products from the database", "count", len(products), "total", total)
+	page.Items, page.Total = products, total
+	return page, nil
+}
+
+func (s *productService) SearchProducts(query schemas.ProductSearchQuery) (pagination.Page[schemas.ProductSearchResult], error) {
+	query.Limit = pagination.Limit(query.Limit)
+	page := pagination.Page[schemas.ProductSearchResult]{Items: []schemas.ProductSearchResult{}, Limit: query.Limit, Offset: query.Offset}
+
+	results, total, err := s.productRepository.Search(query)
+	if err != nil {
+		s.Logger.Error("Failed to search products", "q", query.Q, "error", err)
+		return page, err
+	}
+	s.Logger.Debug("Searched products", "q", query.Q, "count", len(results), "total", total)
+	page.Items, page.Total = results, total
+	return page, nil
 }
 
 func (s *productService) GetProductByID(id uint) (models.Product, error) {
@@ -76,15 +124,33 @@ func (s *productService) UpdateProduct(id uint, productPaylod schemas.Product) (
 	if productPaylod.Price != 0 {
 		product.Price = productPaylod.Price
 	}
+	if productPaylod.Currency != "" {
+		product.Currency = productPaylod.Currency
+	}
+	if productPaylod.Prices != nil {
+		product.Prices = toProductPrices(productPaylod.Prices)
+	}
+	if productPaylod.Category != "" {
+		product.Category = productPaylod.Category
+	}
+	if productPaylod.TaxCategory != "" {
+		product.TaxCategory = strings.ToLower(productPaylod.TaxCategory)
+	}
 	if productPaylod.Stock != 0 {
 		product.Stock = productPaylod.Stock
 	}
 	if productPaylod.ImageURL != "" {
 		product.ImageURL = productPaylod.ImageURL
 	}
+	if err := checkPrices(product); err != nil {
+		return models.Product{}, err
+	}
 	product.ID = id
-	s.productRepository.Update(&product)
-	s.Logger.Info("Created new product in the database", "product", product)
+	if err := s.productRepository.Update(&product); err != nil {
+		s.Logger.Error("Failed to update product in the database", "id", id, "error", err)
+		return models.Product{}, err
+	}
+	s.Logger.Info("Updated product in the database", "product", product)
 	return product, nil
 }
 
@@ -97,3 +163,20 @@ func (s *productService) DeleteProduct(id uint) error {
 	s.productRepository.Delete(&product)
 	return nil
 }
+
+func toProductPrices(prices []schemas.ProductPrice) []models.ProductPrice {
+	productPrices := make([]models.ProductPrice, 0, len(prices))
+	for _, price := range prices {
+		productPrices = append(productPrices, models.ProductPrice{Currency: price.Currency, Amount: price.Amount})
+	}
+	return productPrices
+}
+
+func checkPrices(product models.Product) error {
+	for _, price := range product.Prices {
+		if price.Currency == product.Currency {
+			return ErrDuplicateCurrency
+		}
+	}
+	return nil
+}
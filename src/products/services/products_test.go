@@ -1,35 +1,44 @@
 package services
 
 import (
+	"errors"
 	"log/slog"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/svadikari/golang_fiber_orders/src/currency"
+	"github.com/svadikari/golang_fiber_orders/src/money"
+	"github.com/svadikari/golang_fiber_orders/src/pagination"
 	"github.com/svadikari/golang_fiber_orders/src/products/models"
+	"github.com/svadikari/golang_fiber_orders/src/products/schemas"
+	"github.com/svadikari/golang_fiber_orders/src/tax"
 )
 
 type mockProductRepository struct {
 	mock.Mock
 }
 
-func (r *mockProductRepository) Create(product *models.Product) *models.Product {
-	args := r.Called(product)
-	return args.Get(0).(*models.Product)
+func (m *mockProductRepository) Create(product *models.Product) error {
+	return m.Called(product).Error(0)
 }
 
-func (m *mockProductRepository) Update(product *models.Product) *models.Product {
-	args := m.Called(product)
-	return args.Get(0).(*models.Product)
+func (m *mockProductRepository) Update(product *models.Product) error {
+	return m.Called(product).Error(0)
 }
 
 func (m *mockProductRepository) Delete(product *models.Product) {
 	m.Called(product)
 }
 
-func (m *mockProductRepository) Find() []models.Product {
-	args := m.Called()
-	return args.Get(0).([]models.Product)
+func (m *mockProductRepository) Find(query schemas.ProductQuery) ([]models.Product, int64, error) {
+	args := m.Called(query)
+	return args.Get(0).([]models.Product), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *mockProductRepository) Search(query schemas.ProductSearchQuery) ([]schemas.ProductSearchResult, int64, error) {
+	args := m.Called(query)
+	return args.Get(0).([]schemas.ProductSearchResult), args.Get(1).(int64), args.Error(2)
 }
 
 func (m *mockProductRepository) FindByID(id uint) models.Product {
@@ -53,7 +62,7 @@ func TestGetProductById(t *testing.T) {
 	})
 
 	t.Run("Product Found for given productId", func(t *testing.T) {
-		product := models.Product{Name: "Test Product", Price: 10.0, Stock: 100, Description: "Test Description", ImageURL: "http://example.com/image.jpg"}
+		product := models.Product{Name: "Test Product", Price: money.MustParse("10"), Stock: 100, Description: "Test Description", ImageURL: "http://example.com/image.jpg"}
 		product.ID = 1
 		mockRepo.On("FindByID", uint(1)).Return(product).Once()
 		result, err := service.GetProductByID(1)
@@ -64,3 +73,121 @@ func TestGetProductById(t *testing.T) {
 	})
 
 }
+
+func TestGetProducts(t *testing.T) {
+
+	mockRepo := new(mockProductRepository)
+	service := NewProductService(mockRepo, slog.Default())
+
+	t.Run("Default sort and page size are applied", func(t *testing.T) {
+		products := []models.Product{{Name: "Test Product", Price: money.MustParse("10"), Stock: 100}}
+		expected := schemas.ProductQuery{Search: "test", Sort: schemas.DefaultProductSort, Limit: pagination.DefaultLimit}
+		mockRepo.On("Find", expected).Return(products, int64(41), nil).Once()
+		page, err := service.GetProducts(schemas.ProductQuery{Search: "test"})
+		assert.NoError(t, err)
+		assert.Equal(t, products, page.Items)
+		assert.Equal(t, int64(41), page.Total)
+		assert.Equal(t, pagination.DefaultLimit, page.Limit)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Requested page is passed to the repository", func(t *testing.T) {
+		query := schemas.ProductQuery{Sort: "-price", Limit: 5, Offset: 10}
+		mockRepo.On("Find", query).Return([]models.Product{}, int64(12), nil).Once()
+		page, err := service.GetProducts(query)
+		assert.NoError(t, err)
+		assert.Empty(t, page.Items)
+		assert.Equal(t, 10, page.Offset)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Repository errors are returned", func(t *testing.T) {
+		mockRepo.On("Find", mock.Anything).Return([]models.Product{}, int64(0), errors.New("connection refused")).Once()
+		_, err := service.GetProducts(schemas.ProductQuery{})
+		assert.Error(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestSearchProducts(t *testing.T) {
+
+	mockRepo := new(mockProductRepository)
+	service := NewProductService(mockRepo, slog.Default())
+
+	t.Run("Search results are paged", func(t *testing.T) {
+		results := []schemas.ProductSearchResult{{
+			Product:       models.Product{Name: "Running Shoe"},
+			Rank:          0.6,
+			Match:         schemas.MatchFullText,
+			NameHighlight: "Running <mark>Shoe</mark>",
+		}}
+		mockRepo.On("Search", schemas.ProductSearchQuery{Q: "shoe", Limit: pagination.DefaultLimit}).Return(results, int64(1), nil).Once()
+		page, err := service.SearchProducts(schemas.ProductSearchQuery{Q: "shoe"})
+		assert.NoError(t, err)
+		assert.Equal(t, results, page.Items)
+		assert.Equal(t, int64(1), page.Total)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Repository errors are returned", func(t *testing.T) {
+		mockRepo.On("Search", mock.Anything).Return([]schemas.ProductSearchResult{}, int64(0), errors.New("connection refused")).Once()
+		_, err := service.SearchProducts(schemas.ProductSearchQuery{Q: "shoe"})
+		assert.Error(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestCreateProduct(t *testing.T) {
+
+	t.Run("Created products get the default currency and tax category", func(t *testing.T) {
+		mockRepo := new(mockProductRepository)
+		service := NewProductService(mockRepo, slog.Default())
+		mockRepo.On("Create", mock.Anything).Return(nil).Once()
+
+		created, err := service.CreateProduct(schemas.Product{Name: "Mug", Price: money.MustParse("10")})
+		assert.NoError(t, err)
+		assert.Equal(t, currency.Default(), created.Currency)
+		assert.Equal(t, tax.DefaultCategory, created.TaxCategory)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Repository errors are returned", func(t *testing.T) {
+		mockRepo := new(mockProductRepository)
+		service := NewProductService(mockRepo, slog.Default())
+		failed := errors.New("connection refused")
+		mockRepo.On("Create", mock.Anything).Return(failed).Once()
+
+		_, err := service.CreateProduct(schemas.Product{Name: "Mug", Price: money.MustParse("10")})
+		assert.ErrorIs(t, err, failed)
+	})
+}
+
+func TestUpdateProduct(t *testing.T) {
+
+	t.Run("Updated products are saved", func(t *testing.T) {
+		mockRepo := new(mockProductRepository)
+		service := NewProductService(mockRepo, slog.Default())
+		product := models.Product{Name: "Mug", Price: money.MustParse("10"), Currency: "USD"}
+		product.ID = 1
+		mockRepo.On("FindByID", uint(1)).Return(product).Once()
+		mockRepo.On("Update", mock.Anything).Return(nil).Once()
+
+		updated, err := service.UpdateProduct(1, schemas.Product{Name: "Large Mug"})
+		assert.NoError(t, err)
+		assert.Equal(t, "Large Mug", updated.Name)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Repository errors are returned", func(t *testing.T) {
+		mockRepo := new(mockProductRepository)
+		service := NewProductService(mockRepo, slog.Default())
+		product := models.Product{Name: "Mug", Price: money.MustParse("10"), Currency: "USD"}
+		product.ID = 1
+		failed := errors.New("connection refused")
+		mockRepo.On("FindByID", uint(1)).Return(product).Once()
+		mockRepo.On("Update", mock.Anything).Return(failed).Once()
+
+		_, err := service.UpdateProduct(1, schemas.Product{Name: "Large Mug"})
+		assert.ErrorIs(t, err, failed)
+	})
+}
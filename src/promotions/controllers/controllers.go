@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/svadikari/golang_fiber_orders/src/middleware"
+	"github.com/svadikari/golang_fiber_orders/src/pagination"
+	"github.com/svadikari/golang_fiber_orders/src/promotions/models"
+	"github.com/svadikari/golang_fiber_orders/src/promotions/schemas"
+	"github.com/svadikari/golang_fiber_orders/src/promotions/services"
+)
+
+type PromotionController interface {
+	GetPromotions(c *fiber.Ctx) error
+	GetPromotion(c *fiber.Ctx) error
+	CreatePromotion(c *fiber.Ctx) error
+	UpdatePromotion(c *fiber.Ctx) error
+	DeletePromotion(c *fiber.Ctx) error
+}
+
+type promotionController struct {
+	promotionService services.PromotionService
+}
+
+func NewPromotionController(promotionService services.PromotionService) PromotionController {
+	return &promotionController{promotionService: promotionService}
+}
+
+// Get promotions
+//
+//	@Summary		Get promotions
+//	@Description	Retrieve a page of promotions, newest first
+//	@Tags			Promotions
+//	@Produce		json
+//	@Param			code	query		string	false	"Only the promotion of this coupon code"
+//	@Param			active	query		bool	false	"Only active (true) or inactive (false) promotions"
+//	@Param			limit	query		int		false	"Page size"	minimum(1)	maximum(100)	default(20)
+//	@Param			offset	query		int		false	"Number of promotions to skip"
+//	@Success		200		{object}	pagination.Page[models.Promotion]
+//	@Failure		400		{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500		{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/promotions [get]
+func (pc *promotionController) GetPromotions(c *fiber.Ctx) error {
+	var query schemas.PromotionQuery
+	if err := c.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid query parameters")
+	}
+	if validationErrs := middleware.NewStructValidator().Validate(query); len(validationErrs) > 0 {
+		return fiber.NewError(fiber.StatusBadRequest, strings.Join(validationErrs, ","))
+	}
+
+	var page pagination.Page[models.Promotion]
+	page, err := pc.promotionService.GetPromotions(c.UserContext(), query)
+	if err != nil {
+		c.Locals("logger").(*slog.Logger).Error("Failed to fetch promotions", "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to fetch promotions")
+	}
+	return c.Status(fiber.StatusOK).JSON(page)
+}
+
+// Get promotion
+//
+//	@Summary		Get promotion
+//	@Description	Get a promotion by ID
+//	@Tags			Promotions
+//	@Produce		json
+//	@Param			id	path		int	true	"Promotion ID"
+//	@Success		200	{object}	models.Promotion
+//	@Failure		400	{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		404	{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500	{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/promotions/{id} [get]
+func (pc *promotionController) GetPromotion(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid promotion ID")
+	}
+	promotion, err := pc.promotionService.GetPromotion(c.UserContext(), uint(id))
+	if err != nil {
+		return promotionError(c, err, "Failed to fetch promotion")
+	}
+	return c.Status(fiber.StatusOK).JSON(promotion)
+}
+
+// Create promotion
+//
+//	@Summary		Create promotion
+//	@Description	Create a promotion redeemed with a coupon code: a percentage or fixed amount off the targeted order lines, or free shipping
+//	@Tags			Promotions
+//	@Accept			json
+//	@Produce		json
+//	@Param			promotion	body		schemas.PromotionSchema	true	"Promotion payload"
+//	@Success		201			{object}	models.Promotion
+//	@Failure		400			{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		409			{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500			{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/promotions [post]
+func (pc *promotionController) CreatePromotion(c *fiber.Ctx) error {
+	payload, err := parsePromotion(c)
+	if err != nil {
+		return err
+	}
+	promotion, err := pc.promotionService.CreatePromotion(c.UserContext(), payload)
+	if err != nil {
+		return promotionError(c, err, "Failed to create promotion")
+	}
+	return c.Status(fiber.StatusCreated).JSON(promotion)
+}
+
+// Update promotion
+//
+//	@Summary		Update promotion
+//	@Description	Replace a promotion by ID. Orders that already redeemed it keep their discounts
+//	@Tags			Promotions
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int						true	"Promotion ID"
+//	@Param			promotion	body		schemas.PromotionSchema	true	"Promotion payload"
+//	@Success		200			{object}	models.Promotion
+//	@Failure		400			{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		404			{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		409			{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500			{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/promotions/{id} [put]
+func (pc *promotionController) UpdatePromotion(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid promotion ID")
+	}
+	payload, err := parsePromotion(c)
+	if err != nil {
+		return err
+	}
+	promotion, err := pc.promotionService.UpdatePromotion(c.UserContext(), uint(id), payload)
+	if err != nil {
+		return promotionError(c, err, "Failed to update promotion")
+	}
+	return c.Status(fiber.StatusOK).JSON(promotion)
+}
+
+// Delete promotion
+//
+//	@Summary		Delete promotion
+//	@Description	Delete a promotion by ID. Its coupon code can no longer be redeemed
+//	@Tags			Promotions
+//	@Produce		json
+//	@Param			id	path	int	true	"Promotion ID"
+//	@Success		204
+//	@Failure		400	{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		404	{object}	middleware.GlobalErrorHandlerResp
+//	@Failure		500	{object}	middleware.GlobalErrorHandlerResp
+//	@Router			/promotions/{id} [delete]
+func (pc *promotionController) DeletePromotion(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "Invalid promotion ID")
+	}
+	if err := pc.promotionService.DeletePromotion(c.UserContext(), uint(id)); err != nil {
+		return promotionError(c, err, "Failed to delete promotion")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func parsePromotion(c *fiber.Ctx) (schemas.PromotionSchema, error) {
+	var payload schemas.PromotionSchema
+	if err := c.BodyParser(&payload); err != nil {
+		return payload, fiber.NewError(fiber.StatusBadRequest, "Cannot parse JSON")
+	}
+	if validationErrs := middleware.NewStructValidator().Validate(payload); len(validationErrs) > 0 {
+		return payload, fiber.NewError(fiber.StatusBadRequest, strings.Join(validationErrs, ","))
+	}
+	return payload, nil
+}
+
+// promotionError maps service errors to HTTP errors, logging unexpected ones.
+func promotionError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, services.ErrPromotionNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Promotion not found")
+	case errors.Is(err, services.ErrInvalidWindow):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrDuplicateCode):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	default:
+		c.Locals("logger").(*slog.Logger).Error(fallback, "error", err)
+		return fiber.NewError(fiber.StatusInternalServerError, fallback)
+	}
+}
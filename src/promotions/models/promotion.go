@@ -0,0 +1,80 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/money"
+	"gorm.io/gorm"
+)
+
+// Promotion types.
+const (
+	TypePercentage   = "percentage"
+	TypeFixedAmount  = "fixed_amount"
+	TypeFreeShipping = "free_shipping"
+)
+
+// Promotion is a discount redeemed with a coupon code. It applies to every order line, or only to the
+// lines of its Products and Categories when it targets any.
+type Promotion struct {
+	gorm.Model
+	// Code is the coupon code, stored in upper case.
+	Code        string `json:"code" gorm:"column:code;size:50;not null;uniqueIndex:uq_promotions_code,where:deleted_at IS NULL"`
+	Description string `json:"description" gorm:"column:description;size:500;not null;default:''"`
+	Type        string `json:"type" gorm:"column:type;size:20;not null"`
+	// Percentage is the discount of percentage promotions, such as 0.15.
+	Percentage money.Rate `json:"percentage" gorm:"column:percentage;type:numeric(12,6);not null;default:0"`
+	// Amount is the discount of fixed amount promotions, in Currency.
+	Amount money.Money `json:"amount" gorm:"column:amount;type:numeric(12,2);not null;default:0"`
+	// MinOrderTotal is the smallest sum of the order lines, in Currency, the promotion applies to.
+	MinOrderTotal money.Money `json:"min_order_total" gorm:"column:min_order_total;type:numeric(12,2);not null;default:0"`
+	// Currency is the ISO 4217 currency of Amount and MinOrderTotal. Orders in other currencies can only
+	// redeem the promotion when neither is set.
+	Currency string `json:"currency" gorm:"column:currency;type:char(3);not null;default:'USD'"`
+	// UsageLimitPerUser caps the orders of one user that may redeem the promotion; 0 means no limit.
+	UsageLimitPerUser int        `json:"usage_limit_per_user" gorm:"column:usage_limit_per_user;not null;default:0"`
+	StartsAt          *time.Time `json:"starts_at" gorm:"column:starts_at"`
+	EndsAt            *time.Time `json:"ends_at" gorm:"column:ends_at"`
+	Active            bool       `json:"active" gorm:"column:active;not null"`
+	// Products and Categories restrict the promotion to the order lines of these products or categories.
+	Products   []PromotionProduct  `json:"products" gorm:"foreignKey:PromotionID;constraint:OnDelete:CASCADE"`
+	Categories []PromotionCategory `json:"categories" gorm:"foreignKey:PromotionID;constraint:OnDelete:CASCADE"`
+}
+
+type PromotionProduct struct {
+	PromotionID uint `json:"-" gorm:"column:promotion_id;primaryKey"`
+	ProductID   uint `json:"product_id" gorm:"column:product_id;primaryKey"`
+}
+
+type PromotionCategory struct {
+	PromotionID uint   `json:"-" gorm:"column:promotion_id;primaryKey"`
+	Category    string `json:"category" gorm:"column:category;primaryKey;size:100"`
+}
+
+// PromotionRedemption records an order that redeemed a promotion.
+type PromotionRedemption struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement;column:id"`
+	PromotionID uint      `json:"promotion_id" gorm:"column:promotion_id;not null;index:idx_promotion_redemptions_user,priority:1"`
+	UserID      uint      `json:"user_id" gorm:"column:user_id;not null;index:idx_promotion_redemptions_user,priority:2"`
+	OrderID     uint      `json:"order_id" gorm:"column:order_id;not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at;not null"`
+}
+
+// Targets tells whether the promotion applies to an order line of productID in category.
+func (p Promotion) Targets(productID uint, category string) bool {
+	if len(p.Products) == 0 && len(p.Categories) == 0 {
+		return true
+	}
+	for _, product := range p.Products {
+		if product.ProductID == productID {
+			return true
+		}
+	}
+	for _, c := range p.Categories {
+		if category != "" && strings.EqualFold(c.Category, category) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/svadikari/golang_fiber_orders/src/promotions/models"
+	"github.com/svadikari/golang_fiber_orders/src/promotions/schemas"
+	"gorm.io/gorm"
+)
+
+var ErrPromotionNotFound = errors.New("promotion not found")
+
+type PromotionRepository interface {
+	Find(ctx context.Context, query schemas.PromotionQuery) ([]models.Promotion, int64, error)
+	FindByID(ctx context.Context, id uint) (models.Promotion, error)
+	// FindByCode returns the promotion of a coupon code, ErrPromotionNotFound when there is none.
+	FindByCode(ctx context.Context, code string) (models.Promotion, error)
+	Create(ctx context.Context, promotion *models.Promotion) error
+	// Update saves the promotion and replaces its products and categories.
+	Update(ctx context.Context, promotion *models.Promotion) error
+	Delete(ctx context.Context, promotion *models.Promotion) error
+}
+
+type promotionRepository struct {
+	Db *gorm.DB
+}
+
+func NewPromotionRepository(db *gorm.DB) PromotionRepository {
+	return &promotionRepository{Db: db}
+}
+
+// Find returns the page of promotions matching query, newest first, and the number of promotions matching its filters.
+func (r *promotionRepository) Find(ctx context.Context, query schemas.PromotionQuery) ([]models.Promotion, int64, error) {
+	promotions := []models.Promotion{}
+	var total int64
+
+	filters := func(tx *gorm.DB) *gorm.DB {
+		if query.Code != "" {
+			tx = tx.Where("code = UPPER(?)", query.Code)
+		}
+		if query.Active != nil {
+			tx = tx.Where("active = ?", *query.Active)
+		}
+		return tx
+	}
+	db := r.Db.WithContext(ctx)
+	if err := db.Model(&models.Promotion{}).Scopes(filters).Count(&total).Error; err != nil {
+		return promotions, 0, err
+	}
+	err := db.Scopes(filters).Preload("Products").Preload("Categories").
+		Order("created_at DESC, id DESC").
+		Limit(query.Limit).Offset(query.Offset).
+		Find(&promotions).Error
+	return promotions, total, err
+}
+
+func (r *promotionRepository) FindByID(ctx context.Context, id uint) (models.Promotion, error) {
+	var promotion models.Promotion
+	err := r.Db.WithContext(ctx).Preload("Products").Preload("Categories").First(&promotion, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return promotion, ErrPromotionNotFound
+	}
+	return promotion, err
+}
+
+func (r *promotionRepository) FindByCode(ctx context.Context, code string) (models.Promotion, error) {
+	var promotion models.Promotion
+	err := r.Db.WithContext(ctx).Where("code = ?", code).First(&promotion).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return promotion, ErrPromotionNotFound
+	}
+	return promotion, err
+}
+
+func (r *promotionRepository) Create(ctx context.Context, promotion *models.Promotion) error {
+	return r.Db.WithContext(ctx).Create(promotion).Error
+}
+
+func (r *promotionRepository) Update(ctx context.Context, promotion *models.Promotion) error {
+	return r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Products", "Categories").Save(promotion).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("promotion_id = ?", promotion.ID).Delete(&models.PromotionProduct{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("promotion_id = ?", promotion.ID).Delete(&models.PromotionCategory{}).Error; err != nil {
+			return err
+		}
+		for i := range promotion.Products {
+			promotion.Products[i].PromotionID = promotion.ID
+		}
+		for i := range promotion.Categories {
+			promotion.Categories[i].PromotionID = promotion.ID
+		}
+		if len(promotion.Products) > 0 {
+			if err := tx.Create(&promotion.Products).Error; err != nil {
+				return err
+			}
+		}
+		if len(promotion.Categories) > 0 {
+			return tx.Create(&promotion.Categories).Error
+		}
+		return nil
+	})
+}
+
+func (r *promotionRepository) Delete(ctx context.Context, promotion *models.Promotion) error {
+	return r.Db.WithContext(ctx).Delete(promotion).Error
+}
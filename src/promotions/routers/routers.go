@@ -0,0 +1,23 @@
+package routers
+
+import (
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/svadikari/golang_fiber_orders/src/promotions/controllers"
+	"github.com/svadikari/golang_fiber_orders/src/promotions/repository"
+	"github.com/svadikari/golang_fiber_orders/src/promotions/services"
+	"gorm.io/gorm"
+)
+
+func Init(app *fiber.App, db *gorm.DB) {
+	promotionService := services.NewPromotionService(repository.NewPromotionRepository(db), slog.Default())
+	promotionController := controllers.NewPromotionController(promotionService)
+	app.Route("/promotions", func(router fiber.Router) {
+		router.Get("/", promotionController.GetPromotions)
+		router.Post("/", promotionController.CreatePromotion)
+		router.Get("/:id<min(1)>", promotionController.GetPromotion)
+		router.Put("/:id<min(1)>", promotionController.UpdatePromotion)
+		router.Delete("/:id<min(1)>", promotionController.DeletePromotion)
+	})
+}
@@ -0,0 +1,40 @@
+package schemas
+
+import (
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/money"
+)
+
+// PromotionSchema creates or replaces a promotion. Currency defaults to DEFAULT_CURRENCY and Active to true.
+type PromotionSchema struct {
+	Code              string                    `json:"code" validate:"required,max=50,alphanum" message:"code is required and must be at most 50 letters or digits"`
+	Description       string                    `json:"description" validate:"max=500" message:"description must be at most 500 characters"`
+	Type              string                    `json:"type" validate:"required,oneof=percentage fixed_amount free_shipping" message:"type is required and must be oneof percentage/fixed_amount/free_shipping"`
+	Percentage        money.Rate                `json:"percentage" validate:"required_if=Type percentage,min=0,max=1000000" message:"percentage is required for percentage promotions and must be between 0 and 1"`
+	Amount            money.Money               `json:"amount" validate:"required_if=Type fixed_amount,min=0" message:"amount is required for fixed_amount promotions and must be min 0"`
+	MinOrderTotal     money.Money               `json:"min_order_total" validate:"min=0" message:"min_order_total must be min 0"`
+	Currency          string                    `json:"currency" validate:"omitempty,iso4217" message:"currency must be an ISO 4217 code"`
+	UsageLimitPerUser int                       `json:"usage_limit_per_user" validate:"min=0" message:"usage_limit_per_user must be min 0"`
+	StartsAt          *time.Time                `json:"starts_at"`
+	EndsAt            *time.Time                `json:"ends_at"`
+	Active            *bool                     `json:"active"`
+	Products          []PromotionProductSchema  `json:"products" validate:"omitempty,unique=ProductID,dive" message:"products must not repeat a product"`
+	Categories        []PromotionCategorySchema `json:"categories" validate:"omitempty,unique=Category,dive" message:"categories must not repeat a category"`
+}
+
+type PromotionProductSchema struct {
+	ProductID uint `json:"product_id" validate:"required,min=1" message:"products product_id is required and must be min 1"`
+}
+
+type PromotionCategorySchema struct {
+	Category string `json:"category" validate:"required,max=100" message:"categories category is required and must be at most 100 characters"`
+}
+
+// PromotionQuery holds the filters and paging of GET /promotions.
+type PromotionQuery struct {
+	Code   string `query:"code" validate:"max=50" message:"code must be at most 50 characters"`
+	Active *bool  `query:"active"`
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100" message:"limit must be between 1 and 100"`
+	Offset int    `query:"offset" validate:"omitempty,min=0" message:"offset must be min 0"`
+}
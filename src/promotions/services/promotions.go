@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/svadikari/golang_fiber_orders/src/currency"
+	"github.com/svadikari/golang_fiber_orders/src/pagination"
+	"github.com/svadikari/golang_fiber_orders/src/promotions/models"
+	"github.com/svadikari/golang_fiber_orders/src/promotions/repository"
+	"github.com/svadikari/golang_fiber_orders/src/promotions/schemas"
+)
+
+var (
+	ErrPromotionNotFound = repository.ErrPromotionNotFound
+	// ErrDuplicateCode rejects a coupon code another promotion already uses.
+	ErrDuplicateCode = errors.New("coupon code is already in use")
+	// ErrInvalidWindow rejects a validity window that ends before it starts.
+	ErrInvalidWindow = errors.New("ends_at must be after starts_at")
+)
+
+type PromotionService interface {
+	GetPromotions(ctx context.Context, query schemas.PromotionQuery) (pagination.Page[models.Promotion], error)
+	GetPromotion(ctx context.Context, id uint) (models.Promotion, error)
+	CreatePromotion(ctx context.Context, payload schemas.PromotionSchema) (models.Promotion, error)
+	UpdatePromotion(ctx context.Context, id uint, payload schemas.PromotionSchema) (models.Promotion, error)
+	DeletePromotion(ctx context.Context, id uint) error
+}
+
+type promotionService struct {
+	Logger              *slog.Logger
+	promotionRepository repository.PromotionRepository
+}
+
+func NewPromotionService(promotionRepository repository.PromotionRepository, logger *slog.Logger) PromotionService {
+	logger = logger.With("service", "PromotionService")
+	return &promotionService{Logger: logger, promotionRepository: promotionRepository}
+}
+
+func (s *promotionService) GetPromotions(ctx context.Context, query schemas.PromotionQuery) (pagination.Page[models.Promotion], error) {
+	query.Limit = pagination.Limit(query.Limit)
+	page := pagination.Page[models.Promotion]{Items: []models.Promotion{}, Limit: query.Limit, Offset: query.Offset}
+
+	promotions, total, err := s.promotionRepository.Find(ctx, query)
+	if err != nil {
+		return page, err
+	}
+	page.Items, page.Total = promotions, total
+	return page, nil
+}
+
+func (s *promotionService) GetPromotion(ctx context.Context, id uint) (models.Promotion, error) {
+	return s.promotionRepository.FindByID(ctx, id)
+}
+
+func (s *promotionService) CreatePromotion(ctx context.Context, payload schemas.PromotionSchema) (models.Promotion, error) {
+	promotion := toPromotion(payload)
+	if err := s.checkPromotion(ctx, promotion); err != nil {
+		return models.Promotion{}, err
+	}
+	if err := s.promotionRepository.Create(ctx, &promotion); err != nil {
+		return models.Promotion{}, err
+	}
+	s.Logger.Info("Created promotion", "id", promotion.ID, "code", promotion.Code)
+	return promotion, nil
+}
+
+// UpdatePromotion replaces the promotion with payload.
+func (s *promotionService) UpdatePromotion(ctx context.Context, id uint, payload schemas.PromotionSchema) (models.Promotion, error) {
+	existing, err := s.promotionRepository.FindByID(ctx, id)
+	if err != nil {
+		return models.Promotion{}, err
+	}
+	promotion := toPromotion(payload)
+	promotion.Model = existing.Model
+	if err := s.checkPromotion(ctx, promotion); err != nil {
+		return models.Promotion{}, err
+	}
+	if err := s.promotionRepository.Update(ctx, &promotion); err != nil {
+		return models.Promotion{}, err
+	}
+	s.Logger.Info("Updated promotion", "id", promotion.ID, "code", promotion.Code)
+	return promotion, nil
+}
+
+func (s *promotionService) DeletePromotion(ctx context.Context, id uint) error {
+	promotion, err := s.promotionRepository.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.promotionRepository.Delete(ctx, &promotion)
+}
+
+// checkPromotion rejects a validity window that ends before it starts and a code another promotion uses.
+func (s *promotionService) checkPromotion(ctx context.Context, promotion models.Promotion) error {
+	if promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.EndsAt.After(*promotion.StartsAt) {
+		return ErrInvalidWindow
+	}
+	other, err := s.promotionRepository.FindByCode(ctx, promotion.Code)
+	if errors.Is(err, repository.ErrPromotionNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if other.ID != promotion.ID {
+		return ErrDuplicateCode
+	}
+	return nil
+}
+
+func toPromotion(payload schemas.PromotionSchema) models.Promotion {
+	promotion := models.Promotion{
+		Code:              strings.ToUpper(payload.Code),
+		Description:       payload.Description,
+		Type:              payload.Type,
+		MinOrderTotal:     payload.MinOrderTotal,
+		Currency:          payload.Currency,
+		UsageLimitPerUser: payload.UsageLimitPerUser,
+		StartsAt:          payload.StartsAt,
+		EndsAt:            payload.EndsAt,
+		Active:            payload.Active == nil || *payload.Active,
+		Products:          make([]models.PromotionProduct, 0, len(payload.Products)),
+		Categories:        make([]models.PromotionCategory, 0, len(payload.Categories)),
+	}
+	// Only the discount of the promotion's own type is kept.
+	switch payload.Type {
+	case models.TypePercentage:
+		promotion.Percentage = payload.Percentage
+	case models.TypeFixedAmount:
+		promotion.Amount = payload.Amount
+	}
+	if promotion.Currency == "" {
+		promotion.Currency = currency.Default()
+	}
+	for _, product := range payload.Products {
+		promotion.Products = append(promotion.Products, models.PromotionProduct{ProductID: product.ProductID})
+	}
+	for _, category := range payload.Categories {
+		promotion.Categories = append(promotion.Categories, models.PromotionCategory{Category: category.Category})
+	}
+	return promotion
+}
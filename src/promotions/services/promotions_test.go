@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/svadikari/golang_fiber_orders/src/money"
+	"github.com/svadikari/golang_fiber_orders/src/promotions/models"
+	"github.com/svadikari/golang_fiber_orders/src/promotions/repository"
+	"github.com/svadikari/golang_fiber_orders/src/promotions/schemas"
+	"gorm.io/gorm"
+	gormSchema "gorm.io/gorm/schema"
+)
+
+type mockPromotionRepository struct {
+	mock.Mock
+}
+
+func (m *mockPromotionRepository) Find(ctx context.Context, query schemas.PromotionQuery) ([]models.Promotion, int64, error) {
+	args := m.Called(query)
+	return args.Get(0).([]models.Promotion), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *mockPromotionRepository) FindByID(ctx context.Context, id uint) (models.Promotion, error) {
+	args := m.Called(id)
+	return args.Get(0).(models.Promotion), args.Error(1)
+}
+
+func (m *mockPromotionRepository) FindByCode(ctx context.Context, code string) (models.Promotion, error) {
+	args := m.Called(code)
+	return args.Get(0).(models.Promotion), args.Error(1)
+}
+
+func (m *mockPromotionRepository) Create(ctx context.Context, promotion *models.Promotion) error {
+	return m.Called(promotion).Error(0)
+}
+
+func (m *mockPromotionRepository) Update(ctx context.Context, promotion *models.Promotion) error {
+	return m.Called(promotion).Error(0)
+}
+
+func (m *mockPromotionRepository) Delete(ctx context.Context, promotion *models.Promotion) error {
+	return m.Called(promotion).Error(0)
+}
+
+func TestCreatePromotion(t *testing.T) {
+
+	payload := schemas.PromotionSchema{
+		Code:       "summer15",
+		Type:       models.TypePercentage,
+		Percentage: money.MustParseRate("0.15"),
+		Amount:     money.MustParse("5"),
+		Products:   []schemas.PromotionProductSchema{{ProductID: 3}},
+	}
+
+	t.Run("Promotions are created with normalized codes and defaults", func(t *testing.T) {
+		mockRepo := new(mockPromotionRepository)
+		service := NewPromotionService(mockRepo, slog.Default())
+		mockRepo.On("FindByCode", "SUMMER15").Return(models.Promotion{}, repository.ErrPromotionNotFound).Once()
+		mockRepo.On("Create", mock.Anything).Return(nil).Once()
+
+		promotion, err := service.CreatePromotion(context.Background(), payload)
+		assert.NoError(t, err)
+		assert.Equal(t, "SUMMER15", promotion.Code)
+		assert.Equal(t, "USD", promotion.Currency)
+		assert.True(t, promotion.Active)
+		assert.Zero(t, promotion.Amount, "only the discount of the promotion type is kept")
+		assert.Equal(t, []models.PromotionProduct{{ProductID: 3}}, promotion.Products)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Promotions created inactive are stored inactive", func(t *testing.T) {
+		mockRepo := new(mockPromotionRepository)
+		service := NewPromotionService(mockRepo, slog.Default())
+		mockRepo.On("FindByCode", "SUMMER15").Return(models.Promotion{}, repository.ErrPromotionNotFound).Once()
+		mockRepo.On("Create", mock.MatchedBy(func(promotion *models.Promotion) bool { return !promotion.Active })).Return(nil).Once()
+
+		active := false
+		inactive := payload
+		inactive.Active = &active
+		promotion, err := service.CreatePromotion(context.Background(), inactive)
+		assert.NoError(t, err)
+		assert.False(t, promotion.Active)
+		mockRepo.AssertExpectations(t)
+
+		// gorm leaves zero values out of inserts for columns with a default, which would store false as true.
+		promotionSchema, err := gormSchema.Parse(&models.Promotion{}, &sync.Map{}, gormSchema.NamingStrategy{})
+		assert.NoError(t, err)
+		assert.False(t, promotionSchema.LookUpField("Active").HasDefaultValue)
+	})
+
+	t.Run("Codes in use are rejected", func(t *testing.T) {
+		mockRepo := new(mockPromotionRepository)
+		service := NewPromotionService(mockRepo, slog.Default())
+		mockRepo.On("FindByCode", "SUMMER15").Return(models.Promotion{Model: gorm.Model{ID: 2}}, nil).Once()
+
+		_, err := service.CreatePromotion(context.Background(), payload)
+		assert.ErrorIs(t, err, ErrDuplicateCode)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Validity windows must end after they start", func(t *testing.T) {
+		service := NewPromotionService(new(mockPromotionRepository), slog.Default())
+		startsAt := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+		endsAt := startsAt.Add(-time.Hour)
+		invalid := payload
+		invalid.StartsAt, invalid.EndsAt = &startsAt, &endsAt
+
+		_, err := service.CreatePromotion(context.Background(), invalid)
+		assert.ErrorIs(t, err, ErrInvalidWindow)
+	})
+}
+
+func TestUpdatePromotion(t *testing.T) {
+
+	t.Run("Promotions keep their code when replaced", func(t *testing.T) {
+		mockRepo := new(mockPromotionRepository)
+		service := NewPromotionService(mockRepo, slog.Default())
+		existing := models.Promotion{Model: gorm.Model{ID: 2}, Code: "SUMMER15"}
+		mockRepo.On("FindByID", uint(2)).Return(existing, nil).Once()
+		mockRepo.On("FindByCode", "SUMMER15").Return(existing, nil).Once()
+		mockRepo.On("Update", mock.Anything).Return(nil).Once()
+
+		active := false
+		promotion, err := service.UpdatePromotion(context.Background(), 2, schemas.PromotionSchema{
+			Code: "SUMMER15", Type: models.TypeFreeShipping, Active: &active,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), promotion.ID)
+		assert.False(t, promotion.Active)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown promotions are not found", func(t *testing.T) {
+		mockRepo := new(mockPromotionRepository)
+		service := NewPromotionService(mockRepo, slog.Default())
+		mockRepo.On("FindByID", uint(9)).Return(models.Promotion{}, repository.ErrPromotionNotFound).Once()
+
+		_, err := service.UpdatePromotion(context.Background(), 9, schemas.PromotionSchema{Code: "X", Type: models.TypeFreeShipping})
+		assert.ErrorIs(t, err, ErrPromotionNotFound)
+	})
+}
@@ -0,0 +1,165 @@
+package schemaregistry
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	SchemaTypeAvro     = "AVRO"
+	SchemaTypeProtobuf = "PROTOBUF"
+)
+
+// errorSubjectNotFound is the Confluent error code returned for unknown subjects.
+const errorSubjectNotFound = 40401
+
+type Schema struct {
+	ID         int    `json:"id"`
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType"`
+}
+
+type registerRequest struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType"`
+}
+
+type registerResponse struct {
+	ID int `json:"id"`
+}
+
+type compatibilityResponse struct {
+	IsCompatible bool `json:"is_compatible"`
+}
+
+type errorResponse struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+type Registry interface {
+	// Register checks schema against the latest version of subject and registers it,
+	// returning its ID. Registering an already known schema returns the existing ID.
+	Register(subject, schema, schemaType string) (int, error)
+	// Schema fetches a schema by ID.
+	Schema(id int) (Schema, error)
+}
+
+// client talks to a Confluent-compatible schema registry and caches IDs and schemas,
+// which are immutable once registered.
+type client struct {
+	restyClient *resty.Client
+	mu          sync.RWMutex
+	ids         map[string]int
+	schemas     map[int]Schema
+}
+
+func NewClient(baseURL string) Registry {
+	restyClient := resty.New()
+	restyClient.SetBaseURL(baseURL).
+		SetHeader("Content-Type", "application/vnd.schemaregistry.v1+json").
+		SetHeader("Accept", "application/vnd.schemaregistry.v1+json").
+		SetTimeout(5 * time.Second).
+		SetRetryCount(3).
+		SetRetryWaitTime(1 * time.Second)
+
+	slog.Info("Initialized Schema Registry Client", "baseURL", restyClient.BaseURL)
+	return &client{restyClient: restyClient, ids: map[string]int{}, schemas: map[int]Schema{}}
+}
+
+func (c *client) Register(subject, schema, schemaType string) (int, error) {
+	cacheKey := subject + "\x00" + schema
+	c.mu.RLock()
+	id, ok := c.ids[cacheKey]
+	c.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+
+	compatible, err := c.isCompatible(subject, schema, schemaType)
+	if err != nil {
+		return 0, err
+	}
+	if !compatible {
+		return 0, fmt.Errorf("schema is not compatible with the latest version of subject %s", subject)
+	}
+
+	var registered registerResponse
+	var failure errorResponse
+	resp, err := c.restyClient.R().
+		SetBody(registerRequest{Schema: schema, SchemaType: schemaType}).
+		SetResult(&registered).
+		SetError(&failure).
+		Post("/subjects/" + subject + "/versions")
+	if err != nil {
+		return 0, err
+	}
+	if resp.IsError() {
+		return 0, fmt.Errorf("failed to register schema for subject %s: %d %s", subject, resp.StatusCode(), failure.Message)
+	}
+
+	c.mu.Lock()
+	c.ids[cacheKey] = registered.ID
+	c.schemas[registered.ID] = Schema{ID: registered.ID, Schema: schema, SchemaType: schemaType}
+	c.mu.Unlock()
+	slog.Info("Registered schema", "subject", subject, "id", registered.ID, "schemaType", schemaType)
+	return registered.ID, nil
+}
+
+// isCompatible tests schema against the latest version of subject. A subject without versions accepts any schema.
+func (c *client) isCompatible(subject, schema, schemaType string) (bool, error) {
+	var result compatibilityResponse
+	var failure errorResponse
+	resp, err := c.restyClient.R().
+		SetBody(registerRequest{Schema: schema, SchemaType: schemaType}).
+		SetResult(&result).
+		SetError(&failure).
+		Post("/compatibility/subjects/" + subject + "/versions/latest")
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode() == http.StatusNotFound && failure.ErrorCode == errorSubjectNotFound {
+		return true, nil
+	}
+	if resp.IsError() {
+		return false, fmt.Errorf("failed to check schema compatibility for subject %s: %d %s", subject, resp.StatusCode(), failure.Message)
+	}
+	return result.IsCompatible, nil
+}
+
+func (c *client) Schema(id int) (Schema, error) {
+	c.mu.RLock()
+	schema, ok := c.schemas[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	var failure errorResponse
+	resp, err := c.restyClient.R().
+		SetResult(&schema).
+		SetError(&failure).
+		Get("/schemas/ids/" + strconv.Itoa(id))
+	if err != nil {
+		return Schema{}, err
+	}
+	if resp.IsError() {
+		return Schema{}, fmt.Errorf("failed to fetch schema %d: %d %s", id, resp.StatusCode(), failure.Message)
+	}
+	schema.ID = id
+	if schema.SchemaType == "" {
+		// The registry omits schemaType for Avro, its default.
+		schema.SchemaType = SchemaTypeAvro
+	}
+
+	c.mu.Lock()
+	c.schemas[id] = schema
+	c.mu.Unlock()
+	return schema, nil
+}
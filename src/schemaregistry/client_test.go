@@ -0,0 +1,43 @@
+package schemaregistry
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient(t *testing.T) {
+
+	server := httptest.NewServer(NewStubHandler())
+	defer server.Close()
+	registry := NewClient(server.URL)
+
+	t.Run("Schemas are registered once per subject", func(t *testing.T) {
+		id, err := registry.Register("orders-value", `{"type":"string"}`, SchemaTypeAvro)
+		assert.NoError(t, err)
+		again, err := NewClient(server.URL).Register("orders-value", `{"type":"string"}`, SchemaTypeAvro)
+		assert.NoError(t, err)
+		assert.Equal(t, id, again)
+	})
+
+	t.Run("Registered schemas are fetched by ID", func(t *testing.T) {
+		id, err := registry.Register("payments-value", `syntax = "proto3";`, SchemaTypeProtobuf)
+		assert.NoError(t, err)
+
+		schema, err := NewClient(server.URL).Schema(id)
+		assert.NoError(t, err)
+		assert.Equal(t, `syntax = "proto3";`, schema.Schema)
+		assert.Equal(t, SchemaTypeProtobuf, schema.SchemaType)
+	})
+
+	t.Run("Incompatible schemas are rejected", func(t *testing.T) {
+		_, err := registry.Register("orders-value", `syntax = "proto3";`, SchemaTypeProtobuf)
+		assert.ErrorContains(t, err, "not compatible")
+	})
+
+	t.Run("Unknown schema IDs are reported", func(t *testing.T) {
+		_, err := registry.Schema(99)
+		assert.Error(t, err)
+	})
+}
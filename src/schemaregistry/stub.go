@@ -0,0 +1,93 @@
+package schemaregistry
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// stubRegistry is an in-memory subset of the Confluent schema registry API. It stores
+// every distinct schema per subject and treats any new version as compatible unless the
+// schema type changes, which is enough to exercise clients locally and in tests.
+type stubRegistry struct {
+	mu       sync.Mutex
+	schemas  []Schema
+	subjects map[string][]int
+}
+
+// NewStubHandler returns an http.Handler serving the registry endpoints used by Client.
+func NewStubHandler() http.Handler {
+	stub := &stubRegistry{subjects: map[string][]int{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /subjects/{subject}/versions", stub.register)
+	mux.HandleFunc("POST /compatibility/subjects/{subject}/versions/latest", stub.compatibility)
+	mux.HandleFunc("GET /schemas/ids/{id}", stub.schema)
+	return mux
+}
+
+func (s *stubRegistry) register(w http.ResponseWriter, r *http.Request) {
+	var req registerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{ErrorCode: 42201, Message: err.Error()})
+		return
+	}
+	subject := r.PathValue("subject")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range s.subjects[subject] {
+		if s.schemas[id-1].Schema == req.Schema {
+			writeJSON(w, http.StatusOK, registerResponse{ID: id})
+			return
+		}
+	}
+	id := len(s.schemas) + 1
+	s.schemas = append(s.schemas, Schema{ID: id, Schema: req.Schema, SchemaType: schemaTypeOrDefault(req.SchemaType)})
+	s.subjects[subject] = append(s.subjects[subject], id)
+	writeJSON(w, http.StatusOK, registerResponse{ID: id})
+}
+
+func (s *stubRegistry) compatibility(w http.ResponseWriter, r *http.Request) {
+	var req registerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{ErrorCode: 42201, Message: err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	versions := s.subjects[r.PathValue("subject")]
+	if len(versions) == 0 {
+		writeJSON(w, http.StatusNotFound, errorResponse{ErrorCode: errorSubjectNotFound, Message: "Subject not found"})
+		return
+	}
+	latest := s.schemas[versions[len(versions)-1]-1]
+	writeJSON(w, http.StatusOK, compatibilityResponse{IsCompatible: latest.SchemaType == schemaTypeOrDefault(req.SchemaType)})
+}
+
+func (s *stubRegistry) schema(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil || id < 1 || id > len(s.schemas) {
+		writeJSON(w, http.StatusNotFound, errorResponse{ErrorCode: 40403, Message: "Schema not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, s.schemas[id-1])
+}
+
+func schemaTypeOrDefault(schemaType string) string {
+	if schemaType == "" {
+		return SchemaTypeAvro
+	}
+	return strings.ToUpper(schemaType)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,186 @@
+package serialization
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"github.com/svadikari/golang_fiber_orders/src/schemaregistry"
+)
+
+const (
+	FormatJSON     = "json"
+	FormatProtobuf = "protobuf"
+	FormatAvro     = "avro"
+)
+
+const (
+	ContentTypeAvro     = "application/avro"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// ProtoMessage is implemented by event data that can be encoded as Protobuf.
+type ProtoMessage interface {
+	ProtoSchema() string
+	MarshalProto() ([]byte, error)
+	UnmarshalProto([]byte) error
+}
+
+// AvroRecord is implemented by event data that can be encoded as Avro.
+type AvroRecord interface {
+	AvroSchema() string
+	MarshalAvro(schema avro.Schema) ([]byte, error)
+	// UnmarshalAvro decodes data written with the writer schema it was registered with.
+	UnmarshalAvro(writerSchema avro.Schema, data []byte) error
+}
+
+// EventSerializer encodes CloudEvents in the configured format and decodes every supported format,
+// so consumers keep working while producers switch formats. JSON events use structured mode;
+// Avro and Protobuf events use binary mode with the data framed in schema registry wire format.
+type EventSerializer struct {
+	format   string
+	registry schemaregistry.Registry
+	mu       sync.Mutex
+	parsed   map[int]avro.Schema
+}
+
+func NewEventSerializer(format string, registry schemaregistry.Registry) (*EventSerializer, error) {
+	switch format {
+	case FormatJSON:
+	case FormatProtobuf, FormatAvro:
+		if registry == nil {
+			return nil, fmt.Errorf("%s serialization requires a schema registry", format)
+		}
+	default:
+		return nil, fmt.Errorf("unknown serialization format %q", format)
+	}
+	return &EventSerializer{format: format, registry: registry, parsed: map[int]avro.Schema{}}, nil
+}
+
+// Subject follows the topic name strategy of the schema registry.
+func Subject(topic string) string {
+	return topic + "-value"
+}
+
+// Serialize encodes event with data and returns the message value and headers.
+func (s *EventSerializer) Serialize(topic string, event events.CloudEvent, data any) ([]byte, map[string]string, error) {
+	switch s.format {
+	case FormatProtobuf:
+		message, ok := data.(ProtoMessage)
+		if !ok {
+			return nil, nil, fmt.Errorf("%T cannot be serialized as protobuf", data)
+		}
+		schemaId, err := s.registry.Register(Subject(topic), message.ProtoSchema(), schemaregistry.SchemaTypeProtobuf)
+		if err != nil {
+			return nil, nil, err
+		}
+		payload, err := message.MarshalProto()
+		if err != nil {
+			return nil, nil, err
+		}
+		event.DataContentType = ContentTypeProtobuf
+		return frame(schemaId, payload, true), event.BinaryHeaders(), nil
+	case FormatAvro:
+		record, ok := data.(AvroRecord)
+		if !ok {
+			return nil, nil, fmt.Errorf("%T cannot be serialized as avro", data)
+		}
+		schemaId, err := s.registry.Register(Subject(topic), record.AvroSchema(), schemaregistry.SchemaTypeAvro)
+		if err != nil {
+			return nil, nil, err
+		}
+		schema, err := s.avroSchema(schemaId)
+		if err != nil {
+			return nil, nil, err
+		}
+		payload, err := record.MarshalAvro(schema)
+		if err != nil {
+			return nil, nil, err
+		}
+		event.DataContentType = ContentTypeAvro
+		return frame(schemaId, payload, false), event.BinaryHeaders(), nil
+	default:
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		event.DataContentType = events.DataContentTypeJSON
+		event.Data = payload
+		value, err := json.Marshal(event)
+		if err != nil {
+			return nil, nil, err
+		}
+		return value, map[string]string{
+			events.HeaderContentType: events.CloudEventsContentType,
+			events.HeaderEventID:     event.ID,
+			events.EventTypeHeader:   event.Type,
+		}, nil
+	}
+}
+
+// Deserialize reads the envelope of msg, leaving the data encoded in event.Data.
+func (s *EventSerializer) Deserialize(msg events.Message) (events.CloudEvent, error) {
+	contentType := msg.Headers[events.HeaderContentType]
+	if contentType == "" || contentType == events.CloudEventsContentType {
+		return events.ParseCloudEvent(msg.Value)
+	}
+	return events.ParseBinaryCloudEvent(msg.Headers, msg.Value)
+}
+
+// DecodeData decodes the data of event into v according to its content type.
+func (s *EventSerializer) DecodeData(event events.CloudEvent, v any) error {
+	switch event.DataContentType {
+	case ContentTypeProtobuf:
+		message, ok := v.(ProtoMessage)
+		if !ok {
+			return fmt.Errorf("%T cannot be deserialized from protobuf", v)
+		}
+		_, payload, err := unframe(event.Data, true)
+		if err != nil {
+			return err
+		}
+		return message.UnmarshalProto(payload)
+	case ContentTypeAvro:
+		record, ok := v.(AvroRecord)
+		if !ok {
+			return fmt.Errorf("%T cannot be deserialized from avro", v)
+		}
+		if s.registry == nil {
+			return fmt.Errorf("avro data requires a schema registry")
+		}
+		schemaId, payload, err := unframe(event.Data, false)
+		if err != nil {
+			return err
+		}
+		writerSchema, err := s.avroSchema(schemaId)
+		if err != nil {
+			return err
+		}
+		return record.UnmarshalAvro(writerSchema, payload)
+	case events.DataContentTypeJSON, "":
+		return json.Unmarshal(event.Data, v)
+	default:
+		return fmt.Errorf("unsupported data content type %q", event.DataContentType)
+	}
+}
+
+// avroSchema parses the registered schema with the given ID once.
+func (s *EventSerializer) avroSchema(schemaId int) (avro.Schema, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if schema, ok := s.parsed[schemaId]; ok {
+		return schema, nil
+	}
+	registered, err := s.registry.Schema(schemaId)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := avro.Parse(registered.Schema)
+	if err != nil {
+		return nil, err
+	}
+	s.parsed[schemaId] = schema
+	return schema, nil
+}
@@ -0,0 +1,92 @@
+package serialization
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svadikari/golang_fiber_orders/src/events"
+	"github.com/svadikari/golang_fiber_orders/src/money"
+	"github.com/svadikari/golang_fiber_orders/src/orders/schemas"
+	"github.com/svadikari/golang_fiber_orders/src/schemaregistry"
+)
+
+func TestEventSerializer(t *testing.T) {
+
+	server := httptest.NewServer(schemaregistry.NewStubHandler())
+	defer server.Close()
+	registry := schemaregistry.NewClient(server.URL)
+
+	createdAt := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	orderEvent := schemas.OrderEvent{
+		OrderID:     7,
+		UserID:      3,
+		Status:      "NEW",
+		TotalAmount: money.MustParse("37.63"),
+		Currency:    "USD",
+		Items: []schemas.OrderEventItem{
+			{ProductID: 11, Quantity: 2, UnitPrice: money.MustParse("16.25"), DiscountAmount: money.MustParse("2")},
+		},
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		Subtotal:  money.MustParse("30.50"),
+		TaxAmount: money.MustParse("2.13"),
+		TaxMode:   "exclusive",
+		TaxLines: []schemas.OrderEventTaxLine{{Name: "CA sales tax", Region: "US-CA", Category: "standard",
+			Rate: money.MustParseRate("0.0725"), TaxableAmount: money.MustParse("30.50"), TaxAmount: money.MustParse("2.13")}},
+		DiscountAmount: money.MustParse("2"),
+		CouponCode:     "SAVE2",
+		ShippingMethod: "standard",
+		ShippingCost:   money.MustParse("5"),
+		ShippingAddress: &schemas.OrderEventAddress{Name: "Ada Lovelace", Line1: "1 Market St", City: "San Francisco",
+			Region: "CA", PostalCode: "94105", Country: "US"},
+		BillingAddress: &schemas.OrderEventAddress{Name: "Ada Lovelace", Line1: "2 Mission St", Line2: "Suite 4",
+			City: "San Francisco", PostalCode: "94105", Country: "US"},
+		TrackingNumber: "1Z999AA10123456784",
+		Carrier:        "UPS",
+	}
+	ctx := events.WithCorrelationID(context.Background(), "req-1")
+
+	for _, format := range []string{FormatJSON, FormatProtobuf, FormatAvro} {
+		t.Run("Order events round trip as "+format, func(t *testing.T) {
+			// Each format gets its own topic, as a subject only accepts compatible schemas.
+			topic := "orders-" + format
+			serializer, err := NewEventSerializer(format, registry)
+			assert.NoError(t, err)
+
+			event := events.NewCloudEvent(ctx, schemas.EventOrderCreated, "/test", "orders/7", schemas.OrderEventSchemaVersion)
+			value, headers, err := serializer.Serialize(topic, event, &orderEvent)
+			assert.NoError(t, err)
+			assert.Equal(t, event.ID, headers[events.HeaderEventID])
+			assert.Equal(t, schemas.EventOrderCreated, headers[events.EventTypeHeader])
+
+			// Consumers decode with their own serializer, whatever format they publish in.
+			consumer, err := NewEventSerializer(FormatJSON, registry)
+			assert.NoError(t, err)
+			parsed, err := consumer.Deserialize(events.Message{Topic: topic, Value: value, Headers: headers})
+			assert.NoError(t, err)
+			assert.Equal(t, event.ID, parsed.ID)
+			assert.Equal(t, "req-1", parsed.CorrelationID)
+
+			var decoded schemas.OrderEvent
+			assert.NoError(t, consumer.DecodeData(parsed, &decoded))
+			assert.Equal(t, orderEvent, decoded)
+		})
+	}
+
+	t.Run("Binary formats require a schema registry", func(t *testing.T) {
+		_, err := NewEventSerializer(FormatAvro, nil)
+		assert.Error(t, err)
+		_, err = NewEventSerializer("xml", registry)
+		assert.Error(t, err)
+	})
+
+	t.Run("Data without wire format framing is rejected", func(t *testing.T) {
+		serializer, _ := NewEventSerializer(FormatJSON, registry)
+		event := events.CloudEvent{DataContentType: ContentTypeProtobuf, Data: []byte{1, 2}}
+		var decoded schemas.OrderEvent
+		assert.Error(t, serializer.DecodeData(event, &decoded))
+	})
+}
@@ -0,0 +1,52 @@
+package serialization
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Confluent wire format: a zero magic byte and the big-endian schema ID precede the payload.
+// Protobuf payloads additionally carry the index path of the message within the schema,
+// where the single byte 0 stands for the first message.
+const (
+	magicByte  = 0
+	headerSize = 5
+)
+
+var errInvalidFrame = errors.New("payload is not in schema registry wire format")
+
+func frame(schemaId int, payload []byte, protobuf bool) []byte {
+	framed := make([]byte, headerSize, headerSize+1+len(payload))
+	framed[0] = magicByte
+	binary.BigEndian.PutUint32(framed[1:headerSize], uint32(schemaId))
+	if protobuf {
+		framed = append(framed, 0)
+	}
+	return append(framed, payload...)
+}
+
+func unframe(data []byte, protobuf bool) (int, []byte, error) {
+	if len(data) < headerSize || data[0] != magicByte {
+		return 0, nil, errInvalidFrame
+	}
+	schemaId := int(binary.BigEndian.Uint32(data[1:headerSize]))
+	payload := data[headerSize:]
+	if !protobuf {
+		return schemaId, payload, nil
+	}
+
+	count, n := protowire.ConsumeVarint(payload)
+	if n < 0 {
+		return 0, nil, errInvalidFrame
+	}
+	payload = payload[n:]
+	for i := int64(0); i < protowire.DecodeZigZag(count); i++ {
+		if _, n = protowire.ConsumeVarint(payload); n < 0 {
+			return 0, nil, errInvalidFrame
+		}
+		payload = payload[n:]
+	}
+	return schemaId, payload, nil
+}
@@ -0,0 +1,78 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/money"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrMethodNotFound = errors.New("shipping method not found")
+
+// Method is a way of shipping orders, such as "standard" or "express", at a flat Cost in Currency.
+// Orders in other currencies pay the cost converted with the stored exchange rates.
+type Method struct {
+	Code     string      `json:"code" gorm:"column:code;primaryKey;size:50"`
+	Name     string      `json:"name" gorm:"column:name;size:100;not null"`
+	Cost     money.Money `json:"cost" gorm:"column:cost;type:numeric(12,2);not null;check:cost >= 0"`
+	Currency string      `json:"currency" gorm:"column:currency;type:char(3);not null"`
+	// Active methods can be chosen for new orders.
+	Active    bool      `json:"active" gorm:"column:active;not null"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at;not null"`
+}
+
+func (Method) TableName() string {
+	return "shipping_methods"
+}
+
+// Catalog looks up shipping methods by code.
+type Catalog interface {
+	Find(ctx context.Context, code string) (Method, error)
+}
+
+// Methods stores the shipping methods.
+type Methods struct {
+	db *gorm.DB
+}
+
+func NewMethods(db *gorm.DB) *Methods {
+	return &Methods{db: db}
+}
+
+// Find returns the method of a code, ErrMethodNotFound when there is none.
+func (m *Methods) Find(ctx context.Context, code string) (Method, error) {
+	var method Method
+	err := m.db.WithContext(ctx).First(&method, "code = ?", code).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return method, ErrMethodNotFound
+	}
+	return method, err
+}
+
+// List returns every shipping method.
+func (m *Methods) List(ctx context.Context) ([]Method, error) {
+	var methods []Method
+	err := m.db.WithContext(ctx).Order("code").Find(&methods).Error
+	return methods, err
+}
+
+// Save creates or replaces the method of its code.
+func (m *Methods) Save(ctx context.Context, method *Method) error {
+	method.UpdatedAt = time.Now().UTC()
+	return m.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(method).Error
+}
+
+// Delete removes the method of a code. Orders shipped with it keep its code and cost.
+func (m *Methods) Delete(ctx context.Context, code string) error {
+	result := m.db.WithContext(ctx).Delete(&Method{}, "code = ?", code)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrMethodNotFound
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package tax
+
+import (
+	"strings"
+
+	"github.com/svadikari/golang_fiber_orders/src/money"
+)
+
+// Line is an order line to tax: its total in the order currency and the tax category of its product.
+type Line struct {
+	Category string
+	Amount   money.Money
+}
+
+// Breakdown is the tax of an order. Subtotal is the amount before tax and Total is Subtotal plus TaxAmount.
+// In inclusive mode Total is the sum of the line totals.
+type Breakdown struct {
+	Region    string
+	Mode      Mode
+	Subtotal  money.Money
+	TaxAmount money.Money
+	Total     money.Money
+	// LineTaxes holds the tax of every line, in the order of the lines.
+	LineTaxes []money.Money
+	TaxLines  []TaxLine
+}
+
+// TaxLine totals one rate across the lines it applies to.
+type TaxLine struct {
+	Name          string      `json:"name"`
+	Region        string      `json:"region"`
+	Category      string      `json:"category"`
+	Rate          money.Rate  `json:"rate"`
+	TaxableAmount money.Money `json:"taxable_amount"`
+	TaxAmount     money.Money `json:"tax_amount"`
+}
+
+// Calculate taxes lines with rates, which are the rates of region and of its country. Every line pays the
+// rate of its category in each of them, or the standard rate where its category has none. Tax is worked
+// out and rounded half away from zero per line and rate; in inclusive mode the tax taken out of a line
+// is split across its rates by largest remainder, so net amount plus tax is the line total exactly.
+func Calculate(rates []TaxRate, region string, mode Mode, lines []Line) Breakdown {
+	breakdown := Breakdown{Region: region, Mode: mode, LineTaxes: make([]money.Money, len(lines))}
+	index := map[TaxRate]int{}
+	for i, line := range lines {
+		applicable := applicableRates(rates, region, line.Category)
+		net, taxes := taxLine(mode, line.Amount, applicable)
+		breakdown.Subtotal += net
+		for j, rate := range applicable {
+			breakdown.LineTaxes[i] += taxes[j]
+			key := TaxRate{Region: rate.Region, Category: rate.Category, Name: rate.Name, Rate: rate.Rate}
+			k, ok := index[key]
+			if !ok {
+				k = len(breakdown.TaxLines)
+				index[key] = k
+				breakdown.TaxLines = append(breakdown.TaxLines, TaxLine{
+					Name:     rate.Name,
+					Region:   rate.Region,
+					Category: rate.Category,
+					Rate:     rate.Rate,
+				})
+			}
+			breakdown.TaxLines[k].TaxableAmount += net
+			breakdown.TaxLines[k].TaxAmount += taxes[j]
+		}
+		breakdown.TaxAmount += breakdown.LineTaxes[i]
+	}
+	breakdown.Total = breakdown.Subtotal + breakdown.TaxAmount
+	return breakdown
+}
+
+// taxLine returns the net amount of a line and its tax at each rate.
+func taxLine(mode Mode, amount money.Money, rates []TaxRate) (money.Money, []money.Money) {
+	taxes := make([]money.Money, len(rates))
+	if mode != ModeInclusive {
+		for i, rate := range rates {
+			taxes[i] = amount.MulRate(rate.Rate)
+		}
+		return amount, taxes
+	}
+
+	one := money.MustParseRate("1")
+	combined := one
+	for _, rate := range rates {
+		combined += rate.Rate
+	}
+	net := amount.Convert(combined, one)
+	for i, rate := range rates {
+		taxes[i] = net.MulRate(rate.Rate)
+	}
+	if len(rates) > 0 {
+		taxes = (amount - net).Allocate(taxes)
+	}
+	return net, taxes
+}
+
+// applicableRates picks the rate of category, or else the standard rate, in each jurisdiction of region.
+func applicableRates(rates []TaxRate, region, category string) []TaxRate {
+	if category == "" {
+		category = DefaultCategory
+	}
+	var applicable []TaxRate
+	for _, jurisdiction := range jurisdictions(region) {
+		var standard, own *TaxRate
+		for i := range rates {
+			if rates[i].Region != jurisdiction {
+				continue
+			}
+			switch rates[i].Category {
+			case category:
+				own = &rates[i]
+			case DefaultCategory:
+				standard = &rates[i]
+			}
+		}
+		if own == nil {
+			own = standard
+		}
+		if own != nil {
+			applicable = append(applicable, *own)
+		}
+	}
+	return applicable
+}
+
+// jurisdictions returns the country of region followed by region itself when it is a subdivision.
+func jurisdictions(region string) []string {
+	if country, _, ok := strings.Cut(region, "-"); ok {
+		return []string{country, region}
+	}
+	return []string{region}
+}
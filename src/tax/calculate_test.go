@@ -0,0 +1,70 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/svadikari/golang_fiber_orders/src/money"
+)
+
+func TestCalculate(t *testing.T) {
+	rates := []TaxRate{
+		{Region: "DE", Category: "standard", Name: "VAT", Rate: money.MustParseRate("0.19")},
+		{Region: "DE", Category: "books", Name: "VAT reduced", Rate: money.MustParseRate("0.07")},
+		{Region: "CA", Category: "standard", Name: "GST", Rate: money.MustParseRate("0.05")},
+		{Region: "CA-BC", Category: "standard", Name: "PST", Rate: money.MustParseRate("0.07")},
+		{Region: "CA-BC", Category: "food", Name: "PST food", Rate: money.MustParseRate("0")},
+	}
+
+	t.Run("adds tax on top in exclusive mode", func(t *testing.T) {
+		breakdown := Calculate(rates, "DE", ModeExclusive, []Line{
+			{Category: "standard", Amount: money.MustParse("10.00")},
+			{Category: "books", Amount: money.MustParse("20.00")},
+			{Category: "toys", Amount: money.MustParse("5.00")},
+		})
+		assert.Equal(t, money.MustParse("35.00"), breakdown.Subtotal)
+		assert.Equal(t, money.MustParse("4.25"), breakdown.TaxAmount)
+		assert.Equal(t, money.MustParse("39.25"), breakdown.Total)
+		assert.Equal(t, []money.Money{money.MustParse("1.90"), money.MustParse("1.40"), money.MustParse("0.95")}, breakdown.LineTaxes)
+		assert.Equal(t, []TaxLine{
+			{Name: "VAT", Region: "DE", Category: "standard", Rate: money.MustParseRate("0.19"),
+				TaxableAmount: money.MustParse("15.00"), TaxAmount: money.MustParse("2.85")},
+			{Name: "VAT reduced", Region: "DE", Category: "books", Rate: money.MustParseRate("0.07"),
+				TaxableAmount: money.MustParse("20.00"), TaxAmount: money.MustParse("1.40")},
+		}, breakdown.TaxLines)
+	})
+
+	t.Run("takes tax out in inclusive mode", func(t *testing.T) {
+		breakdown := Calculate(rates, "DE", ModeInclusive, []Line{{Category: "standard", Amount: money.MustParse("11.90")}})
+		assert.Equal(t, money.MustParse("10.00"), breakdown.Subtotal)
+		assert.Equal(t, money.MustParse("1.90"), breakdown.TaxAmount)
+		assert.Equal(t, money.MustParse("11.90"), breakdown.Total)
+	})
+
+	t.Run("stacks the rates of a subdivision on its country", func(t *testing.T) {
+		breakdown := Calculate(rates, "CA-BC", ModeExclusive, []Line{
+			{Category: "standard", Amount: money.MustParse("100.00")},
+			{Category: "food", Amount: money.MustParse("10.00")},
+		})
+		assert.Equal(t, money.MustParse("12.50"), breakdown.TaxAmount)
+		assert.Equal(t, []money.Money{money.MustParse("12.00"), money.MustParse("0.50")}, breakdown.LineTaxes)
+		assert.Len(t, breakdown.TaxLines, 3)
+		assert.Equal(t, money.MustParse("110.00"), breakdown.TaxLines[0].TaxableAmount)
+	})
+
+	t.Run("inclusive tax adds up to the line total across stacked rates", func(t *testing.T) {
+		breakdown := Calculate(rates, "CA-BC", ModeInclusive, []Line{{Category: "standard", Amount: money.MustParse("9.99")}})
+		assert.Equal(t, money.MustParse("8.92"), breakdown.Subtotal)
+		assert.Equal(t, money.MustParse("1.07"), breakdown.TaxAmount)
+		assert.Equal(t, money.MustParse("0.45"), breakdown.TaxLines[0].TaxAmount)
+		assert.Equal(t, money.MustParse("0.62"), breakdown.TaxLines[1].TaxAmount)
+		assert.Equal(t, money.MustParse("9.99"), breakdown.Total)
+	})
+
+	t.Run("leaves regions without rates untaxed", func(t *testing.T) {
+		breakdown := Calculate(nil, "", ModeExclusive, []Line{{Amount: money.MustParse("5.00")}})
+		assert.Equal(t, money.MustParse("5.00"), breakdown.Total)
+		assert.Zero(t, breakdown.TaxAmount)
+		assert.Empty(t, breakdown.TaxLines)
+	})
+}
@@ -0,0 +1,124 @@
+package tax
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/svadikari/golang_fiber_orders/src/money"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultCategory is the tax category of products that do not name one. Its rate also applies to
+// categories without a rate of their own in a region.
+const DefaultCategory = "standard"
+
+// Mode tells whether catalog prices exclude or include tax.
+type Mode string
+
+const (
+	// ModeExclusive adds tax on top of the line totals.
+	ModeExclusive Mode = "exclusive"
+	// ModeInclusive takes the tax out of the line totals, which stay what the customer pays.
+	ModeInclusive Mode = "inclusive"
+)
+
+// ModeFromEnv reads the pricing mode from TAX_PRICING_MODE, defaulting to exclusive.
+func ModeFromEnv() Mode {
+	if Mode(strings.ToLower(os.Getenv("TAX_PRICING_MODE"))) == ModeInclusive {
+		return ModeInclusive
+	}
+	return ModeExclusive
+}
+
+// DefaultRegion returns the tax region of orders that do not name one, from TAX_DEFAULT_REGION.
+// Orders without a region are not taxed.
+func DefaultRegion() string {
+	return strings.ToUpper(os.Getenv("TAX_DEFAULT_REGION"))
+}
+
+var ErrRateNotFound = errors.New("tax rate not found")
+
+// TaxRate is the rate of one tax category in one region. Region is an ISO 3166-1 country code such as
+// "DE" or an ISO 3166-2 subdivision code such as "US-CA"; the rates of a subdivision add to those of its country.
+type TaxRate struct {
+	Region    string     `json:"region" gorm:"column:region;primaryKey;size:6"`
+	Category  string     `json:"category" gorm:"column:category;primaryKey;size:50"`
+	Name      string     `json:"name" gorm:"column:name;size:100;not null"`
+	Rate      money.Rate `json:"rate" gorm:"column:rate;type:numeric(12,6);not null;check:rate >= 0"`
+	UpdatedAt time.Time  `json:"updated_at" gorm:"column:updated_at;not null"`
+}
+
+func (TaxRate) TableName() string {
+	return "tax_rates"
+}
+
+// Calculator works out the tax of order lines.
+type Calculator interface {
+	Calculate(ctx context.Context, region string, lines []Line) (Breakdown, error)
+}
+
+// Engine taxes order lines with the rates stored in the tax_rates table.
+type Engine struct {
+	rates *Rates
+	mode  Mode
+}
+
+func NewEngine(db *gorm.DB) *Engine {
+	return &Engine{rates: NewRates(db), mode: ModeFromEnv()}
+}
+
+func (e *Engine) Calculate(ctx context.Context, region string, lines []Line) (Breakdown, error) {
+	var rates []TaxRate
+	if region != "" {
+		var err error
+		if rates, err = e.rates.ForRegion(ctx, region); err != nil {
+			return Breakdown{}, err
+		}
+	}
+	return Calculate(rates, region, e.mode, lines), nil
+}
+
+// Rates stores the tax rates.
+type Rates struct {
+	db *gorm.DB
+}
+
+func NewRates(db *gorm.DB) *Rates {
+	return &Rates{db: db}
+}
+
+// ForRegion returns the rates that apply in region: its own and those of its country.
+func (r *Rates) ForRegion(ctx context.Context, region string) ([]TaxRate, error) {
+	var rates []TaxRate
+	err := r.db.WithContext(ctx).Where("region IN ?", jurisdictions(region)).Find(&rates).Error
+	return rates, err
+}
+
+// List returns every tax rate.
+func (r *Rates) List(ctx context.Context) ([]TaxRate, error) {
+	var rates []TaxRate
+	err := r.db.WithContext(ctx).Order("region, category").Find(&rates).Error
+	return rates, err
+}
+
+// Save creates or replaces the rate of its region and category.
+func (r *Rates) Save(ctx context.Context, rate *TaxRate) error {
+	rate.UpdatedAt = time.Now().UTC()
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(rate).Error
+}
+
+// Delete removes the rate of a region and category.
+func (r *Rates) Delete(ctx context.Context, region, category string) error {
+	result := r.db.WithContext(ctx).Delete(&TaxRate{}, "region = ? AND category = ?", region, category)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRateNotFound
+	}
+	return nil
+}